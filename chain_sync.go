@@ -21,11 +21,12 @@ import (
 	"fmt"
 	"io"
 	"net"
-	"os"
 	"sort"
+	"strconv"
 	"sync/atomic"
 	"time"
 
+	"github.com/buger/jsonparser"
 	"github.com/gorilla/websocket"
 	"github.com/savaki/ogmigo/ouroboros/chainsync"
 	"golang.org/x/sync/errgroup"
@@ -60,12 +61,114 @@ func (c *ChainSync) Close() error {
 // ChainSyncFunc callback containing json encoded chainsync.Response
 type ChainSyncFunc func(ctx context.Context, data []byte) error
 
+func (fn ChainSyncFunc) handle(ctx context.Context, msg *chainSyncMessage) error {
+	return fn(ctx, msg.data)
+}
+
+// ChainSyncHandler receives decoded chainsync messages.  The raw json encoded
+// chainsync.Response for the message being handled is available via ChainSyncData
+type ChainSyncHandler interface {
+	// IntersectionFound is invoked when ogmios finds one of the requested points
+	IntersectionFound(ctx context.Context, point, tip chainsync.Point) error
+	// IntersectionNotFound is invoked when none of the requested points could be found
+	IntersectionNotFound(ctx context.Context, tip chainsync.Point) error
+	// RollForward is invoked for each new block
+	RollForward(ctx context.Context, block chainsync.RollForwardBlock, tip chainsync.Point) error
+	// RollBackward is invoked when the chain rolls back to the provided point
+	RollBackward(ctx context.Context, point, tip chainsync.Point) error
+}
+
+type chainSyncDataKey struct{}
+
+// ChainSyncData returns the json encoded chainsync.Response currently being
+// handled by a ChainSyncHandler
+func ChainSyncData(ctx context.Context) ([]byte, bool) {
+	data, ok := ctx.Value(chainSyncDataKey{}).([]byte)
+	return data, ok
+}
+
+type typedHandler struct {
+	handler ChainSyncHandler
+}
+
+func (t typedHandler) handle(ctx context.Context, msg *chainSyncMessage) error {
+	response, err := msg.decode()
+	if err != nil {
+		return err
+	}
+	if response.Result == nil {
+		return nil
+	}
+
+	ctx = context.WithValue(ctx, chainSyncDataKey{}, msg.data)
+	switch result := response.Result; {
+	case result.RollForward != nil:
+		return t.handler.RollForward(ctx, result.RollForward.Block, result.RollForward.Tip)
+	case result.RollBackward != nil:
+		return t.handler.RollBackward(ctx, result.RollBackward.Point, result.RollBackward.Tip)
+	case result.IntersectionFound != nil:
+		return t.handler.IntersectionFound(ctx, result.IntersectionFound.Point, result.IntersectionFound.Tip)
+	case result.IntersectionNotFound != nil:
+		return t.handler.IntersectionNotFound(ctx, result.IntersectionNotFound.Tip)
+	default:
+		return nil
+	}
+}
+
+// chainSyncMessage holds a json encoded chainsync.Response that is decoded
+// at most once and only when required
+type chainSyncMessage struct {
+	data     []byte
+	response *chainsync.Response
+	err      error
+}
+
+func (m *chainSyncMessage) decode() (*chainsync.Response, error) {
+	if m.response == nil && m.err == nil {
+		var response chainsync.Response
+		if err := json.Unmarshal(m.data, &response); err != nil {
+			m.err = fmt.Errorf("failed to decode chainsync response: %w", err)
+		} else {
+			m.response = &response
+		}
+	}
+	return m.response, m.err
+}
+
+// point returns the point of the block contained in a RollForward message or
+// the target of a RollBackward message
+func (m *chainSyncMessage) point() (chainsync.Point, bool) {
+	if response, err := m.decode(); err == nil && response.Result != nil {
+		switch result := response.Result; {
+		case result.RollForward != nil:
+			ps := result.RollForward.Block.PointStruct()
+			return ps.Point(), true
+		case result.RollBackward != nil:
+			return result.RollBackward.Point, true
+		}
+	}
+	return chainsync.Point{}, false
+}
+
+// isRollBackward returns true if the message is a RollBackward.  Avoids
+// decoding the message unless it has already been decoded
+func (m *chainSyncMessage) isRollBackward() bool {
+	if m.response != nil {
+		return m.response.Result != nil && m.response.Result.RollBackward != nil
+	}
+	_, _, _, err := jsonparser.Get(m.data, "result", "RollBackward")
+	return err == nil
+}
+
 // ChainSyncOptions configuration parameters
 type ChainSyncOptions struct {
-	minSlot   uint64           // minSlot to begin invoking ChainSyncFunc; 0 for always invoke func
-	points    chainsync.Points // points to attempt initial intersection
-	reconnect bool             // reconnect to ogmios if connection drops
-	store     Store            // store of points
+	depth         uint64               // depth a block must reach before ChainSyncFunc is invoked; 0 for no delay
+	minSlot       uint64               // minSlot to begin invoking ChainSyncFunc; 0 for always invoke func
+	points        chainsync.Points     // points to attempt initial intersection
+	reconnect     bool                 // reconnect to ogmios if connection drops
+	reconnectFunc func(ReconnectEvent) // invoked prior to each reconnect attempt
+	retryPolicy   RetryPolicy          // determines if and when to reconnect
+	store         Store                // store of points
 }
 
 func buildChainSyncOptions(opts ...ChainSyncOption) ChainSyncOptions {
@@ -73,6 +176,9 @@ func buildChainSyncOptions(opts ...ChainSyncOption) ChainSyncOptions {
 	for _, opt := range opts {
 		opt(&options)
 	}
+	if options.retryPolicy == nil {
+		options.retryPolicy = defaultRetryPolicy
+	}
 	if options.store == nil {
 		options.store = nopStore{}
 	}
@@ -82,6 +188,16 @@ func buildChainSyncOptions(opts ...ChainSyncOption) ChainSyncOptions {
 // ChainSyncOption provides functional options for ChainSync
 type ChainSyncOption func(opts *ChainSyncOptions)
 
+// WithConfirmationDepth buffers blocks in memory and only invokes the callback
+// once a block is at least n blocks behind the tip.  Blocks rolled back while
+// buffered are silently discarded; the callback only receives a RollBackward
+// if it undoes blocks that were already delivered
+func WithConfirmationDepth(n uint64) ChainSyncOption {
+	return func(opts *ChainSyncOptions) {
+		opts.depth = n
+	}
+}
+
 // WithMinSlot ignores any activity prior to the specified slot
 func WithMinSlot(slot uint64) ChainSyncOption {
 	return func(opts *ChainSyncOptions) {
@@ -103,7 +219,25 @@ func WithReconnect(enabled bool) ChainSyncOption {
 	}
 }
 
-// WithStore specifies store to persist points to; defaults to no persistence
+// WithReconnectFunc registers a func to observe each reconnect attempt
+func WithReconnectFunc(fn func(ReconnectEvent)) ChainSyncOption {
+	return func(opts *ChainSyncOptions) {
+		opts.reconnectFunc = fn
+	}
+}
+
+// WithRetryPolicy enables reconnecting to ogmios using the provided policy.
+// Defaults to reconnecting on temporary errors every 10s
+func WithRetryPolicy(policy RetryPolicy) ChainSyncOption {
+	return func(opts *ChainSyncOptions) {
+		opts.reconnect = true
+		opts.retryPolicy = policy
+	}
+}
+
+// WithStore specifies store to persist points to; defaults to no persistence.
+// The store must implement RollbackStore so that rolled back points are
+// discarded; ChainSync fails otherwise
 func WithStore(store Store) ChainSyncOption {
 	return func(opts *ChainSyncOptions) {
 		opts.store = store
@@ -114,7 +248,20 @@ func WithStore(store Store) ChainSyncOption {
 // By default, ChainSync stores no checkpoints and always restarts from origin.  These can
 // be overridden via WithPoints and WithStore
 func (c *Client) ChainSync(ctx context.Context, callback ChainSyncFunc, opts ...ChainSyncOption) (*ChainSync, error) {
+	return c.chainSync(ctx, callback, opts...)
+}
+
+// ChainSyncWithHandler behaves like ChainSync, but decodes each message once and
+// dispatches it to the typed methods of the handler
+func (c *Client) ChainSyncWithHandler(ctx context.Context, handler ChainSyncHandler, opts ...ChainSyncOption) (*ChainSync, error) {
+	return c.chainSync(ctx, typedHandler{handler: handler}, opts...)
+}
+
+func (c *Client) chainSync(ctx context.Context, handler chainSyncHandler, opts ...ChainSyncOption) (*ChainSync, error) {
 	options := buildChainSyncOptions(opts...)
+	if _, ok := options.store.(RollbackStore); !ok {
+		return nil, fmt.Errorf("failed to start chain sync, %T: %w", options.store, errNotRollbackStore)
+	}
 
 	done := make(chan struct{})
 	errs := make(chan error, 1)
@@ -124,28 +271,53 @@ func (c *Client) ChainSync(ctx context.Context, callback ChainSyncFunc, opts ...
 		defer close(done)
 
 		var (
-			timeout = 10 * time.Second
+			attempt int       // consecutive failed connections
+			start   time.Time // time of the first failure
 			err     error
 		)
 		for {
-			err = c.doChainSync(ctx, callback, options)
-			if err != nil && isTemporaryError(err) {
-				if options.reconnect {
-					c.options.logger.Info("websocket connection error: will retry",
-						KV("delay", timeout.Round(time.Millisecond).String()),
-						KV("err", err.Error()),
-					)
-
-					select {
-					case <-ctx.Done():
-						return
-					case <-time.After(timeout):
-						continue
-					}
-				}
+			var received int64
+			err = c.doChainSync(ctx, handler, options, &received)
+			if err == nil || !options.reconnect {
+				break
+			}
+
+			// messages may be read for some time without reaching the handler
+			// e.g. while skipping to the min slot or awaiting confirmation so
+			// any message read marks the connection as established
+			if atomic.LoadInt64(&received) > 0 {
+				attempt = 0 // connection was established; start a new run of failures
+			}
+			if attempt == 0 {
+				start = time.Now()
 			}
+			attempt++
 
-			break
+			elapsed := time.Since(start)
+			delay, ok := options.retryPolicy.Retry(attempt, elapsed, err)
+			if !ok {
+				break
+			}
+
+			c.options.logger.Info("websocket connection error: will retry",
+				KV("attempt", strconv.Itoa(attempt)),
+				KV("delay", delay.Round(time.Millisecond).String()),
+				KV("err", err.Error()),
+			)
+			if options.reconnectFunc != nil {
+				options.reconnectFunc(ReconnectEvent{
+					Attempt: attempt,
+					Delay:   delay,
+					Elapsed: elapsed,
+					Err:     err,
+				})
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
 		}
 
 		errs <- err
@@ -159,13 +331,30 @@ func (c *Client) ChainSync(ctx context.Context, callback ChainSyncFunc, opts ...
 	}, nil
 }
 
-func (c *Client) doChainSync(ctx context.Context, callback ChainSyncFunc, options ChainSyncOptions) error {
+// chainSyncHandler is the internal interface shared by ChainSyncFunc and ChainSyncHandler
+type chainSyncHandler interface {
+	handle(ctx context.Context, msg *chainSyncMessage) error
+}
+
+// doChainSync runs a single chainsync session, incrementing received for each
+// message read from ogmios
+func (c *Client) doChainSync(ctx context.Context, handler chainSyncHandler, options ChainSyncOptions, received *int64) error {
+	v6, err := c.v6(ctx)
+	if err != nil {
+		return err
+	}
+
 	conn, _, err := websocket.DefaultDialer.Dial(c.options.endpoint, nil)
 	if err != nil {
 		return fmt.Errorf("failed to connect to ogmios, %v: %w", c.options.endpoint, err)
 	}
 
-	init, err := getInit(ctx, options.store, options.points...)
+	getInitFunc, next := getInit, nextV5
+	if v6 {
+		getInitFunc, next = getInitV6, nextV6
+	}
+
+	init, err := getInitFunc(ctx, options.store, options.points...)
 	if err != nil {
 		return fmt.Errorf("failed to create init message: %w", err)
 	}
@@ -209,7 +398,6 @@ func (c *Client) doChainSync(ctx context.Context, callback ChainSyncFunc, option
 			return fmt.Errorf("failed to write FindIntersect: %w", err)
 		}
 
-		next := []byte(`{"type":"jsonwsp/request","version":"1.0","servicename":"ogmios","methodname":"RequestNext","args":{}}`)
 		for {
 			select {
 			case <-ctx.Done():
@@ -225,6 +413,11 @@ func (c *Client) doChainSync(ctx context.Context, callback ChainSyncFunc, option
 	group.Go(func() error {
 		checkSlot := options.minSlot > 0
 		last := newCircular(3)
+
+		var queue *blockQueue
+		if options.depth > 0 {
+			queue = newBlockQueue(options.depth)
+		}
 		for n := uint64(1); ; n++ {
 			messageType, data, err := conn.ReadMessage()
 			if err != nil {
@@ -239,6 +432,7 @@ func (c *Client) doChainSync(ctx context.Context, callback ChainSyncFunc, option
 				}
 				return fmt.Errorf("failed to read message from ogmios: %w", err)
 			}
+			atomic.AddInt64(received, 1)
 
 			select {
 			case <-ctx.Done():
@@ -280,9 +474,32 @@ func (c *Client) doChainSync(ctx context.Context, callback ChainSyncFunc, option
 				// ok
 			}
 
+			msg := &chainSyncMessage{data: data}
+			if v6 {
+				if msg, err = readChainSyncV6(data); err != nil {
+					return fmt.Errorf("chainsync client failed: %w", err)
+				}
+			}
+
+			// discard checkpoints that are no longer part of the chain
+			isRollBackward := msg.isRollBackward()
+			if isRollBackward {
+				point, ok := msg.point()
+				if !ok {
+					return fmt.Errorf("chainsync client failed: unable to decode RollBackward")
+				}
+				if queue != nil && !queue.rollback(point) {
+					continue // only unconfirmed blocks were rolled back
+				}
+				if err := rollback(ctx, options.store, point); err != nil {
+					return fmt.Errorf("chainsync client failed: %w", err)
+				}
+				last = newCircular(3)
+			}
+
 			// allow rapid bypassing of earlier slots
 			if checkSlot {
-				if point, ok := getPoint(data); ok {
+				if point, ok := msg.point(); ok {
 					if ps, ok := point.PointStruct(); ok {
 						if ps.Slot < options.minSlot {
 							continue
@@ -292,25 +509,77 @@ func (c *Client) doChainSync(ctx context.Context, callback ChainSyncFunc, option
 				}
 			}
 
-			if err := callback(ctx, data); err != nil {
-				return fmt.Errorf("chainsync stopped: callback failed: %w", err)
+			msgs := []*chainSyncMessage{msg}
+			if queue != nil && !isRollBackward {
+				if msgs, err = queue.push(msg); err != nil {
+					return fmt.Errorf("chainsync client failed: %w", err)
+				}
+			}
+
+			for _, msg := range msgs {
+				if err := handler.handle(ctx, msg); err != nil {
+					return fmt.Errorf("chainsync stopped: callback failed: %w", err)
+				}
 			}
 
 			// periodically save points to the store to allow graceful recovery
-			if n%c.options.saveInterval == 0 {
-				if point, ok := getPoint(last.prefix(data)...); ok {
+			if n%c.options.saveInterval == 0 && len(msgs) > 0 {
+				if point, ok := getPoint(last.prefix(msgs[len(msgs)-1])...); ok {
 					if err := options.store.Save(ctx, point); err != nil {
 						return fmt.Errorf("chainsync client failed: %w", err)
 					}
 				}
 			}
-			last.add(data)
+			for _, msg := range msgs {
+				last.add(msg)
+			}
 		}
 	})
 	return group.Wait()
 }
 
+var (
+	nextV5 = []byte(`{"type":"jsonwsp/request","version":"1.0","servicename":"ogmios","methodname":"RequestNext","args":{}}`)
+	nextV6 = []byte(`{"jsonrpc":"2.0","method":"nextBlock"}`)
+)
+
 func getInit(ctx context.Context, store Store, pp ...chainsync.Point) (data []byte, err error) {
+	points, err := getInitPoints(ctx, store, pp...)
+	if err != nil {
+		return nil, err
+	}
+
+	init := Map{
+		"type":        "jsonwsp/request",
+		"version":     "1.0",
+		"servicename": "ogmios",
+		"methodname":  "FindIntersect",
+		"args":        Map{"points": points},
+		"mirror":      Map{"step": "INIT"},
+	}
+	return json.Marshal(init)
+}
+
+// getInitV6 returns the ogmios v6 equivalent of getInit
+func getInitV6(ctx context.Context, store Store, pp ...chainsync.Point) (data []byte, err error) {
+	points, err := getInitPoints(ctx, store, pp...)
+	if err != nil {
+		return nil, err
+	}
+
+	pointsV6 := make([]chainsync.PointV6, 0, len(points))
+	for _, point := range points {
+		pointsV6 = append(pointsV6, chainsync.PointV6(point))
+	}
+
+	init := makeRPC("findIntersection", Map{"points": pointsV6})
+	init["id"] = "INIT"
+	return json.Marshal(init)
+}
+
+// getInitPoints returns the points to intersect with; points from the store
+// take precedence over the points provided
+func getInitPoints(ctx context.Context, store Store, pp ...chainsync.Point) (chainsync.Points, error) {
 	points, err := store.Load(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve points from store: %w", err)
@@ -325,58 +594,46 @@ func getInit(ctx context.Context, store Store, pp ...chainsync.Point) (data []by
 	if len(points) > 5 {
 		points = points[0:5]
 	}
+	return points, nil
+}
 
-	init := Map{
-		"type":        "jsonwsp/request",
-		"version":     "1.0",
-		"servicename": "ogmios",
-		"methodname":  "FindIntersect",
-		"args":        Map{"points": points},
-		"mirror":      Map{"step": "INIT"},
+// readChainSyncV6 converts an ogmios v6 chain sync response into the v5
+// encoding so handlers and stores observe the same messages for either
+// protocol
+func readChainSyncV6(data []byte) (*chainSyncMessage, error) {
+	response, err := chainsync.DecodeResponseV6(data)
+	if err != nil {
+		return nil, err
 	}
-	return json.Marshal(init)
+	data, err = json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode chainsync response: %w", err)
+	}
+	return &chainSyncMessage{data: data, response: response}, nil
 }
 
-// getPoint returns the first point from the list of json encoded chainsync.Responses provided
-// multiple Responses allow for the possibility of a Rollback being included in the set
-func getPoint(data ...[]byte) (chainsync.Point, bool) {
-	for _, d := range data {
-		if len(d) == 0 {
+// getPoint returns the first point from the list of chainsync messages provided
+// multiple messages allow for the possibility of a Rollback being included in the set
+func getPoint(msgs ...*chainSyncMessage) (chainsync.Point, bool) {
+	for _, msg := range msgs {
+		if msg == nil {
 			continue
 		}
-
-		var response chainsync.Response
-		if err := json.Unmarshal(d, &response); err == nil {
-			if response.Result != nil && response.Result.RollForward != nil {
-				ps := response.Result.RollForward.Block.PointStruct()
-				return ps.Point(), true
-			}
+		if point, ok := msg.point(); ok {
+			return point, true
 		}
 	}
 	return chainsync.Point{}, false
 }
 
-// isTemporaryError returns true if the error is recoverable
-func isTemporaryError(err error) bool {
-	var wce *websocket.CloseError
-	if ok := errors.As(err, &wce); ok && wce.Code == websocket.CloseAbnormalClosure {
-		return true
-	}
-
-	var noe *net.OpError
-	if ok := errors.As(err, &noe); ok {
-		var sce *os.SyscallError
-		if ok := errors.As(noe.Err, &sce); ok && sce.Syscall == "connect" {
-			return true
-		}
-		return noe.Temporary()
+// rollback ensures the store holds no points beyond the rollback point
+func rollback(ctx context.Context, store Store, point chainsync.Point) error {
+	rs, ok := store.(RollbackStore)
+	if !ok {
+		return fmt.Errorf("failed to rollback store to %v: %w", point, errNotRollbackStore)
 	}
-
-	// handle the generic temporary error
-	var temp interface{ Temporary() bool }
-	if ok := errors.As(err, &temp); ok {
-		return temp.Temporary()
+	if err := rs.Rollback(ctx, point); err != nil {
+		return fmt.Errorf("failed to rollback store to %v: %w", point, err)
 	}
-
-	return false
+	return nil
 }
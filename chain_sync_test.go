@@ -18,15 +18,23 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"github.com/savaki/ogmigo/ouroboros/chainsync"
-	"golang.org/x/text/language"
-	"golang.org/x/text/message"
 	"log"
+	"net"
+	"net/http"
 	"os"
+	"reflect"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/savaki/ogmigo/ouroboros/chainsync"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
 )
 
 func TestClient_ChainSync(t *testing.T) {
@@ -89,6 +97,11 @@ func (e echoStore) Save(_ context.Context, p chainsync.Point) error {
 	return json.NewEncoder(os.Stdout).Encode(p)
 }
 
+func (e echoStore) Rollback(_ context.Context, p chainsync.Point) error {
+	fmt.Print("Rollback => ")
+	return json.NewEncoder(os.Stdout).Encode(p)
+}
+
 func (e echoStore) Load(context.Context) (chainsync.Points, error) {
 	return nil, nil
 }
@@ -146,3 +159,396 @@ func Test_getInit(t *testing.T) {
 		}
 	})
 }
+
+func rollForward(slot, blockNo, tip uint64) string {
+	return fmt.Sprintf(`{"type":"jsonwsp/response","version":"1.0","servicename":"ogmios","methodname":"RequestNext","result":{"RollForward":{"block":{"alonzo":{"header":{"slot":%v,"blockHeight":%v},"headerHash":"%v"}},"tip":{"slot":%v,"hash":"%v","blockNo":%v}}}}`,
+		slot, blockNo, slot, tip*10, tip*10, tip)
+}
+
+func rollBackward(slot, tip uint64) string {
+	return fmt.Sprintf(`{"type":"jsonwsp/response","version":"1.0","servicename":"ogmios","methodname":"RequestNext","result":{"RollBackward":{"point":{"slot":%v,"hash":"%v"},"tip":{"slot":%v,"hash":"%v","blockNo":%v}}}}`,
+		slot, slot, tip*10, tip*10, tip)
+}
+
+const intersectionFound = `{"type":"jsonwsp/response","version":"1.0","servicename":"ogmios","methodname":"FindIntersect","result":{"IntersectionFound":{"point":"origin","tip":{"slot":10,"hash":"10","blockNo":1}}}}`
+
+// chainSyncServer plays back the provided messages, one per RequestNext, and
+// then waits for the client to disconnect
+func chainSyncServer(t *testing.T, messages ...string) (endpoint string, closer func()) {
+	var upgrader = websocket.Upgrader{} // use default options
+
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	go func() {
+		_ = http.Serve(listener, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			c, err := upgrader.Upgrade(w, req, nil)
+			if err != nil {
+				return
+			}
+			defer c.Close()
+
+			remaining := messages
+			for {
+				var request struct{ MethodName string }
+				if err := c.ReadJSON(&request); err != nil {
+					return
+				}
+
+				var response string
+				switch {
+				case request.MethodName == "FindIntersect":
+					response = intersectionFound
+				case len(remaining) > 0:
+					response, remaining = remaining[0], remaining[1:]
+				default:
+					continue
+				}
+				if err := c.WriteMessage(websocket.TextMessage, []byte(response)); err != nil {
+					return
+				}
+			}
+		}))
+	}()
+
+	parts := strings.Split(listener.Addr().String(), ":")
+	port := parts[len(parts)-1]
+	return fmt.Sprintf("ws://127.0.0.1:%v", port), func() { listener.Close() }
+}
+
+func waitFor(t *testing.T, fn func() bool) {
+	deadline := time.Now().Add(5 * time.Second)
+	for !fn() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for condition")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+type recordingHandler struct {
+	mutex  sync.Mutex
+	events []string
+	data   int
+}
+
+func (r *recordingHandler) record(ctx context.Context, event string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.events = append(r.events, event)
+	if data, ok := ChainSyncData(ctx); ok && len(data) > 0 {
+		r.data++
+	}
+	return nil
+}
+
+func (r *recordingHandler) IntersectionFound(ctx context.Context, point, _ chainsync.Point) error {
+	return r.record(ctx, "found:"+point.String())
+}
+
+func (r *recordingHandler) IntersectionNotFound(ctx context.Context, _ chainsync.Point) error {
+	return r.record(ctx, "not found")
+}
+
+func (r *recordingHandler) RollForward(ctx context.Context, block chainsync.RollForwardBlock, _ chainsync.Point) error {
+	return r.record(ctx, fmt.Sprintf("forward:%v", block.PointStruct().Slot))
+}
+
+func (r *recordingHandler) RollBackward(ctx context.Context, point, _ chainsync.Point) error {
+	ps, _ := point.PointStruct()
+	return r.record(ctx, fmt.Sprintf("backward:%v", ps.Slot))
+}
+
+func (r *recordingHandler) Events() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]string(nil), r.events...)
+}
+
+func TestClient_ChainSyncWithHandler(t *testing.T) {
+	endpoint, closer := chainSyncServer(t,
+		rollForward(100, 1, 3),
+		rollForward(200, 2, 3),
+		rollBackward(100, 3),
+		rollForward(300, 3, 3),
+	)
+	defer closer()
+
+	var (
+		ctx     = context.Background()
+		client  = New(WithEndpoint(endpoint), WithLogger(NopLogger))
+		handler = &recordingHandler{}
+	)
+
+	cs, err := client.ChainSyncWithHandler(ctx, handler)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	want := []string{"found:origin", "forward:100", "forward:200", "backward:100", "forward:300"}
+	waitFor(t, func() bool { return len(handler.Events()) == len(want) })
+	if err := cs.Close(); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	if got := handler.Events(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+	if got, want := handler.data, len(want); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func Test_chainSyncMessage(t *testing.T) {
+	msg := &chainSyncMessage{data: []byte(rollForward(100, 1, 3))}
+	point, ok := msg.point()
+	if !ok {
+		t.Fatalf("got false; want true")
+	}
+	if got, want := point.String(), "slot=100 hash=100 block=1"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	first, _ := msg.decode()
+	second, _ := msg.decode()
+	if first != second {
+		t.Fatalf("got %p; want %p", second, first)
+	}
+
+	if msg.isRollBackward() {
+		t.Fatalf("got true; want false")
+	}
+
+	msg = &chainSyncMessage{data: []byte(rollBackward(100, 3))}
+	if !msg.isRollBackward() {
+		t.Fatalf("got false; want true")
+	}
+	point, ok = msg.point()
+	if !ok {
+		t.Fatalf("got false; want true")
+	}
+	if got, want := point.String(), "slot=100 hash=100"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+type recordingStore struct {
+	mutex    sync.Mutex
+	events   []string
+	rollback bool
+}
+
+func (r *recordingStore) record(event string, point chainsync.Point) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.events = append(r.events, event+":"+point.String())
+}
+
+func (r *recordingStore) Events() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]string(nil), r.events...)
+}
+
+func (r *recordingStore) Save(_ context.Context, point chainsync.Point) error {
+	r.record("save", point)
+	return nil
+}
+
+func (r *recordingStore) Load(context.Context) (chainsync.Points, error) {
+	return nil, nil
+}
+
+type recordingRollbackStore struct {
+	*recordingStore
+}
+
+func (r recordingRollbackStore) Rollback(_ context.Context, point chainsync.Point) error {
+	r.record("rollback", point)
+	return nil
+}
+
+func TestClient_ChainSyncRollback(t *testing.T) {
+	endpoint, closer := chainSyncServer(t,
+		rollBackward(50, 3),
+		rollForward(100, 1, 3),
+		rollForward(200, 2, 3),
+		rollBackward(100, 3),
+		rollForward(300, 3, 3),
+	)
+	defer closer()
+
+	var (
+		ctx     = context.Background()
+		client  = New(WithEndpoint(endpoint), WithLogger(NopLogger), WithInterval(1))
+		handler = &recordingHandler{}
+		store   = &recordingStore{}
+	)
+
+	cs, err := client.ChainSyncWithHandler(ctx, handler, WithStore(recordingRollbackStore{recordingStore: store}))
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	want := []string{
+		"rollback:slot=50 hash=50",
+		"save:slot=50 hash=50",
+		"save:slot=100 hash=100 block=1",
+		"save:slot=200 hash=200 block=2",
+		"rollback:slot=100 hash=100",
+		"save:slot=100 hash=100",
+		"save:slot=300 hash=300 block=3",
+	}
+	waitFor(t, func() bool { return len(store.Events()) >= len(want) })
+	if err := cs.Close(); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	if got := store.Events()[:len(want)]; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestClient_ChainSyncRequiresRollbackStore(t *testing.T) {
+	client := New(WithEndpoint("ws://127.0.0.1:0"), WithLogger(NopLogger))
+
+	_, err := client.ChainSyncWithHandler(context.Background(), &recordingHandler{}, WithStore(&recordingStore{}))
+	if !errors.Is(err, errNotRollbackStore) {
+		t.Fatalf("got %v; want %v", err, errNotRollbackStore)
+	}
+}
+
+func TestWithConfirmationDepth(t *testing.T) {
+	endpoint, closer := chainSyncServer(t,
+		rollForward(100, 1, 1),
+		rollForward(200, 2, 2),
+		rollForward(300, 3, 3),
+		rollBackward(200, 2),
+		rollForward(250, 3, 3),
+		rollForward(350, 4, 4),
+	)
+	defer closer()
+
+	var (
+		ctx     = context.Background()
+		client  = New(WithEndpoint(endpoint), WithLogger(NopLogger))
+		handler = &recordingHandler{}
+	)
+
+	cs, err := client.ChainSyncWithHandler(ctx, handler, WithConfirmationDepth(2))
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	want := []string{"found:origin", "forward:100", "forward:200"}
+	waitFor(t, func() bool { return len(handler.Events()) == len(want) })
+	if err := cs.Close(); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	if got := handler.Events(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestWithConfirmationDepth_RollbackBuffered(t *testing.T) {
+	endpoint, closer := chainSyncServer(t,
+		rollForward(100, 1, 1),
+		rollForward(200, 2, 2),
+		rollBackward(100, 1),
+		rollForward(250, 2, 3),
+		rollForward(350, 3, 4),
+	)
+	defer closer()
+
+	var (
+		ctx     = context.Background()
+		client  = New(WithEndpoint(endpoint), WithLogger(NopLogger))
+		handler = &recordingHandler{}
+	)
+
+	cs, err := client.ChainSyncWithHandler(ctx, handler, WithConfirmationDepth(2))
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	// the rollback only trims buffered blocks so it is not forwarded
+	want := []string{"found:origin", "forward:100", "forward:250"}
+	waitFor(t, func() bool { return len(handler.Events()) == len(want) })
+	if err := cs.Close(); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	if got := handler.Events(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+// TestRecordByronBlocks records the block following each point in
+// OGMIOS_BYRON_POINTS, a json array of points, as a fixture for the byron
+// decoder e.g.
+//
+//	OGMIOS=ws://localhost:1337 OGMIOS_BYRON_POINTS='[{"slot":1,"hash":"..."}]' go test -run TestRecordByronBlocks
+func TestRecordByronBlocks(t *testing.T) {
+	endpoint, raw := os.Getenv("OGMIOS"), os.Getenv("OGMIOS_BYRON_POINTS")
+	if endpoint == "" || raw == "" {
+		t.SkipNow()
+	}
+
+	var points chainsync.Points
+	if err := json.Unmarshal([]byte(raw), &points); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	client := New(WithEndpoint(endpoint), WithLogger(NopLogger))
+	for i, point := range points {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		blocks := make(chan json.RawMessage, 1)
+		callback := func(ctx context.Context, data []byte) error {
+			var response struct {
+				Result struct {
+					RollForward *struct{ Block json.RawMessage }
+				}
+			}
+			if err := json.Unmarshal(data, &response); err != nil {
+				return err
+			}
+			if rollForward := response.Result.RollForward; rollForward != nil {
+				select {
+				case blocks <- rollForward.Block:
+				default:
+				}
+			}
+			return nil
+		}
+
+		cs, err := client.ChainSync(ctx, callback, WithPoints(point))
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+
+		var block json.RawMessage
+		select {
+		case <-ctx.Done():
+			t.Fatalf("got %v; want block after %v", ctx.Err(), point)
+		case block = <-blocks:
+		}
+		_ = cs.Close()
+		cancel()
+
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, block, "", "  "); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		filename := fmt.Sprintf("ouroboros/chainsync/testdata/byron-%d.json", i)
+		if err := os.WriteFile(filename, append(buf.Bytes(), '\n'), 0644); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		t.Logf("recorded block after %v to %v", point, filename)
+	}
+}
@@ -16,8 +16,10 @@ package ogmigo
 
 // Client provides a client for the chain sync protocol only
 type Client struct {
-	logger  Logger
-	options Options
+	logger   Logger
+	options  Options
+	pool     *pool
+	protocol *protocolState
 }
 
 // New returns a new Client
@@ -26,7 +28,14 @@ func New(opts ...Option) *Client {
 	logger := options.logger.With(KV("service", "ogmios"))
 
 	return &Client{
-		logger:  logger,
-		options: options,
+		logger:   logger,
+		options:  options,
+		pool:     newPool(options.endpoint, logger, options.poolSize),
+		protocol: &protocolState{},
 	}
 }
+
+// Close releases the websocket connections held open for queries
+func (c *Client) Close() error {
+	return c.pool.Close()
+}
@@ -16,7 +16,6 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
 	"os"
@@ -93,29 +92,7 @@ func action(_ *cli.Context) error {
 		}.Point())
 	}
 
-	var counter int64
-	var callback ogmigo.ChainSyncFunc = func(ctx context.Context, data []byte) error {
-		if v := atomic.AddInt64(&counter, 1); v%opts.Tick != 0 {
-			return nil
-		}
-
-		var response chainsync.Response
-		if err := json.Unmarshal(data, &response); err != nil {
-			return err
-		}
-		if response.Result == nil {
-			return nil
-		}
-		if response.Result.RollForward == nil {
-			return nil
-		}
-
-		ps := response.Result.RollForward.Block.PointStruct()
-		fmt.Printf("slot=%v hash=%v block=%v\n", ps.Slot, ps.Hash, ps.BlockNo)
-
-		return nil
-	}
-	closer, err := client.ChainSync(ctx, callback,
+	closer, err := client.ChainSyncWithHandler(ctx, &handler{},
 		ogmigo.WithPoints(points...),
 		ogmigo.WithReconnect(true),
 	)
@@ -132,3 +109,32 @@ func action(_ *cli.Context) error {
 
 	return nil
 }
+
+// handler prints a progress message every tick blocks
+type handler struct {
+	counter int64
+}
+
+func (h *handler) IntersectionFound(context.Context, chainsync.Point, chainsync.Point) error {
+	return nil
+}
+
+func (h *handler) IntersectionNotFound(context.Context, chainsync.Point) error {
+	return nil
+}
+
+func (h *handler) RollForward(_ context.Context, block chainsync.RollForwardBlock, _ chainsync.Point) error {
+	if v := atomic.AddInt64(&h.counter, 1); v%opts.Tick != 0 {
+		return nil
+	}
+
+	ps := block.PointStruct()
+	fmt.Printf("slot=%v hash=%v block=%v\n", ps.Slot, ps.Hash, ps.BlockNo)
+
+	return nil
+}
+
+func (h *handler) RollBackward(_ context.Context, point chainsync.Point, _ chainsync.Point) error {
+	fmt.Printf("rollback to %v\n", point)
+	return nil
+}
@@ -15,6 +15,7 @@
 package ogmigo
 
 import (
+	"encoding/json"
 	"fmt"
 )
 
@@ -31,6 +32,7 @@ func (e Error) Error() string { return fmt.Sprintf("%v: %v", e.Fault.Code, e.Fau
 
 // Fault provides additional context for ogmios errors
 type Fault struct {
-	Code   string `json:"code,omitempty"`   // Code identifies error
-	String string `json:"string,omitempty"` // String provides human readable description
+	Code   string          `json:"code,omitempty"`   // Code identifies error
+	String string          `json:"string,omitempty"` // String provides human readable description
+	Data   json.RawMessage `json:"data,omitempty"`   // Data holds the details of ogmios v6 errors
 }
@@ -0,0 +1,79 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package base58 implements the bitcoin base58 alphabet used by byron addresses
+package base58
+
+import (
+	"fmt"
+	"math/big"
+)
+
+const alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var (
+	radix   = big.NewInt(58)
+	indexes [256]int
+)
+
+func init() {
+	for i := range indexes {
+		indexes[i] = -1
+	}
+	for i := 0; i < len(alphabet); i++ {
+		indexes[alphabet[i]] = i
+	}
+}
+
+// Encode returns the base58 encoding of data
+func Encode(data []byte) string {
+	var (
+		n    = new(big.Int).SetBytes(data)
+		mod  = new(big.Int)
+		text []byte
+	)
+	for n.Sign() > 0 {
+		n.DivMod(n, radix, mod)
+		text = append(text, alphabet[mod.Int64()])
+	}
+	for _, b := range data {
+		if b != 0 {
+			break
+		}
+		text = append(text, alphabet[0])
+	}
+	for i, j := 0, len(text)-1; i < j; i, j = i+1, j-1 {
+		text[i], text[j] = text[j], text[i]
+	}
+	return string(text)
+}
+
+// Decode returns the data encoded by s
+func Decode(s string) ([]byte, error) {
+	n := new(big.Int)
+	for i := 0; i < len(s); i++ {
+		v := indexes[s[i]]
+		if v < 0 {
+			return nil, fmt.Errorf("invalid base58, %v: invalid character, %c", s, s[i])
+		}
+		n.Mul(n, radix)
+		n.Add(n, big.NewInt(int64(v)))
+	}
+
+	var zeros int
+	for zeros < len(s) && s[zeros] == alphabet[0] {
+		zeros++
+	}
+	return append(make([]byte, zeros), n.Bytes()...), nil
+}
@@ -0,0 +1,45 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package base58
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	testCases := map[string][]byte{
+		"2NEpo7TZRRrLZSi2U": []byte("Hello World!"),
+		"11233QC4":          {0, 0, 0x28, 0x7f, 0xb4, 0xcd},
+		"":                  {},
+	}
+
+	for want, data := range testCases {
+		if got := Encode(data); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		got, err := Decode(want)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Fatalf("got %x; want %x", got, data)
+		}
+	}
+
+	if _, err := Decode("0OIl"); err == nil {
+		t.Fatalf("got nil; want not nil")
+	}
+}
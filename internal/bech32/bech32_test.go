@@ -0,0 +1,59 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bech32
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	testCases := map[string]struct {
+		HRP  string
+		Data string
+	}{
+		"A12UEL5L": {HRP: "a"},
+		"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw": {HRP: "abcdef", Data: "00443214c74254b635cf84653a56d7c675be77df"},
+	}
+
+	for s, tc := range testCases {
+		t.Run(s, func(t *testing.T) {
+			hrp, data, err := Decode(s)
+			if err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			if got, want := hrp, tc.HRP; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+			if got, want := hex.EncodeToString(data), tc.Data; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+
+			encoded, err := Encode(hrp, data)
+			if err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			if _, _, err := Decode(encoded); err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+		})
+	}
+
+	for _, s := range []string{"a12uel5m", "A12uEL5L", "x1b4n0q5v", "1pzry9x0s0muk"} {
+		if _, _, err := Decode(s); err == nil {
+			t.Fatalf("got nil; want err for %v", s)
+		}
+	}
+}
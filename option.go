@@ -19,6 +19,8 @@ type Options struct {
 	endpoint     string
 	logger       Logger
 	pipeline     int
+	poolSize     int
+	protocol     Protocol
 	saveInterval uint64
 }
 
@@ -53,6 +55,21 @@ func WithPipeline(n int) Option {
 	}
 }
 
+// WithPoolSize specifies the number of long-lived connections shared by queries; defaults to 1
+func WithPoolSize(n int) Option {
+	return func(opts *Options) {
+		opts.poolSize = n
+	}
+}
+
+// WithProtocol selects the ogmios wire protocol; defaults to ProtocolV5.  Use
+// ProtocolAuto to detect the protocol from the server
+func WithProtocol(protocol Protocol) Option {
+	return func(opts *Options) {
+		opts.protocol = protocol
+	}
+}
+
 func buildOptions(opts ...Option) Options {
 	var options Options
 	for _, opt := range opts {
@@ -67,6 +84,9 @@ func buildOptions(opts ...Option) Options {
 	if options.pipeline <= 0 {
 		options.pipeline = 50
 	}
+	if options.poolSize <= 0 {
+		options.poolSize = 1
+	}
 	if options.saveInterval <= 0 {
 		options.saveInterval = 2160
 	}
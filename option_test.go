@@ -41,3 +41,27 @@ func TestWithPipeline(t *testing.T) {
 		t.Fatalf("got %v; want %v", got, want)
 	}
 }
+
+func TestWithPoolSize(t *testing.T) {
+	options := buildOptions()
+	if got, want := options.poolSize, 1; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	options = buildOptions(WithPoolSize(4))
+	if got, want := options.poolSize, 4; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestWithProtocol(t *testing.T) {
+	options := buildOptions()
+	if got, want := options.protocol, ProtocolV5; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	options = buildOptions(WithProtocol(ProtocolAuto))
+	if got, want := options.protocol, ProtocolAuto; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
@@ -0,0 +1,419 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package address decodes, classifies and encodes cardano addresses; both
+// bech32 shelley addresses and base58 byron bootstrap addresses.  See CIP-19
+// https://cips.cardano.org/cips/cip19/
+package address
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/savaki/ogmigo/internal/base58"
+	"github.com/savaki/ogmigo/internal/bech32"
+)
+
+// HashSize is the size, in bytes, of key and script hashes
+const HashSize = 28
+
+// Network identifies the network of a shelley address
+type Network byte
+
+const (
+	Testnet Network = 0
+	Mainnet Network = 1
+)
+
+// Kind classifies an address
+type Kind int
+
+const (
+	KindBase       Kind = 1 // payment and stake credential
+	KindPointer    Kind = 2 // payment credential and pointer to a stake registration
+	KindEnterprise Kind = 3 // payment credential only
+	KindReward     Kind = 4 // stake credential only
+	KindByron      Kind = 5 // byron bootstrap address
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindBase:
+		return "base"
+	case KindPointer:
+		return "pointer"
+	case KindEnterprise:
+		return "enterprise"
+	case KindReward:
+		return "reward"
+	case KindByron:
+		return "byron"
+	default:
+		return "invalid"
+	}
+}
+
+// CredentialType indicates whether a credential is a key or script hash
+type CredentialType int
+
+const (
+	KeyHash    CredentialType = 0
+	ScriptHash CredentialType = 1
+)
+
+func (c CredentialType) String() string {
+	if c == ScriptHash {
+		return "script"
+	}
+	return "key"
+}
+
+// Credential holds a payment or stake credential
+type Credential struct {
+	Type CredentialType
+	Hash []byte
+}
+
+// KeyCredential returns a credential from the hash of a verification key
+func KeyCredential(hash []byte) Credential {
+	return Credential{Type: KeyHash, Hash: hash}
+}
+
+// ScriptCredential returns a credential from the hash of a script
+func ScriptCredential(hash []byte) Credential {
+	return Credential{Type: ScriptHash, Hash: hash}
+}
+
+// Hex returns the hex encoded hash of the credential
+func (c Credential) Hex() string {
+	return hex.EncodeToString(c.Hash)
+}
+
+// Pointer references the certificate that registered a stake credential
+type Pointer struct {
+	Slot      uint64
+	TxIndex   uint64
+	CertIndex uint64
+}
+
+// Address holds a decoded address.  Payment is set for base, pointer,
+// enterprise addresses; Stake for base and reward addresses; Pointer for
+// pointer addresses and Byron for byron addresses
+type Address struct {
+	Kind    Kind
+	Network Network
+	Payment Credential
+	Stake   *Credential
+	Pointer *Pointer
+	Byron   *ByronAttributes
+
+	raw []byte // original bytes of byron addresses
+}
+
+// header types as defined by CIP-19.  The low bits of the type flag script
+// credentials; bit 0 for the payment, or reward, credential and bit 1 for
+// the stake credential of base addresses
+const (
+	headerBase       = 0x00 // 0x00-0x03
+	headerPointer    = 0x04 // 0x04-0x05
+	headerEnterprise = 0x06 // 0x06-0x07
+	headerByron      = 0x08
+	headerReward     = 0x0e // 0x0e-0x0f
+	scriptBit        = 0x01
+	stakeScriptBit   = 0x02
+)
+
+// NewBaseAddress returns a base address holding payment and stake credentials
+func NewBaseAddress(network Network, payment, stake Credential) Address {
+	return Address{Kind: KindBase, Network: network, Payment: payment, Stake: &stake}
+}
+
+// NewEnterpriseAddress returns an address holding only a payment credential
+func NewEnterpriseAddress(network Network, payment Credential) Address {
+	return Address{Kind: KindEnterprise, Network: network, Payment: payment}
+}
+
+// NewPointerAddress returns an address holding a payment credential and a
+// pointer to the registration of the stake credential
+func NewPointerAddress(network Network, payment Credential, pointer Pointer) Address {
+	return Address{Kind: KindPointer, Network: network, Payment: payment, Pointer: &pointer}
+}
+
+// NewRewardAddress returns a reward, or stake, address
+func NewRewardAddress(network Network, stake Credential) Address {
+	return Address{Kind: KindReward, Network: network, Stake: &stake}
+}
+
+// Parse decodes a bech32 shelley address or a base58 byron address
+func Parse(s string) (Address, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "addr") || strings.HasPrefix(s, "stake") {
+		hrp, data, err := bech32.Decode(s)
+		if err != nil {
+			return Address{}, fmt.Errorf("failed to parse address, %v: %w", s, err)
+		}
+		addr, err := FromBytes(data)
+		if err != nil {
+			return Address{}, fmt.Errorf("failed to parse address, %v: %w", s, err)
+		}
+		if want := addr.hrp(); hrp != want {
+			return Address{}, fmt.Errorf("failed to parse address, %v: got prefix %v; want %v", s, hrp, want)
+		}
+		return addr, nil
+	}
+
+	data, err := base58.Decode(s)
+	if err != nil {
+		return Address{}, fmt.Errorf("failed to parse address, %v: %w", s, err)
+	}
+	addr, err := FromBytes(data)
+	if err != nil {
+		return Address{}, fmt.Errorf("failed to parse address, %v: %w", s, err)
+	}
+	if addr.Kind != KindByron {
+		return Address{}, fmt.Errorf("failed to parse address, %v: shelley address must be bech32", s)
+	}
+	return addr, nil
+}
+
+// FromBytes decodes the binary form of an address as found in tx cbor
+func FromBytes(data []byte) (Address, error) {
+	if len(data) == 0 {
+		return Address{}, fmt.Errorf("empty address")
+	}
+
+	header := data[0]
+	addrType, network := header>>4, Network(header&0x0f)
+	payload := data[1:]
+
+	readHash := func() ([]byte, error) {
+		if len(payload) < HashSize {
+			return nil, fmt.Errorf("truncated after %v bytes", len(data))
+		}
+		hash := append([]byte{}, payload[:HashSize]...)
+		payload = payload[HashSize:]
+		return hash, nil
+	}
+	credentialType := func(bit byte) CredentialType {
+		if addrType&bit != 0 {
+			return ScriptHash
+		}
+		return KeyHash
+	}
+
+	var addr Address
+	switch {
+	case addrType < headerPointer:
+		payment, err := readHash()
+		if err != nil {
+			return Address{}, fmt.Errorf("invalid base address: %w", err)
+		}
+		stake, err := readHash()
+		if err != nil {
+			return Address{}, fmt.Errorf("invalid base address: %w", err)
+		}
+		addr = NewBaseAddress(network,
+			Credential{Type: credentialType(scriptBit), Hash: payment},
+			Credential{Type: credentialType(stakeScriptBit), Hash: stake},
+		)
+
+	case addrType == headerPointer || addrType == headerPointer|scriptBit:
+		payment, err := readHash()
+		if err != nil {
+			return Address{}, fmt.Errorf("invalid pointer address: %w", err)
+		}
+		var pointer Pointer
+		for _, v := range []*uint64{&pointer.Slot, &pointer.TxIndex, &pointer.CertIndex} {
+			n, size, err := readVarUint(payload)
+			if err != nil {
+				return Address{}, fmt.Errorf("invalid pointer address: %w", err)
+			}
+			*v, payload = n, payload[size:]
+		}
+		addr = NewPointerAddress(network, Credential{Type: credentialType(scriptBit), Hash: payment}, pointer)
+
+	case addrType == headerEnterprise || addrType == headerEnterprise|scriptBit:
+		payment, err := readHash()
+		if err != nil {
+			return Address{}, fmt.Errorf("invalid enterprise address: %w", err)
+		}
+		addr = NewEnterpriseAddress(network, Credential{Type: credentialType(scriptBit), Hash: payment})
+
+	case addrType == headerByron:
+		return decodeByron(data)
+
+	case addrType == headerReward || addrType == headerReward|scriptBit:
+		stake, err := readHash()
+		if err != nil {
+			return Address{}, fmt.Errorf("invalid reward address: %w", err)
+		}
+		addr = NewRewardAddress(network, Credential{Type: credentialType(scriptBit), Hash: stake})
+
+	default:
+		return Address{}, fmt.Errorf("unsupported address type, %v", addrType)
+	}
+
+	if len(payload) > 0 {
+		return Address{}, fmt.Errorf("invalid %v address: %v trailing bytes", addr.Kind, len(payload))
+	}
+	return addr, nil
+}
+
+// Bytes returns the binary form of the address
+func (a Address) Bytes() []byte {
+	if a.Kind == KindByron {
+		return append([]byte{}, a.raw...)
+	}
+
+	var header byte
+	switch a.Kind {
+	case KindBase:
+		header = headerBase
+		if a.Payment.Type == ScriptHash {
+			header |= scriptBit
+		}
+		if a.Stake != nil && a.Stake.Type == ScriptHash {
+			header |= stakeScriptBit
+		}
+	case KindPointer, KindEnterprise:
+		header = headerPointer
+		if a.Kind == KindEnterprise {
+			header = headerEnterprise
+		}
+		if a.Payment.Type == ScriptHash {
+			header |= scriptBit
+		}
+	case KindReward:
+		header = headerReward
+		if a.Stake != nil && a.Stake.Type == ScriptHash {
+			header |= scriptBit
+		}
+	default:
+		return nil
+	}
+
+	data := []byte{header<<4 | byte(a.Network)&0x0f}
+	switch a.Kind {
+	case KindBase:
+		data = append(data, a.Payment.Hash...)
+		if a.Stake != nil {
+			data = append(data, a.Stake.Hash...)
+		}
+	case KindPointer:
+		data = append(data, a.Payment.Hash...)
+		if p := a.Pointer; p != nil {
+			data = appendVarUint(data, p.Slot)
+			data = appendVarUint(data, p.TxIndex)
+			data = appendVarUint(data, p.CertIndex)
+		}
+	case KindEnterprise:
+		data = append(data, a.Payment.Hash...)
+	case KindReward:
+		if a.Stake != nil {
+			data = append(data, a.Stake.Hash...)
+		}
+	}
+	return data
+}
+
+// String returns the bech32 encoding of shelley addresses and the base58
+// encoding of byron addresses; blank if the address is invalid
+func (a Address) String() string {
+	if a.Kind == KindByron {
+		return base58.Encode(a.raw)
+	}
+
+	data := a.Bytes()
+	if data == nil {
+		return ""
+	}
+	s, err := bech32.Encode(a.hrp(), data)
+	if err != nil {
+		return ""
+	}
+	return s
+}
+
+// hrp returns the bech32 human readable part of the address
+func (a Address) hrp() string {
+	hrp := "addr"
+	if a.Kind == KindReward {
+		hrp = "stake"
+	}
+	if a.Network != Mainnet {
+		hrp += "_test"
+	}
+	return hrp
+}
+
+// StakeAddress returns the reward address holding the stake credential of a
+// base address; false for other addresses
+func (a Address) StakeAddress() (Address, bool) {
+	switch {
+	case a.Kind == KindReward:
+		return a, true
+	case a.Kind == KindBase && a.Stake != nil:
+		return NewRewardAddress(a.Network, *a.Stake), true
+	default:
+		return Address{}, false
+	}
+}
+
+// PaymentKeyHash returns the payment key hash of the address; false if the
+// address has no payment credential or is paid to a script
+func (a Address) PaymentKeyHash() ([]byte, bool) {
+	switch a.Kind {
+	case KindBase, KindPointer, KindEnterprise:
+		if a.Payment.Type == KeyHash {
+			return a.Payment.Hash, true
+		}
+	}
+	return nil, false
+}
+
+// readVarUint reads a pointer component; 7 bits per byte, most significant
+// group first, with the high bit set on all but the last byte
+func readVarUint(data []byte) (uint64, int, error) {
+	var v uint64
+	for i, b := range data {
+		if v>>57 != 0 {
+			return 0, 0, fmt.Errorf("pointer component overflows uint64")
+		}
+		v = v<<7 | uint64(b&0x7f)
+		if b&0x80 == 0 {
+			return v, i + 1, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("truncated pointer component")
+}
+
+func appendVarUint(data []byte, v uint64) []byte {
+	var groups []byte
+	for {
+		groups = append(groups, byte(v&0x7f))
+		v >>= 7
+		if v == 0 {
+			break
+		}
+	}
+	for i := len(groups) - 1; i >= 0; i-- {
+		b := groups[i]
+		if i > 0 {
+			b |= 0x80
+		}
+		data = append(data, b)
+	}
+	return data
+}
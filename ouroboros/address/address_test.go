@@ -0,0 +1,201 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package address
+
+import (
+	"encoding/hex"
+	"reflect"
+	"testing"
+)
+
+// CIP-19 test vectors
+const (
+	paymentKeyHash = "9493315cd92eb5d8c4304e67b7e16ae36d61d34502694657811a2c8e"
+	stakeKeyHash   = "337b62cfff6403a06a3acbc34f8c46003c69fe79a3628cefa9c47251"
+	scriptHash     = "c37b1b5dc0669f1d3c61a6fddb2e8fde96be87b881c60bce8e8d542f"
+)
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	return data
+}
+
+func TestParse(t *testing.T) {
+	paymentKey := KeyCredential(mustDecodeHex(t, paymentKeyHash))
+	stakeKey := KeyCredential(mustDecodeHex(t, stakeKeyHash))
+	script := ScriptCredential(mustDecodeHex(t, scriptHash))
+	pointer := Pointer{Slot: 2498243, TxIndex: 27, CertIndex: 3}
+
+	testCases := map[string]Address{
+		"addr1qx2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzer3n0d3vllmyqwsx5wktcd8cc3sq835lu7drv2xwl2wywfgse35a3x":      NewBaseAddress(Mainnet, paymentKey, stakeKey),
+		"addr1z8phkx6acpnf78fuvxn0mkew3l0fd058hzquvz7w36x4gten0d3vllmyqwsx5wktcd8cc3sq835lu7drv2xwl2wywfgs9yc0hh":      NewBaseAddress(Mainnet, script, stakeKey),
+		"addr1yx2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzerkr0vd4msrxnuwnccdxlhdjar77j6lg0wypcc9uar5d2shs2z78ve":      NewBaseAddress(Mainnet, paymentKey, script),
+		"addr1x8phkx6acpnf78fuvxn0mkew3l0fd058hzquvz7w36x4gt7r0vd4msrxnuwnccdxlhdjar77j6lg0wypcc9uar5d2shskhj42g":      NewBaseAddress(Mainnet, script, script),
+		"addr1gx2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzer5pnz75xxcrzqf96k":                                          NewPointerAddress(Mainnet, paymentKey, pointer),
+		"addr128phkx6acpnf78fuvxn0mkew3l0fd058hzquvz7w36x4gtupnz75xxcrtw79hu":                                          NewPointerAddress(Mainnet, script, pointer),
+		"addr1vx2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzers66hrl8":                                                   NewEnterpriseAddress(Mainnet, paymentKey),
+		"addr1w8phkx6acpnf78fuvxn0mkew3l0fd058hzquvz7w36x4gtcyjy7wx":                                                   NewEnterpriseAddress(Mainnet, script),
+		"stake1uyehkck0lajq8gr28t9uxnuvgcqrc6070x3k9r8048z8y5gh6ffgw":                                                  NewRewardAddress(Mainnet, stakeKey),
+		"stake178phkx6acpnf78fuvxn0mkew3l0fd058hzquvz7w36x4gtcccycj5":                                                  NewRewardAddress(Mainnet, script),
+		"addr_test1qz2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzer3n0d3vllmyqwsx5wktcd8cc3sq835lu7drv2xwl2wywfgs68faae": NewBaseAddress(Testnet, paymentKey, stakeKey),
+	}
+
+	for s, want := range testCases {
+		t.Run(s, func(t *testing.T) {
+			got, err := Parse(s)
+			if err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("got %#v; want %#v", got, want)
+			}
+			if got, want := want.String(), s; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestParse_Byron(t *testing.T) {
+	testCases := map[string]struct {
+		Network        Network
+		Root           string
+		DerivationPath string
+		ProtocolMagic  uint32
+	}{
+		"Ae2tdPwUPEZ4YjgvykNpoFeYUxoyhNj2kg8KfKWN2FizsSpLUPv68MpTVDo": {
+			Network: Mainnet,
+			Root:    "4d947501de882f64dba476c342abc6b31979be1c8cfaa01f424b0779",
+		},
+		"DdzFFzCqrhsrcTVhLygT24QwTnNqQqQ8mZrq5jykUzMveU26sxaH529kMpo7VhPrt5pwW3dXeB2k3EEvKcNBRmzCfcQ7dTkyGzTs658C": {
+			Network:        Mainnet,
+			Root:           "62145da0c4df494aef8018515e540e96d179ec9d4b8aceee7bb9bc09",
+			DerivationPath: "36033c7deb0f075eae01dc90de562cb9ac13aad2548e415850df2ff3",
+		},
+		"37btjrVyb4KEB2STADSsj3MYSAdj52X5FrFWpw2r7Wmj2GDzXjFRsHWuZqrw7zSkwopv8Ci3VWeg6bisU9dgJxW5hb2MZYeduNKbQJrqz3zVBsu9nT": {
+			Network:        Testnet,
+			Root:           "9c708538a763ff27169987a489e35057ef3cd3778c05e96f7ba9450e",
+			DerivationPath: "9c1722f7e446689256e1a30260f3510d558d99d0c391f2ba89cb6977",
+			ProtocolMagic:  1097911063,
+		},
+	}
+
+	for s, tc := range testCases {
+		t.Run(s, func(t *testing.T) {
+			got, err := Parse(s)
+			if err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			if got.Kind != KindByron || got.Network != tc.Network {
+				t.Fatalf("got %v %v; want byron %v", got.Kind, got.Network, tc.Network)
+			}
+			if got, want := hex.EncodeToString(got.Byron.Root), tc.Root; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+			if got, want := hex.EncodeToString(got.Byron.DerivationPath), tc.DerivationPath; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+			if magic := got.Byron.ProtocolMagic; (magic == nil) != (tc.ProtocolMagic == 0) || (magic != nil && *magic != tc.ProtocolMagic) {
+				t.Fatalf("got %v; want %v", magic, tc.ProtocolMagic)
+			}
+			if got, want := got.String(), s; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+			if _, ok := got.PaymentKeyHash(); ok {
+				t.Fatalf("got true; want false")
+			}
+		})
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	testCases := map[string]string{
+		"empty":             "",
+		"checksum":          "addr1vx2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzers66hrl9",
+		"network mismatch":  "addr_test1vx2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzers66hrl8",
+		"byron checksum":    "Ae2tdPwUPEZ4YjgvykNpoFeYUxoyhNj2kg8KfKWN2FizsSpLUPv68MpTVDp",
+		"shelley as base58": "2fXdKm3XHKqnjLDhZT3v2cHDfTZqY7LEo6Y6Y",
+	}
+
+	for label, s := range testCases {
+		t.Run(label, func(t *testing.T) {
+			if _, err := Parse(s); err == nil {
+				t.Fatalf("got nil; want error")
+			}
+		})
+	}
+
+	if _, err := FromBytes(mustDecodeHex(t, "61"+paymentKeyHash[:10])); err == nil {
+		t.Fatalf("got nil; want error")
+	}
+	if _, err := FromBytes(mustDecodeHex(t, "61"+paymentKeyHash+"00")); err == nil {
+		t.Fatalf("got nil; want error")
+	}
+}
+
+func TestAddress_StakeAddress(t *testing.T) {
+	addr, err := Parse("addr1qx2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzer3n0d3vllmyqwsx5wktcd8cc3sq835lu7drv2xwl2wywfgse35a3x")
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	stake, ok := addr.StakeAddress()
+	if !ok {
+		t.Fatalf("got false; want true")
+	}
+	if got, want := stake.String(), "stake1uyehkck0lajq8gr28t9uxnuvgcqrc6070x3k9r8048z8y5gh6ffgw"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	hash, ok := addr.PaymentKeyHash()
+	if !ok {
+		t.Fatalf("got false; want true")
+	}
+	if got, want := hex.EncodeToString(hash), paymentKeyHash; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	enterprise, err := Parse("addr1w8phkx6acpnf78fuvxn0mkew3l0fd058hzquvz7w36x4gtcyjy7wx")
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if _, ok := enterprise.StakeAddress(); ok {
+		t.Fatalf("got true; want false")
+	}
+	if _, ok := enterprise.PaymentKeyHash(); ok {
+		t.Fatalf("got true; want false")
+	}
+}
+
+func TestVarUint(t *testing.T) {
+	for _, v := range []uint64{0, 1, 127, 128, 2498243, 1<<64 - 1} {
+		data := appendVarUint(nil, v)
+		got, n, err := readVarUint(data)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got != v || n != len(data) {
+			t.Fatalf("got %v, %v; want %v, %v", got, n, v, len(data))
+		}
+	}
+
+	if _, _, err := readVarUint([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f}); err == nil {
+		t.Fatalf("got nil; want error")
+	}
+}
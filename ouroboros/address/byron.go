@@ -0,0 +1,124 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package address
+
+import (
+	"fmt"
+	"hash/crc32"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// ByronType is the type of the spending data of a byron address
+type ByronType int
+
+const (
+	ByronPubKey ByronType = 0
+	ByronScript ByronType = 1
+	ByronRedeem ByronType = 2
+)
+
+// byron address attribute keys
+const (
+	byronDerivationPath = 1
+	byronNetworkMagic   = 2
+)
+
+// ByronAttributes holds the contents of a byron address.  DerivationPath
+// holds the encrypted derivation path of legacy daedalus wallets and
+// ProtocolMagic is set only for testnet addresses
+type ByronAttributes struct {
+	Root           []byte // hash of the spending data and attributes
+	Type           ByronType
+	DerivationPath []byte
+	ProtocolMagic  *uint32
+}
+
+// decodeByron decodes [tag 24(bytes [root, attributes, type]), crc32]
+func decodeByron(data []byte) (Address, error) {
+	var outer []cbor.RawMessage
+	if err := cbor.Unmarshal(data, &outer); err != nil {
+		return Address{}, fmt.Errorf("invalid byron address: %w", err)
+	}
+	if len(outer) != 2 {
+		return Address{}, fmt.Errorf("invalid byron address: got %v elements; want 2", len(outer))
+	}
+
+	var tag cbor.RawTag
+	if err := cbor.Unmarshal(outer[0], &tag); err != nil {
+		return Address{}, fmt.Errorf("invalid byron address: %w", err)
+	}
+	if tag.Number != 24 {
+		return Address{}, fmt.Errorf("invalid byron address: got tag %v; want 24", tag.Number)
+	}
+	var payload []byte
+	if err := cbor.Unmarshal(tag.Content, &payload); err != nil {
+		return Address{}, fmt.Errorf("invalid byron address: %w", err)
+	}
+
+	var checksum uint32
+	if err := cbor.Unmarshal(outer[1], &checksum); err != nil {
+		return Address{}, fmt.Errorf("invalid byron address: %w", err)
+	}
+	if got := crc32.ChecksumIEEE(payload); got != checksum {
+		return Address{}, fmt.Errorf("invalid byron address: got checksum %v; want %v", got, checksum)
+	}
+
+	var fields struct {
+		_          struct{} `cbor:",toarray"`
+		Root       []byte
+		Attributes map[uint64]cbor.RawMessage
+		Type       ByronType
+	}
+	if err := cbor.Unmarshal(payload, &fields); err != nil {
+		return Address{}, fmt.Errorf("invalid byron address: %w", err)
+	}
+
+	attributes := &ByronAttributes{
+		Root: fields.Root,
+		Type: fields.Type,
+	}
+	// attribute values are cbor encoded and wrapped in a byte string
+	unwrap := func(key uint64, v interface{}) error {
+		var wrapped []byte
+		if err := cbor.Unmarshal(fields.Attributes[key], &wrapped); err != nil {
+			return fmt.Errorf("invalid byron address attribute, %v: %w", key, err)
+		}
+		if err := cbor.Unmarshal(wrapped, v); err != nil {
+			return fmt.Errorf("invalid byron address attribute, %v: %w", key, err)
+		}
+		return nil
+	}
+	if _, ok := fields.Attributes[byronDerivationPath]; ok {
+		if err := unwrap(byronDerivationPath, &attributes.DerivationPath); err != nil {
+			return Address{}, err
+		}
+	}
+	network := Mainnet
+	if _, ok := fields.Attributes[byronNetworkMagic]; ok {
+		var magic uint32
+		if err := unwrap(byronNetworkMagic, &magic); err != nil {
+			return Address{}, err
+		}
+		attributes.ProtocolMagic, network = &magic, Testnet
+	}
+
+	return Address{
+		Kind:    KindByron,
+		Network: network,
+		Byron:   attributes,
+		raw:     append([]byte{}, data...),
+	}, nil
+}
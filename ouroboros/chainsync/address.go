@@ -0,0 +1,55 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"encoding/hex"
+
+	"github.com/savaki/ogmigo/ouroboros/address"
+)
+
+// ParseAddress decodes the address of the output
+func (t TxOut) ParseAddress() (address.Address, error) {
+	return address.Parse(t.Address)
+}
+
+// PaymentKeyHash returns the hex encoded payment key hash of the output
+// address; false if the address is invalid, a byron address or is paid to a
+// script
+func (t TxOut) PaymentKeyHash() (string, bool) {
+	addr, err := t.ParseAddress()
+	if err != nil {
+		return "", false
+	}
+	hash, ok := addr.PaymentKeyHash()
+	if !ok {
+		return "", false
+	}
+	return hex.EncodeToString(hash), true
+}
+
+// StakeAddress returns the bech32 stake address delegated by the output
+// address; false unless the output address is a valid base address
+func (t TxOut) StakeAddress() (string, bool) {
+	addr, err := t.ParseAddress()
+	if err != nil {
+		return "", false
+	}
+	stake, ok := addr.StakeAddress()
+	if !ok {
+		return "", false
+	}
+	return stake.String(), true
+}
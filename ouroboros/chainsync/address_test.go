@@ -0,0 +1,67 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import "testing"
+
+func TestTxOut_Address(t *testing.T) {
+	testCases := map[string]struct {
+		Address        string
+		PaymentKeyHash string
+		StakeAddress   string
+	}{
+		"base": {
+			Address:        "addr1qx2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzer3n0d3vllmyqwsx5wktcd8cc3sq835lu7drv2xwl2wywfgse35a3x",
+			PaymentKeyHash: "9493315cd92eb5d8c4304e67b7e16ae36d61d34502694657811a2c8e",
+			StakeAddress:   "stake1uyehkck0lajq8gr28t9uxnuvgcqrc6070x3k9r8048z8y5gh6ffgw",
+		},
+		"enterprise": {
+			Address:        "addr1vx2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzers66hrl8",
+			PaymentKeyHash: "9493315cd92eb5d8c4304e67b7e16ae36d61d34502694657811a2c8e",
+		},
+		"script": {
+			Address:      "addr1z8phkx6acpnf78fuvxn0mkew3l0fd058hzquvz7w36x4gten0d3vllmyqwsx5wktcd8cc3sq835lu7drv2xwl2wywfgs9yc0hh",
+			StakeAddress: "stake1uyehkck0lajq8gr28t9uxnuvgcqrc6070x3k9r8048z8y5gh6ffgw",
+		},
+		"byron": {
+			Address: "Ae2tdPwUPEZ4YjgvykNpoFeYUxoyhNj2kg8KfKWN2FizsSpLUPv68MpTVDo",
+		},
+		"invalid": {
+			Address: "addr1invalid",
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			txOut := TxOut{Address: tc.Address}
+
+			hash, ok := txOut.PaymentKeyHash()
+			if got, want := ok, tc.PaymentKeyHash != ""; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+			if got, want := hash, tc.PaymentKeyHash; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+
+			stake, ok := txOut.StakeAddress()
+			if got, want := ok, tc.StakeAddress != ""; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+			if got, want := stake, tc.StakeAddress; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+// Era names as returned by RollForwardBlock.Era
+const (
+	EraByron   = "byron"
+	EraShelley = "shelley"
+	EraAllegra = "allegra"
+	EraMary    = "mary"
+	EraAlonzo  = "alonzo"
+	EraBabbage = "babbage"
+	EraConway  = "conway"
+)
+
+// BlockInfo holds the header fields common to blocks of every era
+type BlockInfo struct {
+	Era             string
+	Hash            string
+	Height          uint64
+	Slot            uint64
+	PrevHash        string
+	ProtocolVersion ProtocolVersion
+	IssuerVK        string // genesis key of the issuer for byron blocks
+	Size            uint64 // not reported for byron blocks
+}
+
+// Era returns the name of the era of the block e.g. EraAlonzo; blank if the
+// block is empty
+func (r RollForwardBlock) Era() string {
+	era, _ := r.block()
+	return era
+}
+
+// block returns the era and shelley based block; the block is nil for byron
+func (r RollForwardBlock) block() (string, *Block) {
+	switch {
+	case r.Byron != nil:
+		return EraByron, nil
+	case r.Shelley != nil:
+		return EraShelley, r.Shelley
+	case r.Allegra != nil:
+		return EraAllegra, r.Allegra
+	case r.Mary != nil:
+		return EraMary, r.Mary
+	case r.Alonzo != nil:
+		return EraAlonzo, r.Alonzo
+	case r.Babbage != nil:
+		return EraBabbage, r.Babbage
+	case r.Conway != nil:
+		return EraConway, r.Conway
+	default:
+		return "", nil
+	}
+}
+
+// Info returns the header fields of the block regardless of era
+func (r RollForwardBlock) Info() BlockInfo {
+	if byron := r.Byron; byron != nil {
+		return BlockInfo{
+			Era:             EraByron,
+			Hash:            byron.Hash,
+			Height:          byron.Header.BlockHeight,
+			Slot:            byron.Header.Slot,
+			PrevHash:        byron.Header.PrevHash,
+			ProtocolVersion: byron.Header.ProtocolVersion,
+			IssuerVK:        byron.Header.GenesisKey,
+		}
+	}
+
+	era, block := r.block()
+	if block == nil {
+		return BlockInfo{}
+	}
+
+	header := block.Header
+	return BlockInfo{
+		Era:      era,
+		Hash:     block.HeaderHash,
+		Height:   header.BlockHeight,
+		Slot:     header.Slot,
+		PrevHash: header.PrevHash,
+		ProtocolVersion: ProtocolVersion{
+			Major: uint32(header.ProtocolVersion["major"]),
+			Minor: uint32(header.ProtocolVersion["minor"]),
+			Patch: uint32(header.ProtocolVersion["patch"]),
+		},
+		IssuerVK: header.IssuerVK,
+		Size:     header.BlockSize,
+	}
+}
+
+// Txs returns the transactions of the block normalized to Tx.  See EachTx
+func (r RollForwardBlock) Txs() []Tx {
+	if byron := r.Byron; byron != nil {
+		txs := make([]Tx, 0, len(byron.Body.TxPayload))
+		for _, payload := range byron.Body.TxPayload {
+			txs = append(txs, payload.Tx())
+		}
+		return txs
+	}
+
+	if _, block := r.block(); block != nil {
+		return block.Body
+	}
+	return nil
+}
+
+// EachTx invokes the callback with each transaction of the block, in order,
+// regardless of era.  Byron transactions are converted via ByronTxPayload.Tx
+func (r RollForwardBlock) EachTx(callback func(tx Tx) error) error {
+	for _, tx := range r.Txs() {
+		if err := callback(tx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Tx returns the byron transaction as a Tx holding the id, inputs and outputs.
+// Key witnesses are returned as signatures keyed by verification key
+func (t ByronTxPayload) Tx() Tx {
+	var signatures map[string]string
+	for _, witness := range t.Witness {
+		if witness.WitnessVk == nil {
+			continue
+		}
+		if signatures == nil {
+			signatures = map[string]string{}
+		}
+		signatures[witness.WitnessVk["key"]] = witness.WitnessVk["signature"]
+	}
+
+	return Tx{
+		ID: t.ID,
+		Body: TxBody{
+			Inputs:  t.Body.Inputs,
+			Outputs: t.Body.Outputs,
+		},
+		Witness: Witness{
+			Signatures: signatures,
+		},
+	}
+}
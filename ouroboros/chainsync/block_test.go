@@ -0,0 +1,114 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/savaki/ogmigo/ouroboros/chainsync/num"
+)
+
+func TestRollForwardBlock_Info(t *testing.T) {
+	block := decodeBlock(t, "babbage.json")
+
+	want := BlockInfo{
+		Era:             EraBabbage,
+		Hash:            "ab7c1d3e5f7a9b1c3d5e7f9a1b3c5d7e9f1a3b5c7d9e1f3a5b7c9d1e3f5a7b9c",
+		Height:          1000000,
+		Slot:            7100000,
+		PrevHash:        "5e6b5f1d3a2c4e6f8a0b2c4d6e8f0a1b3c5d7e9f1a2b4c6d8e0f2a4b6c8d0e2f",
+		ProtocolVersion: ProtocolVersion{Major: 8},
+		IssuerVK:        block.Babbage.Header.IssuerVK,
+		Size:            4096,
+	}
+	if got := block.Info(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v; want %#v", got, want)
+	}
+	if got, want := block.Era(), EraBabbage; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := len(block.Txs()), 1; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	if got := (RollForwardBlock{}).Info(); !reflect.DeepEqual(got, BlockInfo{}) {
+		t.Fatalf("got %#v; want zero", got)
+	}
+}
+
+func TestRollForwardBlock_EachTx(t *testing.T) {
+	data := `{
+  "byron": {
+    "hash": "abc",
+    "header": {"blockHeight": 10, "slot": 20, "prevHash": "def", "genesisKey": "key", "protocolVersion": {"major": 1, "minor": 0}},
+    "body": {
+      "txPayload": [
+        {
+          "id": "tx1",
+          "body": {
+            "inputs": [{"txId": "tx0", "index": 1}],
+            "outputs": [{"address": "DdzFF", "value": {"coins": 1000000}}]
+          }
+        },
+        {"id": "tx2"}
+      ]
+    }
+  }
+}`
+
+	var block RollForwardBlock
+	if err := json.Unmarshal([]byte(data), &block); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	info := block.Info()
+	if got, want := info.Era, EraByron; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := info.IssuerVK, "key"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := block.PointStruct(), (PointStruct{BlockNo: 10, Hash: "abc", Slot: 20}); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	var ids []string
+	err := block.EachTx(func(tx Tx) error {
+		ids = append(ids, tx.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if want := []string{"tx1", "tx2"}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("got %v; want %v", ids, want)
+	}
+
+	tx := block.Txs()[0]
+	if got, want := tx.Body.Inputs, []TxIn{{TxHash: "tx0", Index: 1}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v; want %#v", got, want)
+	}
+	if got, want := tx.Body.Outputs[0].Value.Coins, num.Int64(1000000); got.Int64() != want.Int64() {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	stop := errors.New("stop")
+	if err := block.EachTx(func(Tx) error { return stop }); !errors.Is(err, stop) {
+		t.Fatalf("got %v; want %v", err, stop)
+	}
+}
@@ -16,6 +16,8 @@ package chainsync
 
 import (
 	"encoding/json"
+
+	"github.com/savaki/ogmigo/ouroboros/chainsync/num"
 )
 
 type ByronBlock struct {
@@ -25,9 +27,9 @@ type ByronBlock struct {
 }
 
 type ByronBody struct {
-	DlgPayload    []json.RawMessage `json:"dlgPayload,omitempty"`
-	TxPayload     []ByronTxPayload  `json:"txPayload,omitempty"`
-	UpdatePayload json.RawMessage   `json:"updatePayload,omitempty"`
+	DlgPayload    []ByronDelegation   `json:"dlgPayload,omitempty"`
+	TxPayload     []ByronTxPayload    `json:"txPayload,omitempty"`
+	UpdatePayload *ByronUpdatePayload `json:"updatePayload,omitempty"`
 }
 
 type ByronHeader struct {
@@ -43,16 +45,98 @@ type ByronHeader struct {
 	SoftwareVersion map[string]interface{}
 }
 
+// ByronTxBody holds the inputs and outputs of a byron transaction.  Output
+// addresses are base58 encoded and values hold only lovelace
 type ByronTxBody struct {
-	Inputs  []TxIn
-	Outputs []TxOut
+	Inputs     []TxIn                     `json:"inputs,omitempty"     dynamodbav:"inputs,omitempty"`
+	Outputs    []TxOut                    `json:"outputs,omitempty"    dynamodbav:"outputs,omitempty"`
+	Attributes map[string]json.RawMessage `json:"attributes,omitempty" dynamodbav:"attributes,omitempty"`
 }
 
 type ByronTxPayload struct {
 	ID      string
+	Body    ByronTxBody
 	Witness []ByronWitness
 }
 
+// ByronWitness holds one of the byron witness types; keys and signatures are
+// hex encoded
 type ByronWitness struct {
-	RedeemWitness map[string]string
+	RedeemWitness map[string]string `json:"redeemWitness,omitempty" dynamodbav:"redeemWitness,omitempty"`
+	ScriptWitness json.RawMessage   `json:"scriptWitness,omitempty" dynamodbav:"scriptWitness,omitempty"`
+	WitnessVk     map[string]string `json:"witnessVk,omitempty"     dynamodbav:"witnessVk,omitempty"`
+}
+
+// ByronDelegation is a heavyweight delegation certificate from a genesis key
+type ByronDelegation struct {
+	Epoch      uint64 `json:"epoch"      dynamodbav:"epoch"`
+	IssuerVk   string `json:"issuerVk"   dynamodbav:"issuerVk"`
+	DelegateVk string `json:"delegateVk" dynamodbav:"delegateVk"`
+	Signature  string `json:"signature"  dynamodbav:"signature"`
+}
+
+// ByronUpdatePayload holds the update proposal, if any, and the votes cast on
+// update proposals included in the block
+type ByronUpdatePayload struct {
+	Proposal *ByronUpdateProposal `json:"proposal,omitempty" dynamodbav:"proposal,omitempty"`
+	Votes    []ByronUpdateVote    `json:"votes,omitempty"    dynamodbav:"votes,omitempty"`
+}
+
+type ByronUpdateProposal struct {
+	Body      ByronUpdateProposalBody `json:"body"      dynamodbav:"body"`
+	Issuer    string                  `json:"issuer"    dynamodbav:"issuer"`
+	Signature string                  `json:"signature" dynamodbav:"signature"`
+}
+
+type ByronUpdateProposalBody struct {
+	ProtocolVersion  ProtocolVersion            `json:"protocolVersion"            dynamodbav:"protocolVersion"`
+	SoftwareVersion  ByronSoftwareVersion       `json:"softwareVersion"            dynamodbav:"softwareVersion"`
+	ParametersUpdate ByronProtocolParameters    `json:"parametersUpdate"           dynamodbav:"parametersUpdate"`
+	Metadata         map[string]json.RawMessage `json:"metadata,omitempty"         dynamodbav:"metadata,omitempty"`
+}
+
+type ByronSoftwareVersion struct {
+	AppName string `json:"appName" dynamodbav:"appName"`
+	Number  uint32 `json:"number"  dynamodbav:"number"`
+}
+
+// ByronProtocolParameters holds the parameters updated by a proposal; only
+// the proposed parameters are set.  Thresholds are left encoded as reported
+type ByronProtocolParameters struct {
+	HeavyDlgThreshold       json.RawMessage `json:"heavyDlgThreshold,omitempty"       dynamodbav:"heavyDlgThreshold,omitempty"`
+	MaxBlockSize            *uint64         `json:"maxBlockSize,omitempty"            dynamodbav:"maxBlockSize,omitempty"`
+	MaxHeaderSize           *uint64         `json:"maxHeaderSize,omitempty"           dynamodbav:"maxHeaderSize,omitempty"`
+	MaxProposalSize         *uint64         `json:"maxProposalSize,omitempty"         dynamodbav:"maxProposalSize,omitempty"`
+	MaxTxSize               *uint64         `json:"maxTxSize,omitempty"               dynamodbav:"maxTxSize,omitempty"`
+	MpcThreshold            json.RawMessage `json:"mpcThreshold,omitempty"            dynamodbav:"mpcThreshold,omitempty"`
+	ScriptVersion           *uint64         `json:"scriptVersion,omitempty"           dynamodbav:"scriptVersion,omitempty"`
+	SlotDuration            *uint64         `json:"slotDuration,omitempty"            dynamodbav:"slotDuration,omitempty"` // milliseconds
+	SoftforkRule            json.RawMessage `json:"softforkRule,omitempty"            dynamodbav:"softforkRule,omitempty"`
+	TxFeePolicy             *ByronFeePolicy `json:"txFeePolicy,omitempty"             dynamodbav:"txFeePolicy,omitempty"`
+	UnlockStakeEpoch        *uint64         `json:"unlockStakeEpoch,omitempty"        dynamodbav:"unlockStakeEpoch,omitempty"`
+	UpdateImplicit          *uint64         `json:"updateImplicit,omitempty"          dynamodbav:"updateImplicit,omitempty"`
+	UpdateProposalThreshold json.RawMessage `json:"updateProposalThreshold,omitempty" dynamodbav:"updateProposalThreshold,omitempty"`
+	UpdateVoteThreshold     json.RawMessage `json:"updateVoteThreshold,omitempty"     dynamodbav:"updateVoteThreshold,omitempty"`
+}
+
+// ByronFeePolicy computes the minimum fee as constant + coefficient * size
+type ByronFeePolicy struct {
+	Coefficient json.Number `json:"coefficient" dynamodbav:"coefficient"`
+	Constant    json.Number `json:"constant"    dynamodbav:"constant"`
+}
+
+type ByronUpdateVote struct {
+	Voter      string `json:"voter"      dynamodbav:"voter"`
+	ProposalID string `json:"proposalId" dynamodbav:"proposalId"`
+	Signature  string `json:"signature"  dynamodbav:"signature"`
+}
+
+// OutputSum returns the lovelace sent by the transaction.  Byron transactions
+// do not declare a fee; it is the sum of the inputs less this amount
+func (b ByronTxBody) OutputSum() num.Int {
+	var sum num.Int
+	for _, output := range b.Outputs {
+		sum = sum.Add(output.Value.Coins)
+	}
+	return sum
 }
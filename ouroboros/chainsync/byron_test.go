@@ -0,0 +1,111 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TODO: testdata/byron.json is synthetic; its hashes, keys and signatures do
+// not belong to any chain.  Replace it with a mainnet byron epoch boundary
+// block and main block recorded against a mainnet ogmios with
+// TestRecordByronBlocks in the ogmigo package.
+func TestByronBlock(t *testing.T) {
+	block := decodeBlock(t, "byron.json")
+	byron := block.Byron
+	if byron == nil {
+		t.Fatalf("got nil; want byron block")
+	}
+
+	if got, want := block.PointStruct(), (PointStruct{BlockNo: 4490510, Hash: "a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90", Slot: 4471207}); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	t.Run("tx", func(t *testing.T) {
+		payload := byron.Body.TxPayload
+		if got, want := len(payload), 2; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+
+		body := payload[0].Body
+		if got, want := body.Inputs, []TxIn{{TxHash: "e2b6ee3c4e4c5c6e7f8091a2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6", Index: 0}}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %#v; want %#v", got, want)
+		}
+		if got, want := body.Outputs[1].Address, "Ae2tdPwUPEZ4YjgvykNpoFeYUxoyhNj2kg8KfKWN2FizsSpLUPv68MpTVDo"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := body.OutputSum().Int64(), int64(5999828710); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+
+		tx := payload[0].Tx()
+		if got, want := len(tx.Witness.Signatures), 1; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := payload[1].Witness[0].RedeemWitness["key"], "URVk8FxX6Ik9R-rvNOqJy7w8xKSx0dvAj8R0DJhOk3k="; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got := payload[1].Tx().Witness.Signatures; got != nil {
+			t.Fatalf("got %v; want nil", got)
+		}
+	})
+
+	t.Run("delegation", func(t *testing.T) {
+		dlg := byron.Body.DlgPayload
+		if got, want := len(dlg), 1; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := dlg[0].Epoch, uint64(200); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := dlg[0].IssuerVk, byron.Header.GenesisKey; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		update := byron.Body.UpdatePayload
+		if update == nil || update.Proposal == nil {
+			t.Fatalf("got nil; want update proposal")
+		}
+
+		body := update.Proposal.Body
+		if got, want := body.SoftwareVersion, (ByronSoftwareVersion{AppName: "cardano-sl", Number: 1}); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+
+		params := body.ParametersUpdate
+		if params.MaxTxSize == nil || *params.MaxTxSize != 4096 {
+			t.Fatalf("got %v; want 4096", params.MaxTxSize)
+		}
+		if params.TxFeePolicy == nil {
+			t.Fatalf("got nil; want fee policy")
+		}
+		if got, want := params.TxFeePolicy.Coefficient.String(), "43.946"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := string(params.UpdateVoteThreshold), `"1/1000"`; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+
+		if got, want := len(update.Votes), 1; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := update.Votes[0].ProposalID, "f4e7a3d8c1b2a5e6f7d8c9b0a1e2f3d4c5b6a7e8f9d0c1b2a3e4f5d6c7b8a9e0"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+}
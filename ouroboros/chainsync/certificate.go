@@ -0,0 +1,196 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/savaki/ogmigo/ouroboros/chainsync/num"
+)
+
+// Certificate types as returned by Certificate.Type, in addition to the DRep
+// certificate types
+const (
+	StakeKeyRegistration     = "stakeKeyRegistration"
+	StakeKeyDeregistration   = "stakeKeyDeregistration"
+	StakeDelegation          = "stakeDelegation"
+	PoolRegistration         = "poolRegistration"
+	PoolRetirement           = "poolRetirement"
+	GenesisDelegation        = "genesisDelegation"
+	MoveInstantaneousRewards = "moveInstantaneousRewards"
+)
+
+// Certificate holds exactly one certificate of a tx.  Pre-conway certificates
+// are encoded as an object keyed by type e.g. {"stakeDelegation": {...}};
+// delegate representative certificates use the flat DRepCertificate encoding.
+// Certificates of types not listed here are kept, unparsed, in Unknown
+type Certificate struct {
+	StakeKeyRegistration     string                        `json:"stakeKeyRegistration,omitempty"     dynamodbav:"stakeKeyRegistration,omitempty"`   // stake credential
+	StakeKeyDeregistration   string                        `json:"stakeKeyDeregistration,omitempty"   dynamodbav:"stakeKeyDeregistration,omitempty"` // stake credential
+	StakeDelegation          *StakeDelegationCertificate   `json:"stakeDelegation,omitempty"          dynamodbav:"stakeDelegation,omitempty"`
+	PoolRegistration         *PoolParameters               `json:"poolRegistration,omitempty"         dynamodbav:"poolRegistration,omitempty"`
+	PoolRetirement           *PoolRetirementCertificate    `json:"poolRetirement,omitempty"           dynamodbav:"poolRetirement,omitempty"`
+	GenesisDelegation        *GenesisDelegationCertificate `json:"genesisDelegation,omitempty"        dynamodbav:"genesisDelegation,omitempty"`
+	MoveInstantaneousRewards *MIRCertificate               `json:"moveInstantaneousRewards,omitempty" dynamodbav:"moveInstantaneousRewards,omitempty"`
+	DRep                     *DRepCertificate              `json:"-"                                  dynamodbav:"delegateRepresentative,omitempty"`
+	Unknown                  json.RawMessage               `json:"-"                                  dynamodbav:"unknown,omitempty"`
+}
+
+// StakeDelegationCertificate delegates a stake credential to a pool
+type StakeDelegationCertificate struct {
+	Delegator string `json:"delegator" dynamodbav:"delegator"` // stake credential
+	Delegatee string `json:"delegatee" dynamodbav:"delegatee"` // pool id
+}
+
+// PoolParameters registers, or updates, a stake pool
+type PoolParameters struct {
+	ID            string        `json:"id,omitempty"       dynamodbav:"id,omitempty"` // blank for the parameters returned by queries
+	VRF           string        `json:"vrf"                dynamodbav:"vrf"`
+	Pledge        num.Int       `json:"pledge"             dynamodbav:"pledge"`
+	Cost          num.Int       `json:"cost"               dynamodbav:"cost"`
+	Margin        Ratio         `json:"margin"             dynamodbav:"margin"`
+	RewardAccount string        `json:"rewardAccount"      dynamodbav:"rewardAccount"`
+	Owners        []string      `json:"owners"             dynamodbav:"owners"`
+	Relays        []Relay       `json:"relays"             dynamodbav:"relays"`
+	Metadata      *PoolMetadata `json:"metadata,omitempty" dynamodbav:"metadata,omitempty"`
+}
+
+// Relay describes how to reach a stake pool.  Either an ip address or a
+// hostname will be set
+type Relay struct {
+	IPv4     string `json:"ipv4,omitempty"     dynamodbav:"ipv4,omitempty"`
+	IPv6     string `json:"ipv6,omitempty"     dynamodbav:"ipv6,omitempty"`
+	Hostname string `json:"hostname,omitempty" dynamodbav:"hostname,omitempty"`
+	Port     uint16 `json:"port,omitempty"     dynamodbav:"port,omitempty"`
+}
+
+// PoolMetadata references the off chain metadata of a pool
+type PoolMetadata struct {
+	URL  string `json:"url"  dynamodbav:"url"`
+	Hash string `json:"hash" dynamodbav:"hash"`
+}
+
+// PoolRetirementCertificate announces the epoch at which a pool retires
+type PoolRetirementCertificate struct {
+	PoolID          string `json:"poolId"          dynamodbav:"poolId"`
+	RetirementEpoch uint64 `json:"retirementEpoch" dynamodbav:"retirementEpoch"`
+}
+
+// GenesisDelegationCertificate delegates the rights of a genesis key
+type GenesisDelegationCertificate struct {
+	VerificationKeyHash    string `json:"verificationKeyHash"    dynamodbav:"verificationKeyHash"`
+	DelegateKeyHash        string `json:"delegateKeyHash"        dynamodbav:"delegateKeyHash"`
+	VRFVerificationKeyHash string `json:"vrfVerificationKeyHash" dynamodbav:"vrfVerificationKeyHash"`
+}
+
+// MIRCertificate moves rewards from the reserves or treasury, given by Pot.
+// Either Rewards, keyed by stake credential, or Value, transferred to the
+// other pot, is set
+type MIRCertificate struct {
+	Pot     string             `json:"pot"               dynamodbav:"pot"`
+	Rewards map[string]num.Int `json:"rewards,omitempty" dynamodbav:"rewards,omitempty"`
+	Value   *num.Int           `json:"value,omitempty"   dynamodbav:"value,omitempty"`
+}
+
+// certificate prevents recursion when marshalling Certificate
+type certificate Certificate
+
+// Type returns the type of the certificate e.g. StakeDelegation or
+// DRepRegistration; blank if the certificate is unknown
+func (c Certificate) Type() string {
+	switch {
+	case c.StakeKeyRegistration != "":
+		return StakeKeyRegistration
+	case c.StakeKeyDeregistration != "":
+		return StakeKeyDeregistration
+	case c.StakeDelegation != nil:
+		return StakeDelegation
+	case c.PoolRegistration != nil:
+		return PoolRegistration
+	case c.PoolRetirement != nil:
+		return PoolRetirement
+	case c.GenesisDelegation != nil:
+		return GenesisDelegation
+	case c.MoveInstantaneousRewards != nil:
+		return MoveInstantaneousRewards
+	case c.DRep != nil:
+		return c.DRep.Type
+	default:
+		return ""
+	}
+}
+
+func (c Certificate) MarshalJSON() ([]byte, error) {
+	switch {
+	case c.DRep != nil:
+		return json.Marshal(c.DRep)
+	case c.Type() == "" && len(c.Unknown) > 0:
+		return c.Unknown, nil
+	default:
+		return json.Marshal(certificate(c))
+	}
+}
+
+func (c *Certificate) UnmarshalDynamoDBAttributeValue(item *dynamodb.AttributeValue) error {
+	switch {
+	case item == nil:
+		return nil
+	case item.B != nil: // certificates were previously stored as raw json
+		return c.UnmarshalJSON(item.B)
+	}
+
+	var cert certificate
+	if err := dynamodbattribute.Unmarshal(item, &cert); err != nil {
+		return fmt.Errorf("failed to unmarshal certificate: %w", err)
+	}
+	*c = Certificate(cert)
+	return nil
+}
+
+func (c *Certificate) UnmarshalJSON(data []byte) error {
+	var peek struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &peek); err != nil {
+		return fmt.Errorf("failed to unmarshal certificate, %v: %w", string(data), err)
+	}
+
+	switch peek.Type {
+	case "":
+	case DRepRegistration, DRepUpdate, DRepRetirement, DRepVoteDelegation:
+		var cert DRepCertificate
+		if err := json.Unmarshal(data, &cert); err != nil {
+			return fmt.Errorf("failed to decode %v certificate: %w", peek.Type, err)
+		}
+		*c = Certificate{DRep: &cert}
+		return nil
+	default:
+		*c = Certificate{Unknown: append(json.RawMessage(nil), data...)}
+		return nil
+	}
+
+	var cert certificate
+	if err := json.Unmarshal(data, &cert); err != nil {
+		return fmt.Errorf("failed to unmarshal certificate, %v: %w", string(data), err)
+	}
+	*c = Certificate(cert)
+	if c.Type() == "" {
+		c.Unknown = append(json.RawMessage(nil), data...)
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+func TestCertificate(t *testing.T) {
+	testCases := map[string]struct {
+		Data string
+		Type string
+	}{
+		"stakeKeyRegistration": {
+			Data: `{"stakeKeyRegistration":"e0c7a2c9d4b6e8f0a1b3c5d7e9f1a2b4c6d8e0f2a4b6c8d0e2f4a6b8"}`,
+			Type: StakeKeyRegistration,
+		},
+		"stakeKeyDeregistration": {
+			Data: `{"stakeKeyDeregistration":"e0c7a2c9d4b6e8f0a1b3c5d7e9f1a2b4c6d8e0f2a4b6c8d0e2f4a6b8"}`,
+			Type: StakeKeyDeregistration,
+		},
+		"stakeDelegation": {
+			Data: `{"stakeDelegation":{"delegator":"e0c7","delegatee":"pool1m5947rydk4n0ywe6ctlav0ztt632lcwjef7fsy93sflz7ctcx6z"}}`,
+			Type: StakeDelegation,
+		},
+		"poolRegistration": {
+			Data: `{"poolRegistration":{"id":"pool1abc","vrf":"c2b1","pledge":100000000,"cost":340000000,"margin":"1/20","rewardAccount":"stake1uabc","owners":["a1b2"],"relays":[{"ipv4":"192.168.0.1","port":3001},{"hostname":"relay.example.com","port":3001}],"metadata":{"url":"https://example.com/pool.json","hash":"0a1b"}}}`,
+			Type: PoolRegistration,
+		},
+		"poolRetirement": {
+			Data: `{"poolRetirement":{"poolId":"pool1abc","retirementEpoch":300}}`,
+			Type: PoolRetirement,
+		},
+		"genesisDelegation": {
+			Data: `{"genesisDelegation":{"verificationKeyHash":"a1","delegateKeyHash":"b2","vrfVerificationKeyHash":"c3"}}`,
+			Type: GenesisDelegation,
+		},
+		"moveInstantaneousRewards": {
+			Data: `{"moveInstantaneousRewards":{"pot":"reserves","rewards":{"e0c7":1000000}}}`,
+			Type: MoveInstantaneousRewards,
+		},
+		"moveInstantaneousRewards value": {
+			Data: `{"moveInstantaneousRewards":{"pot":"treasury","value":5000000}}`,
+			Type: MoveInstantaneousRewards,
+		},
+		"drep": {
+			Data: `{"type":"voteDelegation","delegateRepresentative":{"type":"abstain"},"credential":"e0c7"}`,
+			Type: DRepVoteDelegation,
+		},
+		"unknown": {
+			Data: `{"type":"constitutionalCommitteeDelegation","member":{"id":"a1"}}`,
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			var cert Certificate
+			if err := json.Unmarshal([]byte(tc.Data), &cert); err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			if got, want := cert.Type(), tc.Type; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+
+			data, err := json.Marshal(cert)
+			if err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			assertJSONEqual(t, data, []byte(tc.Data))
+
+			item, err := dynamodbattribute.Marshal(cert)
+			if err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			var got Certificate
+			if err := dynamodbattribute.Unmarshal(item, &got); err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			if !reflect.DeepEqual(got, cert) {
+				t.Fatalf("got %#v; want %#v", got, cert)
+			}
+
+			// certificates were previously stored as raw json
+			got = Certificate{}
+			if err := got.UnmarshalDynamoDBAttributeValue(&dynamodb.AttributeValue{B: []byte(tc.Data)}); err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			if !reflect.DeepEqual(got, cert) {
+				t.Fatalf("got %#v; want %#v", got, cert)
+			}
+		})
+	}
+
+	t.Run("pool parameters", func(t *testing.T) {
+		var cert Certificate
+		if err := json.Unmarshal([]byte(testCases["poolRegistration"].Data), &cert); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		params := cert.PoolRegistration
+		if got, want := params.Pledge.Int64(), int64(100000000); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := params.Relays, []Relay{{IPv4: "192.168.0.1", Port: 3001}, {Hostname: "relay.example.com", Port: 3001}}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %#v; want %#v", got, want)
+		}
+		if got, want := params.Metadata.URL, "https://example.com/pool.json"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := params.Margin.Float64(), 0.05; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+}
+
+func TestCertificateV6(t *testing.T) {
+	testCases := map[string]struct {
+		Data string
+		Want []Certificate
+	}{
+		"registration": {
+			Data: `{"type":"stakeCredentialRegistration","credential":"e0c7"}`,
+			Want: []Certificate{{StakeKeyRegistration: "e0c7"}},
+		},
+		"deregistration": {
+			Data: `{"type":"stakeCredentialDeregistration","credential":"e0c7","deposit":{"ada":{"lovelace":2000000}}}`,
+			Want: []Certificate{{StakeKeyDeregistration: "e0c7"}},
+		},
+		"pool retirement": {
+			Data: `{"type":"stakePoolRetirement","stakePool":{"id":"pool1abc","retirementEpoch":300}}`,
+			Want: []Certificate{{PoolRetirement: &PoolRetirementCertificate{PoolID: "pool1abc", RetirementEpoch: 300}}},
+		},
+		"genesis delegation": {
+			Data: `{"type":"genesisDelegation","delegate":{"id":"b2","vrfVerificationKeyHash":"c3"},"issuer":{"id":"a1"}}`,
+			Want: []Certificate{{GenesisDelegation: &GenesisDelegationCertificate{VerificationKeyHash: "a1", DelegateKeyHash: "b2", VRFVerificationKeyHash: "c3"}}},
+		},
+		"stake and vote delegation": {
+			Data: `{"type":"stakeDelegation","credential":"e0c7","stakePool":{"id":"pool1abc"},"delegateRepresentative":{"type":"noConfidence"}}`,
+			Want: []Certificate{
+				{StakeDelegation: &StakeDelegationCertificate{Delegator: "e0c7", Delegatee: "pool1abc"}},
+				{DRep: &DRepCertificate{Type: DRepVoteDelegation, DRep: DRep{Type: "noConfidence"}, Credential: "e0c7"}},
+			},
+		},
+		"unknown": {
+			Data: `{"type":"constitutionalCommitteeRetirement","member":{"id":"a1"}}`,
+			Want: []Certificate{{Unknown: json.RawMessage(`{"type":"constitutionalCommitteeRetirement","member":{"id":"a1"}}`)}},
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			got, err := certificateV6(json.RawMessage(tc.Data))
+			if err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			if !reflect.DeepEqual(got, tc.Want) {
+				t.Fatalf("got %#v; want %#v", got, tc.Want)
+			}
+		})
+	}
+
+	t.Run("pool registration", func(t *testing.T) {
+		data := `{"type":"stakePoolRegistration","stakePool":{"id":"pool1abc","vrfVerificationKeyHash":"c2b1","pledge":{"ada":{"lovelace":100}},"cost":{"ada":{"lovelace":340}},"margin":"1/20","rewardAccount":"stake1uabc","owners":["a1b2"],"relays":[{"type":"hostname","hostname":"relay.example.com","port":3001}],"metadata":{"url":"https://example.com/pool.json","hash":"0a1b"}}}`
+		got, err := certificateV6(json.RawMessage(data))
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		params := got[0].PoolRegistration
+		if params == nil {
+			t.Fatalf("got nil; want pool parameters")
+		}
+		if got, want := params.VRF, "c2b1"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := params.Cost.Int64(), int64(340); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := params.Relays, []Relay{{Hostname: "relay.example.com", Port: 3001}}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %#v; want %#v", got, want)
+		}
+	})
+}
+
+func assertJSONEqual(t *testing.T, got, want []byte) {
+	t.Helper()
+
+	var a, b interface{}
+	if err := json.Unmarshal(got, &a); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if err := json.Unmarshal(want, &b); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Fatalf("got %s; want %s", got, want)
+	}
+}
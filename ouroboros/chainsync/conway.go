@@ -0,0 +1,111 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"encoding/json"
+
+	"github.com/savaki/ogmigo/ouroboros/chainsync/num"
+)
+
+// Anchor references off chain metadata by url and hash
+type Anchor struct {
+	URL  string `json:"url"  dynamodbav:"url"`
+	Hash string `json:"hash" dynamodbav:"hash"`
+}
+
+// GovernanceActionID identifies a proposal by the tx that submitted it and
+// its index within the tx
+type GovernanceActionID struct {
+	TxID  string `json:"txId"  dynamodbav:"txId"`
+	Index int    `json:"index" dynamodbav:"index"`
+}
+
+// Voter identifies the issuer of a vote.  Role is one of
+// constitutionalCommittee, delegateRepresentative or stakePoolOperator
+type Voter struct {
+	Role string `json:"role" dynamodbav:"role"`
+	ID   string `json:"id"   dynamodbav:"id"`
+}
+
+// Vote cast by a voter on a proposal; Vote is one of yes, no or abstain
+type Vote struct {
+	Issuer   Voter              `json:"issuer"           dynamodbav:"issuer"`
+	Proposal GovernanceActionID `json:"proposal"         dynamodbav:"proposal"`
+	Vote     string             `json:"vote"             dynamodbav:"vote"`
+	Anchor   *Anchor            `json:"anchor,omitempty" dynamodbav:"anchor,omitempty"`
+}
+
+// GovernanceAction describes the action a proposal would enact.  Type is one
+// of parameterChange, hardForkInitiation, treasuryWithdrawals, noConfidence,
+// updateCommittee, newConstitution or information.  Only the fields relevant
+// to Type are set
+type GovernanceAction struct {
+	Type         string              `json:"type"                   dynamodbav:"type"`
+	Ancestor     *GovernanceActionID `json:"ancestor,omitempty"     dynamodbav:"ancestor,omitempty"`
+	Parameters   json.RawMessage     `json:"parameters,omitempty"   dynamodbav:"parameters,omitempty"`   // parameterChange
+	Version      *ProtocolVersion    `json:"version,omitempty"      dynamodbav:"version,omitempty"`      // hardForkInitiation
+	Withdrawals  map[string]num.Int  `json:"withdrawals,omitempty"  dynamodbav:"withdrawals,omitempty"`  // treasuryWithdrawals
+	Members      json.RawMessage     `json:"members,omitempty"      dynamodbav:"members,omitempty"`      // updateCommittee
+	Quorum       string              `json:"quorum,omitempty"       dynamodbav:"quorum,omitempty"`       // updateCommittee
+	Constitution json.RawMessage     `json:"constitution,omitempty" dynamodbav:"constitution,omitempty"` // newConstitution
+}
+
+// Proposal submits a governance action for voting
+type Proposal struct {
+	Deposit       num.Int          `json:"deposit"       dynamodbav:"deposit"`
+	ReturnAccount string           `json:"returnAccount" dynamodbav:"returnAccount"`
+	Anchor        Anchor           `json:"anchor"        dynamodbav:"anchor"`
+	Action        GovernanceAction `json:"action"        dynamodbav:"action"`
+}
+
+// DRep identifies a delegate representative.  Type is registered, abstain or
+// noConfidence; ID and From, either verificationKey or script, are only set
+// for registered representatives
+type DRep struct {
+	Type string `json:"type"           dynamodbav:"type"`
+	ID   string `json:"id,omitempty"   dynamodbav:"id,omitempty"`
+	From string `json:"from,omitempty" dynamodbav:"from,omitempty"`
+}
+
+// DRep certificate types
+const (
+	DRepRegistration   = "delegateRepresentativeRegistration"
+	DRepUpdate         = "delegateRepresentativeUpdate"
+	DRepRetirement     = "delegateRepresentativeRetirement"
+	DRepVoteDelegation = "voteDelegation"
+)
+
+// DRepCertificate registers, updates or retires a delegate representative, or
+// delegates the voting power of a stake credential to one
+type DRepCertificate struct {
+	Type       string   `json:"type"                 dynamodbav:"type"`
+	DRep       DRep     `json:"delegateRepresentative" dynamodbav:"delegateRepresentative"`
+	Credential string   `json:"credential,omitempty" dynamodbav:"credential,omitempty"` // voteDelegation
+	Deposit    *num.Int `json:"deposit,omitempty"    dynamodbav:"deposit,omitempty"`
+	Anchor     *Anchor  `json:"anchor,omitempty"     dynamodbav:"anchor,omitempty"`
+}
+
+// DRepCertificates returns the delegate representative certificates of the
+// tx; other certificates are skipped
+func (t TxBody) DRepCertificates() []DRepCertificate {
+	var certs []DRepCertificate
+	for _, cert := range t.Certificates {
+		if cert.DRep != nil {
+			certs = append(certs, *cert.DRep)
+		}
+	}
+	return certs
+}
@@ -0,0 +1,130 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+// decodeBlock decodes the RollForwardBlock fixture, disallowing unknown fields
+func decodeBlock(t *testing.T, filename string) RollForwardBlock {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", filename))
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	var block RollForwardBlock
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&block); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	item, err := dynamodbattribute.Marshal(block)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	var got RollForwardBlock
+	if err := dynamodbattribute.Unmarshal(item, &got); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := got.PointStruct(), block.PointStruct(); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	return block
+}
+
+func TestBabbageBlock(t *testing.T) {
+	block := decodeBlock(t, "babbage.json")
+
+	ps := block.PointStruct()
+	if got, want := ps.BlockNo, uint64(1000000); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := ps.Slot, uint64(7100000); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if ps.Hash == "" {
+		t.Fatalf("got blank; want not blank")
+	}
+	if block.Babbage.Header.VrfInput == nil {
+		t.Fatalf("got nil; want not nil")
+	}
+
+	body := block.Babbage.Body[0].Body
+	if got, want := len(body.References), 1; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := body.TotalCollateral.Int64(), int64(500000); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := body.CollateralReturn.Value.Coins.Int64(), int64(4500000); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	txOut := body.Outputs[0]
+	if got, want := txOut.Datum, "d87980"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if txOut.DatumHash == "" {
+		t.Fatalf("got blank; want not blank")
+	}
+	if len(txOut.Script) == 0 {
+		t.Fatalf("got empty; want reference script")
+	}
+}
+
+func TestConwayBlock(t *testing.T) {
+	block := decodeBlock(t, "conway.json")
+
+	if got, want := block.PointStruct().BlockNo, uint64(2000000); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	body := block.Conway.Body[0].Body
+	if got, want := len(body.Votes), 1; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := body.Votes[0].Issuer.Role, "delegateRepresentative"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := len(body.Proposals), 1; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := body.Proposals[0].Action.Version.Major, uint32(10); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := body.Donation.Int64(), int64(1000000); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	certs := body.DRepCertificates()
+	if got, want := len(certs), 2; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := certs[0].Deposit.Int64(), int64(500000000); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := certs[1].DRep.Type, "abstain"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
@@ -0,0 +1,269 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/savaki/ogmigo/ouroboros/address"
+	"github.com/savaki/ogmigo/ouroboros/chainsync/num"
+)
+
+// script ref languages as defined by the cardano ledger cddl
+var scriptLanguages = map[string]uint64{
+	ScriptNative:   0,
+	ScriptPlutusV1: 1,
+	ScriptPlutusV2: 2,
+	ScriptPlutusV3: 3,
+}
+
+// EncodeTxOut returns the ledger cbor encoding of the output.  Outputs with
+// an inline datum or a reference script use the babbage map format; others
+// use the more compact legacy array format.  A Datum equal to DatumHash, as
+// reported by alonzo, is treated as a datum hash; any other Datum is inline
+func EncodeTxOut(txOut TxOut) ([]byte, error) {
+	encMode, err := encOptions.EncMode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode output: %w", err)
+	}
+
+	addr, err := address.Parse(txOut.Address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode output: %w", err)
+	}
+	value, err := EncodeValue(txOut.Value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode output: %w", err)
+	}
+
+	datumHash, datum := txOut.DatumHash, txOut.Datum
+	if datum == datumHash {
+		datum = ""
+	}
+	var hash []byte
+	if datumHash != "" {
+		if hash, err = hex.DecodeString(datumHash); err != nil {
+			return nil, fmt.Errorf("failed to encode output: invalid datum hash: %w", err)
+		}
+	}
+
+	if datum == "" && len(txOut.Script) == 0 {
+		items := []interface{}{addr.Bytes(), cbor.RawMessage(value)}
+		if hash != nil {
+			items = append(items, hash)
+		}
+		return encMode.Marshal(items)
+	}
+
+	fields := map[uint64]interface{}{
+		0: addr.Bytes(),
+		1: cbor.RawMessage(value),
+	}
+	switch {
+	case datum != "":
+		data, err := hex.DecodeString(datum)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode output: invalid datum: %w", err)
+		}
+		fields[2] = []interface{}{1, cbor.Tag{Number: 24, Content: data}}
+	case hash != nil:
+		fields[2] = []interface{}{0, hash}
+	}
+	if len(txOut.Script) > 0 {
+		ref, err := encodeScriptRef(txOut.Script)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode output: invalid script: %w", err)
+		}
+		fields[3] = cbor.Tag{Number: 24, Content: ref}
+	}
+	return encMode.Marshal(fields)
+}
+
+// EncodeValue returns the ledger cbor encoding of an output value; coin if
+// the value holds only coins and [coin, multiasset] otherwise
+func EncodeValue(v Value) ([]byte, error) {
+	encMode, err := encOptions.EncMode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode value: %w", err)
+	}
+
+	coins, ok := v.Coins.ToUint64()
+	if !ok {
+		return nil, fmt.Errorf("failed to encode value: invalid coins, %v", v.Coins)
+	}
+	if len(v.AssetIDs()) == 0 {
+		return encMode.Marshal(coins)
+	}
+
+	assets, err := multiAsset(v, func(quantity num.Int) (interface{}, bool) { return quantity.ToUint64() })
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode value: %w", err)
+	}
+	return encMode.Marshal([]interface{}{coins, assets})
+}
+
+// EncodeMint returns the ledger cbor encoding of the assets minted, positive
+// quantities, and burned, negative quantities, by a tx.  Coins are ignored
+func EncodeMint(v Value) ([]byte, error) {
+	encMode, err := encOptions.EncMode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode mint: %w", err)
+	}
+
+	assets, err := multiAsset(v, func(quantity num.Int) (interface{}, bool) { return quantity.ToInt64() })
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode mint: %w", err)
+	}
+	return encMode.Marshal(assets)
+}
+
+// multiAsset returns the nonzero assets of v as {policy id: {asset name:
+// quantity}}; quantity converts each quantity to the encoded integer type
+func multiAsset(v Value, quantity func(num.Int) (interface{}, bool)) (map[byteString]map[byteString]interface{}, error) {
+	assets := map[byteString]map[byteString]interface{}{}
+	for _, id := range v.AssetIDs() {
+		policyID, err := hex.DecodeString(id.PolicyID())
+		if err != nil {
+			return nil, fmt.Errorf("invalid asset id, %v: %w", id, err)
+		}
+		name, err := hex.DecodeString(id.AssetName())
+		if err != nil {
+			return nil, fmt.Errorf("invalid asset id, %v: %w", id, err)
+		}
+		q, ok := quantity(v.Assets[id])
+		if !ok {
+			return nil, fmt.Errorf("invalid quantity, %v: %v", id, v.Assets[id])
+		}
+
+		names, ok := assets[byteString(policyID)]
+		if !ok {
+			names = map[byteString]interface{}{}
+			assets[byteString(policyID)] = names
+		}
+		names[byteString(name)] = q
+	}
+	return assets, nil
+}
+
+// EncodeNativeScript returns the ledger cbor encoding of a native script
+func EncodeNativeScript(n NativeScript) ([]byte, error) {
+	v, err := nativeScriptItems(n)
+	if err != nil {
+		return nil, err
+	}
+	return cbor.Marshal(v)
+}
+
+func nativeScriptItems(n NativeScript) ([]interface{}, error) {
+	scripts := func() ([]interface{}, error) {
+		items := []interface{}{}
+		for _, script := range n.Scripts {
+			item, err := nativeScriptItems(script)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	}
+
+	switch n.Type {
+	case NativeScriptSignature:
+		keyHash, err := hex.DecodeString(n.KeyHash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode native script: invalid key hash, %v: %w", n.KeyHash, err)
+		}
+		return []interface{}{0, keyHash}, nil
+	case NativeScriptAll, NativeScriptAny:
+		items, err := scripts()
+		if err != nil {
+			return nil, err
+		}
+		if n.Type == NativeScriptAll {
+			return []interface{}{1, items}, nil
+		}
+		return []interface{}{2, items}, nil
+	case NativeScriptAtLeast:
+		items, err := scripts()
+		if err != nil {
+			return nil, err
+		}
+		return []interface{}{3, n.Required, items}, nil
+	case NativeScriptStartsAt:
+		return []interface{}{4, n.Slot}, nil
+	case NativeScriptExpiresAt:
+		return []interface{}{5, n.Slot}, nil
+	default:
+		return nil, fmt.Errorf("failed to encode native script: unknown type, %v", n.Type)
+	}
+}
+
+// encodeScriptRef encodes a reference script as [language, script].  Both the
+// v5 form, {"plutus:v2": hex}, and the v6 form, {"language": ..., "cbor": ...}
+// are accepted along with {"native": hex} as produced by DecodeTx
+func encodeScriptRef(data json.RawMessage) ([]byte, error) {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+
+	var script Script
+	if _, ok := m["language"]; ok {
+		var v v6Script
+		if err := json.Unmarshal(data, &v); err != nil {
+			return nil, err
+		}
+		script = v.script()
+		if v.CBOR != "" {
+			script.Native, script.CBOR = nil, v.CBOR // prefer the original encoding
+		}
+	} else if raw, ok := m[ScriptNative]; ok && len(m) == 1 && len(raw) > 0 && raw[0] == '"' {
+		script.Language = ScriptNative
+		if err := json.Unmarshal(raw, &script.CBOR); err != nil {
+			return nil, err
+		}
+	} else if err := json.Unmarshal(data, &script); err != nil {
+		return nil, err
+	}
+
+	language, ok := scriptLanguages[script.Language]
+	if !ok {
+		return nil, fmt.Errorf("unknown script language, %v", script.Language)
+	}
+
+	var body interface{}
+	switch {
+	case script.Language == ScriptNative && script.Native != nil:
+		items, err := nativeScriptItems(*script.Native)
+		if err != nil {
+			return nil, err
+		}
+		body = items
+	default:
+		raw, err := hex.DecodeString(script.CBOR)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %v script: %w", script.Language, err)
+		}
+		if script.Language == ScriptNative {
+			body = cbor.RawMessage(raw)
+		} else {
+			body = raw
+		}
+	}
+	return cbor.Marshal([]interface{}{language, body})
+}
@@ -0,0 +1,169 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/savaki/ogmigo/ouroboros/chainsync/num"
+)
+
+func TestEncodeTxOut(t *testing.T) {
+	const (
+		baseAddress = "addr1qx2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzer3n0d3vllmyqwsx5wktcd8cc3sq835lu7drv2xwl2wywfgse35a3x"
+		byron       = "Ae2tdPwUPEZ4YjgvykNpoFeYUxoyhNj2kg8KfKWN2FizsSpLUPv68MpTVDo"
+		datumHash   = "923918e403bf43c34b4ef6b48eb2ee04babed17320d8d1b9ff9ad086e86f44ec"
+	)
+	value := Value{
+		Coins: num.Int64(2000000),
+		Assets: map[AssetID]num.Int{
+			testAssetA1: num.Int64(10),
+			testAssetA2: num.Int64(3),
+			testAssetB:  num.Int64(1),
+		},
+	}
+
+	testCases := map[string]struct {
+		TxOut     TxOut
+		MajorType byte
+	}{
+		"ada only": {
+			TxOut:     TxOut{Address: baseAddress, Value: Value{Coins: num.Int64(1000000)}},
+			MajorType: 4,
+		},
+		"byron": {
+			TxOut:     TxOut{Address: byron, Value: Value{Coins: num.Int64(1000000)}},
+			MajorType: 4,
+		},
+		"assets and datum hash": {
+			TxOut:     TxOut{Address: baseAddress, Value: value, Datum: datumHash, DatumHash: datumHash},
+			MajorType: 4,
+		},
+		"babbage datum hash": {
+			TxOut:     TxOut{Address: baseAddress, Value: value, DatumHash: datumHash, Script: json.RawMessage(`{"plutus:v2":"4e4d01000033222220051200120011"}`)},
+			MajorType: 5,
+		},
+		"inline datum": {
+			TxOut:     TxOut{Address: baseAddress, Value: value, Datum: "d87980"},
+			MajorType: 5,
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			data, err := EncodeTxOut(tc.TxOut)
+			if err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			if got, want := data[0]>>5, tc.MajorType; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+
+			got, err := decodeTxOut(data)
+			if err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			gotJSON, err := json.Marshal(got)
+			if err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			wantJSON, err := json.Marshal(tc.TxOut)
+			if err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			assertJSONEqual(t, gotJSON, wantJSON)
+		})
+	}
+
+	// size of [address, coin] with a 57 byte address and 4 byte coin
+	data, err := EncodeTxOut(testCases["ada only"].TxOut)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := len(data), 1+2+57+5; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	if _, err := EncodeTxOut(TxOut{Address: baseAddress, Value: Value{Coins: num.Int64(-1)}}); err == nil {
+		t.Fatalf("got nil; want error")
+	}
+	if _, err := EncodeTxOut(TxOut{Address: "addr1invalid"}); err == nil {
+		t.Fatalf("got nil; want error")
+	}
+}
+
+func TestEncodeTxOut_NativeScript(t *testing.T) {
+	const address = "addr1vx2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzers66hrl8"
+	native := NativeScript{
+		Type: NativeScriptAll,
+		Scripts: []NativeScript{
+			{Type: NativeScriptSignature, KeyHash: "9493315cd92eb5d8c4304e67b7e16ae36d61d34502694657811a2c8e"},
+			{Type: NativeScriptExpiresAt, Slot: 1000},
+		},
+	}
+	encoded, err := EncodeNativeScript(native)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := hex.EncodeToString(encoded), "8201828200581c9493315cd92eb5d8c4304e67b7e16ae36d61d34502694657811a2c8e82051903e8"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	// the json, v6 and decoded forms of the same script encode identically
+	scripts := map[string]string{
+		"json":    `{"native":{"all":["9493315cd92eb5d8c4304e67b7e16ae36d61d34502694657811a2c8e",{"expiresAt":1000}]}}`,
+		"v6 json": `{"language":"native","json":{"clause":"all","from":[{"clause":"signature","from":"9493315cd92eb5d8c4304e67b7e16ae36d61d34502694657811a2c8e"},{"clause":"before","slot":1000}]}}`,
+		"v6 cbor": `{"language":"native","cbor":"` + hex.EncodeToString(encoded) + `"}`,
+		"decoded": `{"native":"` + hex.EncodeToString(encoded) + `"}`,
+	}
+	want := `{"native":"` + hex.EncodeToString(encoded) + `"}`
+	for label, script := range scripts {
+		t.Run(label, func(t *testing.T) {
+			data, err := EncodeTxOut(TxOut{Address: address, Value: Value{Coins: num.Int64(1)}, Script: json.RawMessage(script)})
+			if err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			got, err := decodeTxOut(data)
+			if err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			if got := string(got.Script); got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestEncodeMint(t *testing.T) {
+	mint := Value{
+		Assets: map[AssetID]num.Int{
+			testAssetA1: num.Int64(100),
+			testAssetB:  num.Int64(-5),
+		},
+	}
+	data, err := EncodeMint(mint)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	got, err := decodeMint(data)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if !got.Equal(mint) {
+		t.Fatalf("got %v; want %v", got, mint)
+	}
+}
@@ -29,6 +29,11 @@ func Int64(v int64) Int {
 	return Int(*bi)
 }
 
+func Uint64(v uint64) Int {
+	bi := new(big.Int).SetUint64(v)
+	return Int(*bi)
+}
+
 func New(s string) (Int, bool) {
 	bi, ok := big.NewInt(0).SetString(s, 10)
 	if !ok {
@@ -38,6 +43,11 @@ func New(s string) (Int, bool) {
 	return Int(*bi), true
 }
 
+func (i Int) Abs() Int {
+	abs := big.NewInt(0).Abs(i.BigInt())
+	return Int(*abs)
+}
+
 func (i Int) Add(that Int) Int {
 	sum := big.NewInt(0).Add(i.BigInt(), that.BigInt())
 	return Int(*sum)
@@ -48,14 +58,34 @@ func (i Int) BigInt() *big.Int {
 	return &bi
 }
 
+// Cmp returns -1, 0 or +1 as i is less than, equal to or greater than that
+func (i Int) Cmp(that Int) int {
+	return i.BigInt().Cmp(that.BigInt())
+}
+
+// Div returns the quotient i/that truncated towards zero.  Div panics if
+// that is zero
+func (i Int) Div(that Int) Int {
+	quo := big.NewInt(0).Quo(i.BigInt(), that.BigInt())
+	return Int(*quo)
+}
+
+// Int returns the int value of i; the result is undefined if i does not fit.
+// See ToInt
 func (i Int) Int() int {
 	return int(i.BigInt().Int64())
 }
 
+// Int64 returns the int64 value of i; the result is undefined if i does not
+// fit.  See ToInt64
 func (i Int) Int64() int64 {
 	return i.BigInt().Int64()
 }
 
+func (i Int) IsZero() bool {
+	return i.Sign() == 0
+}
+
 func (i Int) MarshalDynamoDBAttributeValue(item *dynamodb.AttributeValue) error {
 	item.N = aws.String(i.BigInt().String())
 	return nil
@@ -66,6 +96,21 @@ func (i Int) MarshalJSON() ([]byte, error) {
 	return []byte(s), nil
 }
 
+func (i Int) Mul(that Int) Int {
+	product := big.NewInt(0).Mul(i.BigInt(), that.BigInt())
+	return Int(*product)
+}
+
+func (i Int) Neg() Int {
+	neg := big.NewInt(0).Neg(i.BigInt())
+	return Int(*neg)
+}
+
+// Sign returns -1, 0 or +1 as i is negative, zero or positive
+func (i Int) Sign() int {
+	return i.BigInt().Sign()
+}
+
 func (i Int) String() string {
 	return i.BigInt().String()
 }
@@ -75,6 +120,34 @@ func (i Int) Sub(that Int) Int {
 	return Int(*sum)
 }
 
+// ToInt returns the int value of i; false if i does not fit in an int
+func (i Int) ToInt() (int, bool) {
+	v, ok := i.ToInt64()
+	if !ok || int64(int(v)) != v {
+		return 0, false
+	}
+	return int(v), true
+}
+
+// ToInt64 returns the int64 value of i; false if i does not fit in an int64
+func (i Int) ToInt64() (int64, bool) {
+	bi := i.BigInt()
+	if !bi.IsInt64() {
+		return 0, false
+	}
+	return bi.Int64(), true
+}
+
+// ToUint64 returns the uint64 value of i; false if i is negative or does not
+// fit in a uint64
+func (i Int) ToUint64() (uint64, bool) {
+	bi := i.BigInt()
+	if !bi.IsUint64() {
+		return 0, false
+	}
+	return bi.Uint64(), true
+}
+
 func (i *Int) UnmarshalDynamoDBAttributeValue(item *dynamodb.AttributeValue) error {
 	if aws.BoolValue(item.NULL) {
 		return nil
@@ -16,6 +16,7 @@ package num
 
 import (
 	"encoding/json"
+	"math"
 	"reflect"
 	"testing"
 )
@@ -48,6 +49,61 @@ func TestMath(t *testing.T) {
 	if got, want := a.Sub(b).Int(), 75; got != want {
 		t.Fatalf("got %v; want %v", got, want)
 	}
+	if got, want := a.Mul(b).Int(), 2500; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := a.Div(Int64(30)).Int(), 3; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := a.Neg().Div(Int64(30)).Int(), -3; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := b.Sub(a).Abs().Int(), 75; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := a.Cmp(b), 1; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := b.Cmp(a), -1; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := a.Neg().Sign(), -1; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	var zero Int
+	if !zero.IsZero() || zero.Sign() != 0 || zero.Cmp(Int64(0)) != 0 {
+		t.Fatalf("got %v; want zero", zero)
+	}
+	if got, want := zero.Add(a).Int(), 100; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestConversion(t *testing.T) {
+	maxUint64 := Uint64(math.MaxUint64)
+
+	if got, ok := Int64(-1).ToInt64(); !ok || got != -1 {
+		t.Fatalf("got %v, %v; want -1, true", got, ok)
+	}
+	if _, ok := maxUint64.ToInt64(); ok {
+		t.Fatalf("got true; want false")
+	}
+	if got, ok := maxUint64.ToUint64(); !ok || got != math.MaxUint64 {
+		t.Fatalf("got %v, %v; want %v, true", got, ok, uint64(math.MaxUint64))
+	}
+	if _, ok := maxUint64.Add(Int64(1)).ToUint64(); ok {
+		t.Fatalf("got true; want false")
+	}
+	if _, ok := Int64(-1).ToUint64(); ok {
+		t.Fatalf("got true; want false")
+	}
+	if got, ok := Int64(42).ToInt(); !ok || got != 42 {
+		t.Fatalf("got %v, %v; want 42, true", got, ok)
+	}
+	if _, ok := maxUint64.ToInt(); ok {
+		t.Fatalf("got true; want false")
+	}
 }
 
 func TestNew(t *testing.T) {
@@ -0,0 +1,475 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/savaki/ogmigo/internal/base58"
+	"github.com/savaki/ogmigo/internal/bech32"
+	"github.com/savaki/ogmigo/ouroboros/chainsync/num"
+	"golang.org/x/crypto/blake2b"
+)
+
+// tx body map keys as defined by the cardano ledger cddl
+const (
+	txBodyInputs              = 0
+	txBodyOutputs             = 1
+	txBodyFee                 = 2
+	txBodyTimeToLive          = 3
+	txBodyWithdrawals         = 5
+	txBodyValidityStart       = 8
+	txBodyMint                = 9
+	txBodyScriptIntegrityHash = 11
+	txBodyCollaterals         = 13
+	txBodyRequiredSigners     = 14
+	txBodyNetwork             = 15
+	txBodyCollateralReturn    = 16
+	txBodyTotalCollateral     = 17
+	txBodyReferences          = 18
+	txBodyTreasury            = 21
+	txBodyDonation            = 22
+)
+
+// byteString holds a cbor byte string; unlike []byte it may be used as a map key
+type byteString string
+
+func (b byteString) MarshalCBOR() ([]byte, error) {
+	return cbor.Marshal([]byte(b))
+}
+
+func (b *byteString) UnmarshalBinary(data []byte) error {
+	*b = byteString(data)
+	return nil
+}
+
+// DecodeTxHex decodes the hex encoded cbor of a signed tx.  See DecodeTx
+func DecodeTxHex(s string) (Tx, error) {
+	data, err := hex.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return Tx{}, fmt.Errorf("failed to decode tx: invalid hex: %w", err)
+	}
+	return DecodeTx(data)
+}
+
+// DecodeTx decodes the cbor of a signed shelley era or later tx.  The tx id
+// is the blake2b-256 hash of the body.  Only the body is decoded; the
+// witnesses and metadata are validated as well formed cbor, but not
+// interpreted.  Certificates, protocol updates, votes and proposals are not
+// decoded
+func DecodeTx(data []byte) (Tx, error) {
+	var items []cbor.RawMessage
+	if err := cbor.Unmarshal(data, &items); err != nil {
+		return Tx{}, fmt.Errorf("failed to decode tx: %w", err)
+	}
+	// [body, witnesses, metadata] prior to alonzo; [body, witnesses, isValid, metadata] after
+	if len(items) != 3 && len(items) != 4 {
+		return Tx{}, fmt.Errorf("failed to decode tx: got %v elements; want 3 or 4", len(items))
+	}
+
+	body, err := decodeTxBody(items[0])
+	if err != nil {
+		return Tx{}, fmt.Errorf("failed to decode tx: %w", err)
+	}
+
+	hash := blake2b.Sum256(items[0])
+	return Tx{
+		ID:   hex.EncodeToString(hash[:]),
+		Body: body,
+	}, nil
+}
+
+func decodeTxBody(data []byte) (TxBody, error) {
+	var fields map[uint64]cbor.RawMessage
+	if err := cbor.Unmarshal(data, &fields); err != nil {
+		return TxBody{}, fmt.Errorf("failed to decode body: %w", err)
+	}
+	for _, key := range []uint64{txBodyInputs, txBodyOutputs, txBodyFee} {
+		if _, ok := fields[key]; !ok {
+			return TxBody{}, fmt.Errorf("failed to decode body: missing required field, %v", key)
+		}
+	}
+
+	var body TxBody
+	for key, raw := range fields {
+		var err error
+		switch key {
+		case txBodyInputs:
+			body.Inputs, err = decodeTxIns(raw)
+		case txBodyOutputs:
+			body.Outputs, err = decodeTxOuts(raw)
+		case txBodyFee:
+			var fee uint64
+			err = cbor.Unmarshal(raw, &fee)
+			body.Fee = num.Uint64(fee)
+		case txBodyTimeToLive:
+			var ttl uint64
+			err = cbor.Unmarshal(raw, &ttl)
+			body.TimeToLive = int64(ttl)
+			body.ValidityInterval.InvalidHereafter = ttl
+		case txBodyWithdrawals:
+			body.Withdrawals, err = decodeWithdrawals(raw)
+		case txBodyValidityStart:
+			err = cbor.Unmarshal(raw, &body.ValidityInterval.InvalidBefore)
+		case txBodyMint:
+			var mint Value
+			mint, err = decodeMint(raw)
+			body.Mint = &mint
+		case txBodyScriptIntegrityHash:
+			body.ScriptIntegrityHash, err = decodeHex(raw)
+		case txBodyCollaterals:
+			var ins []TxIn
+			ins, err = decodeTxIns(raw)
+			for _, in := range ins {
+				body.Collaterals = append(body.Collaterals, Collateral{TxId: in.TxHash, Index: in.Index})
+			}
+		case txBodyRequiredSigners:
+			var signers [][]byte
+			err = cbor.Unmarshal(raw, &signers)
+			for _, signer := range signers {
+				body.RequiredExtraSignatures = append(body.RequiredExtraSignatures, hex.EncodeToString(signer))
+			}
+		case txBodyCollateralReturn:
+			var txOut TxOut
+			txOut, err = decodeTxOut(raw)
+			body.CollateralReturn = &txOut
+		case txBodyTotalCollateral:
+			body.TotalCollateral, err = decodeCoin(raw)
+		case txBodyReferences:
+			body.References, err = decodeTxIns(raw)
+		case txBodyTreasury:
+			body.Treasury, err = decodeCoin(raw)
+		case txBodyDonation:
+			body.Donation, err = decodeCoin(raw)
+		case txBodyNetwork:
+			var network uint64
+			err = cbor.Unmarshal(raw, &network)
+			body.Network = json.RawMessage(`"testnet"`)
+			if network == 1 {
+				body.Network = json.RawMessage(`"mainnet"`)
+			}
+		}
+		if err != nil {
+			return TxBody{}, fmt.Errorf("failed to decode body field, %v: %w", key, err)
+		}
+	}
+
+	return body, nil
+}
+
+func decodeCoin(data []byte) (*num.Int, error) {
+	var v uint64
+	if err := cbor.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	coin := num.Uint64(v)
+	return &coin, nil
+}
+
+func decodeHex(data []byte) (string, error) {
+	var v []byte
+	if err := cbor.Unmarshal(data, &v); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(v), nil
+}
+
+// decodeTxIns decodes an array or set (tag 258) of [hash, index] pairs
+func decodeTxIns(data []byte) ([]TxIn, error) {
+	var items []struct {
+		_      struct{} `cbor:",toarray"`
+		TxHash []byte
+		Index  uint64
+	}
+	if err := cbor.Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
+
+	txIns := make([]TxIn, 0, len(items))
+	for _, item := range items {
+		if len(item.TxHash) != 32 {
+			return nil, fmt.Errorf("invalid tx hash length, %v", len(item.TxHash))
+		}
+		txIns = append(txIns, TxIn{
+			TxHash: hex.EncodeToString(item.TxHash),
+			Index:  int(item.Index),
+		})
+	}
+	return txIns, nil
+}
+
+// decodeTxOuts decodes both the legacy array outputs and the map outputs
+// introduced in babbage
+func decodeTxOuts(data []byte) (TxOuts, error) {
+	var items []cbor.RawMessage
+	if err := cbor.Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
+
+	txOuts := make(TxOuts, 0, len(items))
+	for i, item := range items {
+		txOut, err := decodeTxOut(item)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode output, %v: %w", i, err)
+		}
+		txOuts = append(txOuts, txOut)
+	}
+	return txOuts, nil
+}
+
+func decodeTxOut(data []byte) (TxOut, error) {
+	var (
+		address   []byte
+		amount    cbor.RawMessage
+		datum     string
+		datumHash string
+		script    json.RawMessage
+	)
+
+	switch majorType := data[0] >> 5; majorType {
+	case 4: // [address, amount, ? datum hash]
+		var items []cbor.RawMessage
+		if err := cbor.Unmarshal(data, &items); err != nil {
+			return TxOut{}, err
+		}
+		if len(items) < 2 || len(items) > 3 {
+			return TxOut{}, fmt.Errorf("got %v elements; want 2 or 3", len(items))
+		}
+		if err := cbor.Unmarshal(items[0], &address); err != nil {
+			return TxOut{}, fmt.Errorf("failed to decode address: %w", err)
+		}
+		amount = items[1]
+		if len(items) == 3 {
+			v, err := decodeHex(items[2])
+			if err != nil {
+				return TxOut{}, fmt.Errorf("failed to decode datum hash: %w", err)
+			}
+			datum, datumHash = v, v // alonzo reports the datum hash as the datum
+		}
+
+	case 5: // {0: address, 1: amount, ? 2: datum option, ? 3: script ref}
+		var fields map[uint64]cbor.RawMessage
+		if err := cbor.Unmarshal(data, &fields); err != nil {
+			return TxOut{}, err
+		}
+		if err := cbor.Unmarshal(fields[0], &address); err != nil {
+			return TxOut{}, fmt.Errorf("failed to decode address: %w", err)
+		}
+		amount = fields[1]
+		if raw, ok := fields[2]; ok {
+			var option struct {
+				_    struct{} `cbor:",toarray"`
+				Kind uint64
+				Data cbor.RawMessage
+			}
+			if err := cbor.Unmarshal(raw, &option); err != nil {
+				return TxOut{}, fmt.Errorf("failed to decode datum: %w", err)
+			}
+			switch option.Kind {
+			case 0: // datum hash
+				v, err := decodeHex(option.Data)
+				if err != nil {
+					return TxOut{}, fmt.Errorf("failed to decode datum hash: %w", err)
+				}
+				datumHash = v
+			case 1: // inline datum wrapped in tag 24
+				var tag cbor.RawTag
+				if err := cbor.Unmarshal(option.Data, &tag); err != nil {
+					return TxOut{}, fmt.Errorf("failed to decode inline datum: %w", err)
+				}
+				v, err := decodeHex(tag.Content)
+				if err != nil {
+					return TxOut{}, fmt.Errorf("failed to decode inline datum: %w", err)
+				}
+				datum = v
+			}
+		}
+		if raw, ok := fields[3]; ok {
+			v, err := decodeScriptRef(raw)
+			if err != nil {
+				return TxOut{}, fmt.Errorf("failed to decode script ref: %w", err)
+			}
+			script = v
+		}
+
+	default:
+		return TxOut{}, fmt.Errorf("unexpected cbor major type, %v", majorType)
+	}
+
+	encoded, err := encodeAddress(address)
+	if err != nil {
+		return TxOut{}, err
+	}
+	value, err := decodeValue(amount)
+	if err != nil {
+		return TxOut{}, fmt.Errorf("failed to decode value: %w", err)
+	}
+
+	return TxOut{
+		Address:   encoded,
+		Datum:     datum,
+		DatumHash: datumHash,
+		Script:    script,
+		Value:     value,
+	}, nil
+}
+
+// decodeScriptRef decodes tag 24 wrapping [language, script].  Plutus scripts
+// are returned as {"plutus:vN": hex}; native scripts as {"native": hex} as
+// their json form is not derivable from cbor alone
+func decodeScriptRef(data []byte) (json.RawMessage, error) {
+	var tag cbor.RawTag
+	if err := cbor.Unmarshal(data, &tag); err != nil {
+		return nil, err
+	}
+	var wrapped []byte
+	if err := cbor.Unmarshal(tag.Content, &wrapped); err != nil {
+		return nil, err
+	}
+
+	var ref struct {
+		_        struct{} `cbor:",toarray"`
+		Language uint64
+		Script   cbor.RawMessage
+	}
+	if err := cbor.Unmarshal(wrapped, &ref); err != nil {
+		return nil, err
+	}
+
+	if ref.Language == 0 {
+		return json.Marshal(map[string]string{"native": hex.EncodeToString(ref.Script)})
+	}
+
+	script, err := decodeHex(ref.Script)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(map[string]string{fmt.Sprintf("plutus:v%v", ref.Language): script})
+}
+
+// encodeAddress encodes byron addresses as base58 and shelley addresses as bech32
+func encodeAddress(address []byte) (string, error) {
+	if len(address) == 0 {
+		return "", fmt.Errorf("failed to encode address: empty address")
+	}
+
+	header := address[0]
+	if header>>4 == 0x08 {
+		return base58.Encode(address), nil
+	}
+
+	hrp := "addr"
+	if header>>4 == 0x0e || header>>4 == 0x0f {
+		hrp = "stake"
+	}
+	if header&0x0f != 1 {
+		hrp += "_test"
+	}
+	return bech32.Encode(hrp, address)
+}
+
+// decodeValue decodes either coin or [coin, multiasset]
+func decodeValue(data []byte) (Value, error) {
+	if len(data) == 0 {
+		return Value{}, fmt.Errorf("missing value")
+	}
+	if data[0]>>5 == 0 {
+		var coins uint64
+		if err := cbor.Unmarshal(data, &coins); err != nil {
+			return Value{}, err
+		}
+		return Value{Coins: num.Uint64(coins)}, nil
+	}
+
+	var v struct {
+		_      struct{} `cbor:",toarray"`
+		Coins  uint64
+		Assets cbor.RawMessage
+	}
+	if err := cbor.Unmarshal(data, &v); err != nil {
+		return Value{}, err
+	}
+
+	value, err := decodeMultiAsset(v.Assets)
+	if err != nil {
+		return Value{}, err
+	}
+	value.Coins = num.Uint64(v.Coins)
+	return value, nil
+}
+
+// decodeMultiAsset decodes the {policy id: {asset name: quantity}} of an
+// output; quantities are unsigned and may exceed an int64
+func decodeMultiAsset(data []byte) (Value, error) {
+	var assets map[byteString]map[byteString]uint64
+	if err := cbor.Unmarshal(data, &assets); err != nil {
+		return Value{}, err
+	}
+
+	value := Value{Assets: map[AssetID]num.Int{}}
+	for policyID, names := range assets {
+		for name, quantity := range names {
+			value.Assets[assetID(policyID, name)] = num.Uint64(quantity)
+		}
+	}
+	return value, nil
+}
+
+// decodeMint decodes the {policy id: {asset name: quantity}} of mint;
+// quantities are signed to accommodate burns
+func decodeMint(data []byte) (Value, error) {
+	var assets map[byteString]map[byteString]int64
+	if err := cbor.Unmarshal(data, &assets); err != nil {
+		return Value{}, err
+	}
+
+	value := Value{Assets: map[AssetID]num.Int{}}
+	for policyID, names := range assets {
+		for name, quantity := range names {
+			value.Assets[assetID(policyID, name)] = num.Int64(quantity)
+		}
+	}
+	return value, nil
+}
+
+func assetID(policyID, name byteString) AssetID {
+	id := hex.EncodeToString([]byte(policyID))
+	if name != "" {
+		id += "." + hex.EncodeToString([]byte(name))
+	}
+	return AssetID(id)
+}
+
+// decodeWithdrawals decodes {reward address: coin} keyed by bech32 stake address
+func decodeWithdrawals(data []byte) (map[string]int64, error) {
+	var items map[byteString]uint64
+	if err := cbor.Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
+
+	withdrawals := map[string]int64{}
+	for address, amount := range items {
+		encoded, err := encodeAddress([]byte(address))
+		if err != nil {
+			return nil, err
+		}
+		withdrawals[encoded] = int64(amount)
+	}
+	return withdrawals, nil
+}
@@ -0,0 +1,152 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"golang.org/x/crypto/blake2b"
+)
+
+func TestDecodeTx(t *testing.T) {
+	var (
+		txHash  = bytes.Repeat([]byte{0x01}, 32)
+		policy  = bytes.Repeat([]byte{0x02}, 28)
+		address = append([]byte{0x61}, bytes.Repeat([]byte{0x03}, 28)...) // mainnet enterprise
+		reward  = append([]byte{0xe1}, bytes.Repeat([]byte{0x04}, 28)...)
+		datum   = bytes.Repeat([]byte{0x05}, 32)
+	)
+
+	body, err := cbor.Marshal(map[uint64]interface{}{
+		0: cbor.Tag{Number: 258, Content: []interface{}{[]interface{}{txHash, 1}}},
+		1: []interface{}{
+			[]interface{}{address, 2000000},
+			[]interface{}{address, []interface{}{1500000, map[byteString]map[byteString]uint64{byteString(policy): {"coin": 10}}}, datum},
+			map[uint64]interface{}{0: address, 1: 3000000, 2: []interface{}{1, cbor.Tag{Number: 24, Content: []byte{0x01}}}},
+			[]interface{}{address, []interface{}{1500000, map[byteString]map[byteString]uint64{byteString(policy): {"coin": math.MaxUint64}}}},
+		},
+		2:  170000,
+		3:  1000,
+		5:  map[byteString]uint64{byteString(reward): 42},
+		8:  900,
+		9:  map[byteString]map[byteString]int64{byteString(policy): {"coin": -5}},
+		13: []interface{}{[]interface{}{txHash, 0}},
+		15: 1,
+		17: 500000,
+		18: []interface{}{[]interface{}{txHash, 2}},
+	})
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	data, err := cbor.Marshal([]interface{}{cbor.RawMessage(body), map[uint64]interface{}{}, true, nil})
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	tx, err := DecodeTxHex(hex.EncodeToString(data))
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	hash := blake2b.Sum256(body)
+	if got, want := tx.ID, hex.EncodeToString(hash[:]); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := tx.Body.Inputs, []TxIn{{TxHash: hex.EncodeToString(txHash), Index: 1}}; len(got) != 1 || got[0] != want[0] {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := len(tx.Body.Outputs), 4; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := tx.Body.Outputs[0].Address, "addr1v"; !strings.HasPrefix(got, want) {
+		t.Fatalf("got %v; want prefix %v", got, want)
+	}
+	if got, want := tx.Body.Outputs[0].Value.Coins.Int64(), int64(2000000); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	assetID := AssetID(hex.EncodeToString(policy) + "." + hex.EncodeToString([]byte("coin")))
+	if got, want := tx.Body.Outputs[1].Value.Assets[assetID].Int64(), int64(10); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := tx.Body.Outputs[1].Datum, hex.EncodeToString(datum); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := tx.Body.Outputs[1].DatumHash, hex.EncodeToString(datum); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := tx.Body.Outputs[2].Datum, "01"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := tx.Body.Outputs[3].Value.Assets[assetID].String(), "18446744073709551615"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := tx.Body.Fee.Int64(), int64(170000); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := tx.Body.ValidityInterval, (ValidityInterval{InvalidBefore: 900, InvalidHereafter: 1000}); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := tx.Body.Mint.Assets[assetID].Int64(), int64(-5); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := len(tx.Body.Collaterals), 1; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := tx.Body.TotalCollateral.Int64(), int64(500000); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := tx.Body.References, []TxIn{{TxHash: hex.EncodeToString(txHash), Index: 2}}; len(got) != 1 || got[0] != want[0] {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := string(tx.Body.Network), `"mainnet"`; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	for address, amount := range tx.Body.Withdrawals {
+		if !strings.HasPrefix(address, "stake1") {
+			t.Fatalf("got %v; want stake1 prefix", address)
+		}
+		if got, want := amount, int64(42); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	}
+}
+
+func TestDecodeTx_Malformed(t *testing.T) {
+	missingFee, _ := cbor.Marshal([]interface{}{map[uint64]interface{}{0: []interface{}{}, 1: []interface{}{}}, map[uint64]interface{}{}, nil})
+	shortHash, _ := cbor.Marshal([]interface{}{map[uint64]interface{}{0: []interface{}{[]interface{}{[]byte{1}, 0}}, 1: []interface{}{}, 2: 0}, map[uint64]interface{}{}, nil})
+
+	testCases := map[string]string{
+		"not hex":     "blah",
+		"not cbor":    "ff",
+		"not array":   "01",
+		"missing fee": hex.EncodeToString(missingFee),
+		"short hash":  hex.EncodeToString(shortHash),
+	}
+
+	for label, s := range testCases {
+		t.Run(label, func(t *testing.T) {
+			if _, err := DecodeTxHex(s); err == nil {
+				t.Fatalf("got nil; want not nil")
+			}
+		})
+	}
+}
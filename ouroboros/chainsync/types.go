@@ -19,6 +19,8 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"math"
+	"math/big"
 	"regexp"
 	"strconv"
 	"strings"
@@ -106,6 +108,14 @@ type BlockHeader struct {
 	ProtocolVersion map[string]int         `json:"protocolVersion,omitempty" dynamodbav:"protocolVersion,omitempty"`
 	Signature       string                 `json:"signature,omitempty"       dynamodbav:"signature,omitempty"`
 	Slot            uint64                 `json:"slot,omitempty"            dynamodbav:"slot,omitempty"`
+	VrfInput        *VrfInput              `json:"vrfInput,omitempty"        dynamodbav:"vrfInput,omitempty"` // replaces leaderValue and nonce from babbage
+}
+
+// VrfInput holds the single vrf output used for both leader election and
+// nonce generation from babbage
+type VrfInput struct {
+	Output string `json:"output,omitempty" dynamodbav:"output,omitempty"`
+	Proof  string `json:"proof,omitempty"  dynamodbav:"proof,omitempty"`
 }
 
 type IntersectionFound struct {
@@ -343,38 +353,19 @@ type RollForward struct {
 type RollForwardBlock struct {
 	Allegra *Block      `json:"allegra,omitempty" dynamodbav:"allegra,omitempty"`
 	Alonzo  *Block      `json:"alonzo,omitempty"  dynamodbav:"alonzo,omitempty"`
+	Babbage *Block      `json:"babbage,omitempty" dynamodbav:"babbage,omitempty"`
 	Byron   *ByronBlock `json:"byron,omitempty"   dynamodbav:"byron,omitempty"`
+	Conway  *Block      `json:"conway,omitempty"  dynamodbav:"conway,omitempty"`
 	Mary    *Block      `json:"mary,omitempty"    dynamodbav:"mary,omitempty"`
 	Shelley *Block      `json:"shelley,omitempty" dynamodbav:"shelley,omitempty"`
 }
 
 func (r RollForwardBlock) PointStruct() PointStruct {
-	if byron := r.Byron; byron != nil {
-		return PointStruct{
-			BlockNo: byron.Header.BlockHeight,
-			Hash:    byron.Hash,
-			Slot:    byron.Header.Slot,
-		}
-	}
-
-	var block *Block
-	switch {
-	case r.Allegra != nil:
-		block = r.Allegra
-	case r.Alonzo != nil:
-		block = r.Alonzo
-	case r.Mary != nil:
-		block = r.Mary
-	case r.Shelley != nil:
-		block = r.Shelley
-	default:
-		return PointStruct{}
-	}
-
+	info := r.Info()
 	return PointStruct{
-		BlockNo: block.Header.BlockHeight,
-		Hash:    block.HeaderHash,
-		Slot:    block.Header.Slot,
+		BlockNo: info.Height,
+		Hash:    info.Hash,
+		Slot:    info.Slot,
 	}
 }
 
@@ -402,19 +393,26 @@ type Tx struct {
 }
 
 type TxBody struct {
-	Certificates            []json.RawMessage `json:"certificates,omitempty"            dynamodbav:"certificates,omitempty"`
-	Collaterals             []Collateral      `json:"collaterals,omitempty"             dynamodbav:"collaterals,omitempty"`
-	Fee                     num.Int           `json:"fee,omitempty"                     dynamodbav:"fee,omitempty"`
-	Inputs                  []TxIn            `json:"inputs,omitempty"                  dynamodbav:"inputs,omitempty"`
-	Mint                    *Value            `json:"mint,omitempty"                    dynamodbav:"mint,omitempty"`
-	Network                 json.RawMessage   `json:"network,omitempty"                 dynamodbav:"network,omitempty"`
-	Outputs                 TxOuts            `json:"outputs,omitempty"                 dynamodbav:"outputs,omitempty"`
-	RequiredExtraSignatures []string          `json:"requiredExtraSignatures,omitempty" dynamodbav:"requiredExtraSignatures,omitempty"`
-	ScriptIntegrityHash     string            `json:"scriptIntegrityHash,omitempty"     dynamodbav:"scriptIntegrityHash,omitempty"`
-	TimeToLive              int64             `json:"timeToLive,omitempty"              dynamodbav:"timeToLive,omitempty"`
-	Update                  json.RawMessage   `json:"update,omitempty"                  dynamodbav:"update,omitempty"`
-	ValidityInterval        ValidityInterval  `json:"validityInterval"                  dynamodbav:"validityInterval,omitempty"`
-	Withdrawals             map[string]int64  `json:"withdrawals,omitempty"             dynamodbav:"withdrawals,omitempty"`
+	Certificates            []Certificate    `json:"certificates,omitempty"            dynamodbav:"certificates,omitempty"`
+	CollateralReturn        *TxOut           `json:"collateralReturn,omitempty"        dynamodbav:"collateralReturn,omitempty"` // babbage
+	Collaterals             []Collateral     `json:"collaterals,omitempty"             dynamodbav:"collaterals,omitempty"`
+	Donation                *num.Int         `json:"donation,omitempty"                dynamodbav:"donation,omitempty"` // conway
+	Fee                     num.Int          `json:"fee,omitempty"                     dynamodbav:"fee,omitempty"`
+	Inputs                  []TxIn           `json:"inputs,omitempty"                  dynamodbav:"inputs,omitempty"`
+	Mint                    *Value           `json:"mint,omitempty"                    dynamodbav:"mint,omitempty"`
+	Network                 json.RawMessage  `json:"network,omitempty"                 dynamodbav:"network,omitempty"`
+	Outputs                 TxOuts           `json:"outputs,omitempty"                 dynamodbav:"outputs,omitempty"`
+	Proposals               []Proposal       `json:"proposals,omitempty"               dynamodbav:"proposals,omitempty"`  // conway
+	References              []TxIn           `json:"references,omitempty"              dynamodbav:"references,omitempty"` // babbage
+	RequiredExtraSignatures []string         `json:"requiredExtraSignatures,omitempty" dynamodbav:"requiredExtraSignatures,omitempty"`
+	ScriptIntegrityHash     string           `json:"scriptIntegrityHash,omitempty"     dynamodbav:"scriptIntegrityHash,omitempty"`
+	TimeToLive              int64            `json:"timeToLive,omitempty"              dynamodbav:"timeToLive,omitempty"`
+	TotalCollateral         *num.Int         `json:"totalCollateral,omitempty"         dynamodbav:"totalCollateral,omitempty"` // babbage
+	Treasury                *num.Int         `json:"treasury,omitempty"                dynamodbav:"treasury,omitempty"`        // conway
+	Update                  json.RawMessage  `json:"update,omitempty"                  dynamodbav:"update,omitempty"`
+	ValidityInterval        ValidityInterval `json:"validityInterval"                  dynamodbav:"validityInterval,omitempty"`
+	Votes                   []Vote           `json:"votes,omitempty"                   dynamodbav:"votes,omitempty"` // conway
+	Withdrawals             map[string]int64 `json:"withdrawals,omitempty"             dynamodbav:"withdrawals,omitempty"`
 }
 
 type TxID string
@@ -456,10 +454,15 @@ func (t TxIn) TxID() TxID {
 	return NewTxID(t.TxHash, t.Index)
 }
 
+// TxOut holds a transaction output.  In alonzo, Datum holds the datum hash;
+// from babbage, Datum holds the inline datum as cbor hex and DatumHash holds
+// the hash
 type TxOut struct {
-	Address string `json:"address,omitempty" dynamodbav:"address,omitempty"`
-	Datum   string `json:"datum,omitempty"   dynamodbav:"datum,omitempty"`
-	Value   Value  `json:"value,omitempty"   dynamodbav:"value,omitempty"`
+	Address   string          `json:"address,omitempty"   dynamodbav:"address,omitempty"`
+	Datum     string          `json:"datum,omitempty"     dynamodbav:"datum,omitempty"`
+	DatumHash string          `json:"datumHash,omitempty" dynamodbav:"datumHash,omitempty"`
+	Script    json.RawMessage `json:"script,omitempty"    dynamodbav:"script,omitempty"` // reference script
+	Value     Value           `json:"value,omitempty"     dynamodbav:"value,omitempty"`
 }
 
 type TxOuts []TxOut
@@ -475,11 +478,12 @@ func (tt TxOuts) FindByAssetID(assetID AssetID) (TxOut, bool) {
 	return TxOut{}, false
 }
 
+// Witness holds the witnesses of a tx
 type Witness struct {
 	Bootstrap  []json.RawMessage `json:"bootstrap,omitempty"  dynamodbav:"bootstrap,omitempty"`
-	Datums     map[string][]byte `json:"datums,omitempty"     dynamodbav:"datums,omitempty"`
-	Redeemers  json.RawMessage   `json:"redeemers,omitempty"  dynamodbav:"redeemers,omitempty"`
-	Scripts    json.RawMessage   `json:"scripts,omitempty"    dynamodbav:"scripts,omitempty"`
+	Datums     Datums            `json:"datums,omitempty"     dynamodbav:"datums,omitempty"`
+	Redeemers  Redeemers         `json:"redeemers,omitempty"  dynamodbav:"redeemers,omitempty"`
+	Scripts    Scripts           `json:"scripts,omitempty"    dynamodbav:"scripts,omitempty"`
 	Signatures map[string]string `json:"signatures,omitempty" dynamodbav:"signatures,omitempty"`
 }
 
@@ -492,3 +496,45 @@ type Value struct {
 	Coins  num.Int             `json:"coins,omitempty"  dynamodbav:"coins,omitempty"`
 	Assets map[AssetID]num.Int `json:"assets,omitempty" dynamodbav:"assets,omitempty"`
 }
+
+// Ratio holds a rational number encoded as numerator/denominator e.g. "721/10000000"
+type Ratio string
+
+// Rat returns the ratio as a big.Rat
+func (r Ratio) Rat() (*big.Rat, bool) {
+	s := strings.TrimSpace(string(r))
+	if s == "" {
+		return nil, false
+	}
+	return new(big.Rat).SetString(s)
+}
+
+// UnmarshalJSON accepts both the string form, "1/2", and plain numbers e.g. 0.5
+func (r *Ratio) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return fmt.Errorf("failed to unmarshal Ratio: %w", err)
+		}
+		*r = Ratio(s)
+		return nil
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("failed to unmarshal Ratio: %w", err)
+	}
+	*r = Ratio(n)
+	return nil
+}
+
+// Float64 returns the nearest float64 value of the ratio; NaN if the ratio is invalid
+func (r Ratio) Float64() float64 {
+	v, ok := r.Rat()
+	if !ok {
+		return math.NaN()
+	}
+	f, _ := v.Float64()
+	return f
+}
@@ -334,3 +334,45 @@ func TestPoints_Sort(t *testing.T) {
 		})
 	}
 }
+
+func TestRatio_UnmarshalJSON(t *testing.T) {
+	var got []Ratio
+	if err := json.Unmarshal([]byte(`["1/2",0.5,1]`), &got); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	for _, r := range got[:2] {
+		if got, want := r.Float64(), 0.5; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	}
+	if got, want := got[2].Float64(), 1.0; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestRatio(t *testing.T) {
+	tests := map[string]struct {
+		Ratio Ratio
+		Want  float64
+		OK    bool
+	}{
+		"fraction": {Ratio: "721/10000000", Want: 0.0000721, OK: true},
+		"integer":  {Ratio: "1", Want: 1, OK: true},
+		"blank":    {Ratio: "", OK: false},
+		"invalid":  {Ratio: "a/b", OK: false},
+	}
+
+	for label, tc := range tests {
+		t.Run(label, func(t *testing.T) {
+			_, ok := tc.Ratio.Rat()
+			if got, want := ok, tc.OK; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+			if ok {
+				if got, want := tc.Ratio.Float64(), tc.Want; got != want {
+					t.Fatalf("got %v; want %v", got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,862 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/savaki/ogmigo/ouroboros/chainsync/num"
+)
+
+// The V6 types encode and decode the ogmios v6 json schema while holding the
+// equivalent v5 type e.g. chainsync.Point(pointV6)
+// https://ogmios.dev/api/v6/
+
+// PointV6 encodes a Point as {"slot": ..., "id": ...}
+type PointV6 Point
+
+func (p PointV6) MarshalJSON() ([]byte, error) {
+	point := Point(p)
+	if ps, ok := point.PointStruct(); ok {
+		return json.Marshal(struct {
+			Slot uint64 `json:"slot"`
+			ID   string `json:"id"`
+		}{Slot: ps.Slot, ID: ps.Hash})
+	}
+	return point.MarshalJSON()
+}
+
+func (p *PointV6) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '"' {
+		var point Point
+		if err := point.UnmarshalJSON(data); err != nil {
+			return err
+		}
+		*p = PointV6(point)
+		return nil
+	}
+
+	var content struct {
+		Slot   uint64 `json:"slot"`
+		ID     string `json:"id"`
+		Height uint64 `json:"height"`
+	}
+	if err := json.Unmarshal(data, &content); err != nil {
+		return fmt.Errorf("failed to unmarshal PointV6, %v: %w", string(data), err)
+	}
+	*p = PointV6(PointStruct{BlockNo: content.Height, Hash: content.ID, Slot: content.Slot}.Point())
+	return nil
+}
+
+// LovelaceV6 decodes either {"ada": {"lovelace": n}} or {"lovelace": n}
+type LovelaceV6 num.Int
+
+func (l LovelaceV6) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]map[string]num.Int{"ada": {"lovelace": num.Int(l)}})
+}
+
+func (l *LovelaceV6) UnmarshalJSON(data []byte) error {
+	var content struct {
+		Ada      *struct{ Lovelace num.Int }
+		Lovelace *num.Int
+	}
+	if err := json.Unmarshal(data, &content); err != nil {
+		return fmt.Errorf("failed to unmarshal LovelaceV6: %w", err)
+	}
+	switch {
+	case content.Ada != nil:
+		*l = LovelaceV6(content.Ada.Lovelace)
+	case content.Lovelace != nil:
+		*l = LovelaceV6(*content.Lovelace)
+	}
+	return nil
+}
+
+func (l *LovelaceV6) ptr() *num.Int {
+	if l == nil {
+		return nil
+	}
+	v := num.Int(*l)
+	return &v
+}
+
+// ValueV6 encodes a Value as {"ada": {"lovelace": n}, "policy": {"asset": n}}
+type ValueV6 Value
+
+func (v ValueV6) MarshalJSON() ([]byte, error) {
+	m := map[string]map[string]num.Int{
+		"ada": {"lovelace": v.Coins},
+	}
+	for id, quantity := range v.Assets {
+		policyID, assetName := id.PolicyID(), id.AssetName()
+		if m[policyID] == nil {
+			m[policyID] = map[string]num.Int{}
+		}
+		m[policyID][assetName] = quantity
+	}
+	return json.Marshal(m)
+}
+
+func (v *ValueV6) UnmarshalJSON(data []byte) error {
+	var m map[string]map[string]num.Int
+	if err := json.Unmarshal(data, &m); err != nil {
+		return fmt.Errorf("failed to unmarshal ValueV6: %w", err)
+	}
+
+	var value Value
+	for policyID, assets := range m {
+		if policyID == "ada" {
+			value.Coins = assets["lovelace"]
+			continue
+		}
+		if value.Assets == nil {
+			value.Assets = map[AssetID]num.Int{}
+		}
+		for assetName, quantity := range assets {
+			id := policyID
+			if assetName != "" {
+				id += "." + assetName
+			}
+			value.Assets[AssetID(id)] = quantity
+		}
+	}
+	*v = ValueV6(value)
+	return nil
+}
+
+// TxInV6 encodes a TxIn as {"transaction": {"id": ...}, "index": ...}
+type TxInV6 TxIn
+
+type txInV6 struct {
+	Transaction struct {
+		ID string `json:"id"`
+	} `json:"transaction"`
+	Index int `json:"index"`
+}
+
+func (t TxInV6) MarshalJSON() ([]byte, error) {
+	var v txInV6
+	v.Transaction.ID = t.TxHash
+	v.Index = t.Index
+	return json.Marshal(v)
+}
+
+func (t *TxInV6) UnmarshalJSON(data []byte) error {
+	var v txInV6
+	if err := json.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("failed to unmarshal TxInV6: %w", err)
+	}
+	*t = TxInV6{TxHash: v.Transaction.ID, Index: v.Index}
+	return nil
+}
+
+func txInsV6(items []TxInV6) []TxIn {
+	if len(items) == 0 {
+		return nil
+	}
+	txIns := make([]TxIn, 0, len(items))
+	for _, item := range items {
+		txIns = append(txIns, TxIn(item))
+	}
+	return txIns
+}
+
+// TxOutV6 encodes a TxOut using the v6 value encoding
+type TxOutV6 TxOut
+
+type txOutV6 struct {
+	Address   string          `json:"address"`
+	Value     ValueV6         `json:"value"`
+	DatumHash string          `json:"datumHash,omitempty"`
+	Datum     string          `json:"datum,omitempty"`
+	Script    json.RawMessage `json:"script,omitempty"`
+}
+
+func (t TxOutV6) MarshalJSON() ([]byte, error) {
+	return json.Marshal(txOutV6{
+		Address:   t.Address,
+		Value:     ValueV6(t.Value),
+		DatumHash: t.DatumHash,
+		Datum:     t.Datum,
+		Script:    t.Script,
+	})
+}
+
+func (t *TxOutV6) UnmarshalJSON(data []byte) error {
+	var v txOutV6
+	if err := json.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("failed to unmarshal TxOutV6: %w", err)
+	}
+	*t = TxOutV6{
+		Address:   v.Address,
+		Datum:     v.Datum,
+		DatumHash: v.DatumHash,
+		Script:    v.Script,
+		Value:     Value(v.Value),
+	}
+	return nil
+}
+
+type v6Anchor struct {
+	URL  string `json:"url"`
+	Hash string `json:"hash"`
+}
+
+func (a *v6Anchor) anchor() *Anchor {
+	if a == nil {
+		return nil
+	}
+	return &Anchor{URL: a.URL, Hash: a.Hash}
+}
+
+// firstAnchor returns the first anchor provided; ogmios has encoded anchors as
+// both anchor and metadata
+func firstAnchor(anchors ...*v6Anchor) *Anchor {
+	for _, a := range anchors {
+		if a != nil {
+			return a.anchor()
+		}
+	}
+	return nil
+}
+
+type v6GovernanceActionID struct {
+	Transaction struct{ ID string } `json:"transaction"`
+	Index       int                 `json:"index"`
+}
+
+func (g *v6GovernanceActionID) id() *GovernanceActionID {
+	if g == nil {
+		return nil
+	}
+	return &GovernanceActionID{TxID: g.Transaction.ID, Index: g.Index}
+}
+
+type v6Vote struct {
+	Issuer   Voter                `json:"issuer"`
+	Proposal v6GovernanceActionID `json:"proposal"`
+	Vote     string               `json:"vote"`
+	Anchor   *v6Anchor            `json:"anchor"`
+	Metadata *v6Anchor            `json:"metadata"`
+}
+
+// v6GovernanceActionTypes maps v6 action types to their chainsync names
+var v6GovernanceActionTypes = map[string]string{
+	"protocolParametersUpdate": "parameterChange",
+	"constitutionalCommittee":  "updateCommittee",
+	"constitution":             "newConstitution",
+}
+
+type v6Proposal struct {
+	Deposit       LovelaceV6 `json:"deposit"`
+	ReturnAccount string     `json:"returnAccount"`
+	Metadata      v6Anchor   `json:"metadata"`
+	Action        struct {
+		Type         string                `json:"type"`
+		Ancestor     *v6GovernanceActionID `json:"ancestor"`
+		Parameters   json.RawMessage       `json:"parameters"`
+		Version      *ProtocolVersion      `json:"version"`
+		Withdrawals  map[string]LovelaceV6 `json:"withdrawals"`
+		Members      json.RawMessage       `json:"members"`
+		Quorum       string                `json:"quorum"`
+		Constitution json.RawMessage       `json:"constitution"`
+	} `json:"action"`
+}
+
+func (p v6Proposal) proposal() Proposal {
+	action := GovernanceAction{
+		Type:         p.Action.Type,
+		Ancestor:     p.Action.Ancestor.id(),
+		Parameters:   p.Action.Parameters,
+		Version:      p.Action.Version,
+		Members:      p.Action.Members,
+		Quorum:       p.Action.Quorum,
+		Constitution: p.Action.Constitution,
+	}
+	if v, ok := v6GovernanceActionTypes[action.Type]; ok {
+		action.Type = v
+	}
+	for account, amount := range p.Action.Withdrawals {
+		if action.Withdrawals == nil {
+			action.Withdrawals = map[string]num.Int{}
+		}
+		action.Withdrawals[account] = num.Int(amount)
+	}
+
+	return Proposal{
+		Deposit:       num.Int(p.Deposit),
+		ReturnAccount: p.ReturnAccount,
+		Anchor:        *p.Metadata.anchor(),
+		Action:        action,
+	}
+}
+
+// TxV6 decodes the ogmios v6 transaction encoding
+type TxV6 Tx
+
+func (t *TxV6) UnmarshalJSON(data []byte) error {
+	var v v6Tx
+	if err := json.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("failed to unmarshal TxV6: %w", err)
+	}
+	tx, err := v.tx()
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal TxV6, %v: %w", v.ID, err)
+	}
+	*t = TxV6(tx)
+	return nil
+}
+
+type v6Tx struct {
+	ID                       string      `json:"id"`
+	Inputs                   []TxInV6    `json:"inputs"`
+	References               []TxInV6    `json:"references"`
+	Collaterals              []TxInV6    `json:"collaterals"`
+	CollateralReturn         *TxOutV6    `json:"collateralReturn"`
+	TotalCollateral          *LovelaceV6 `json:"totalCollateral"`
+	Outputs                  []TxOutV6   `json:"outputs"`
+	Fee                      LovelaceV6  `json:"fee"`
+	ValidityInterval         struct{ InvalidBefore, InvalidAfter uint64 }
+	Mint                     *ValueV6              `json:"mint"`
+	Network                  json.RawMessage       `json:"network"`
+	ScriptIntegrityHash      string                `json:"scriptIntegrityHash"`
+	RequiredExtraSignatories []string              `json:"requiredExtraSignatories"`
+	Withdrawals              map[string]LovelaceV6 `json:"withdrawals"`
+	Certificates             []json.RawMessage     `json:"certificates"`
+	Metadata                 json.RawMessage       `json:"metadata"`
+	Signatories              []struct {
+		Key       string `json:"key"`
+		Signature string `json:"signature"`
+	} `json:"signatories"`
+	Datums    map[string]string   `json:"datums"`
+	Scripts   map[string]v6Script `json:"scripts"`
+	Redeemers []v6Redeemer        `json:"redeemers"`
+	Votes     []v6Vote            `json:"votes"`
+	Proposals []v6Proposal        `json:"proposals"`
+	Treasury  *struct {
+		Value    *LovelaceV6 `json:"value"`
+		Donation *LovelaceV6 `json:"donation"`
+	} `json:"treasury"`
+}
+
+func (t v6Tx) tx() (Tx, error) {
+	body := TxBody{
+		Fee:                     num.Int(t.Fee),
+		Inputs:                  txInsV6(t.Inputs),
+		Network:                 t.Network,
+		References:              txInsV6(t.References),
+		RequiredExtraSignatures: t.RequiredExtraSignatories,
+		ScriptIntegrityHash:     t.ScriptIntegrityHash,
+		TotalCollateral:         t.TotalCollateral.ptr(),
+		ValidityInterval: ValidityInterval{
+			InvalidBefore:    t.ValidityInterval.InvalidBefore,
+			InvalidHereafter: t.ValidityInterval.InvalidAfter,
+		},
+	}
+	for _, in := range t.Collaterals {
+		body.Collaterals = append(body.Collaterals, Collateral{TxId: in.TxHash, Index: in.Index})
+	}
+	if t.CollateralReturn != nil {
+		txOut := TxOut(*t.CollateralReturn)
+		body.CollateralReturn = &txOut
+	}
+	for _, txOut := range t.Outputs {
+		body.Outputs = append(body.Outputs, TxOut(txOut))
+	}
+	if t.Mint != nil {
+		mint := Value(*t.Mint)
+		body.Mint = &mint
+	}
+	for account, amount := range t.Withdrawals {
+		if body.Withdrawals == nil {
+			body.Withdrawals = map[string]int64{}
+		}
+		body.Withdrawals[account] = num.Int(amount).Int64()
+	}
+	for _, raw := range t.Certificates {
+		certs, err := certificateV6(raw)
+		if err != nil {
+			return Tx{}, err
+		}
+		body.Certificates = append(body.Certificates, certs...)
+	}
+	for _, vote := range t.Votes {
+		body.Votes = append(body.Votes, Vote{
+			Issuer:   vote.Issuer,
+			Proposal: *vote.Proposal.id(),
+			Vote:     vote.Vote,
+			Anchor:   firstAnchor(vote.Anchor, vote.Metadata),
+		})
+	}
+	for _, proposal := range t.Proposals {
+		body.Proposals = append(body.Proposals, proposal.proposal())
+	}
+	if treasury := t.Treasury; treasury != nil {
+		body.Treasury = treasury.Value.ptr()
+		body.Donation = treasury.Donation.ptr()
+	}
+
+	witness := Witness{
+		Datums: t.Datums,
+	}
+	for _, redeemer := range t.Redeemers {
+		witness.Redeemers = append(witness.Redeemers, redeemer.redeemer())
+	}
+	witness.Redeemers.sort()
+	for hash, script := range t.Scripts {
+		if witness.Scripts == nil {
+			witness.Scripts = Scripts{}
+		}
+		witness.Scripts[hash] = script.script()
+	}
+	for _, signatory := range t.Signatories {
+		if witness.Signatures == nil {
+			witness.Signatures = map[string]string{}
+		}
+		witness.Signatures[signatory.Key] = signatory.Signature
+	}
+
+	return Tx{
+		ID:       t.ID,
+		Body:     body,
+		Metadata: t.Metadata,
+		Witness:  witness,
+	}, nil
+}
+
+// v6 redeemer purposes that differ from v5
+var v6RedeemerPurposes = map[string]string{
+	"publish":  RedeemerCertificate,
+	"withdraw": RedeemerWithdrawal,
+}
+
+type v6Redeemer struct {
+	Validator struct {
+		Purpose string `json:"purpose"`
+		Index   int    `json:"index"`
+	} `json:"validator"`
+	Redeemer       string `json:"redeemer"`
+	ExecutionUnits struct {
+		Memory uint64 `json:"memory"`
+		CPU    uint64 `json:"cpu"`
+	} `json:"executionUnits"`
+}
+
+func (r v6Redeemer) redeemer() Redeemer {
+	purpose := r.Validator.Purpose
+	if v, ok := v6RedeemerPurposes[purpose]; ok {
+		purpose = v
+	}
+	return Redeemer{
+		Purpose: purpose,
+		Index:   r.Validator.Index,
+		Data:    r.Redeemer,
+		ExecutionUnits: ExecutionUnits{
+			Memory: r.ExecutionUnits.Memory,
+			Steps:  r.ExecutionUnits.CPU,
+		},
+	}
+}
+
+type v6Script struct {
+	Language string          `json:"language"`
+	JSON     *NativeScriptV6 `json:"json"`
+	CBOR     string          `json:"cbor"`
+}
+
+func (s v6Script) script() Script {
+	script := Script{
+		Language: s.Language,
+		CBOR:     s.CBOR,
+	}
+	if s.JSON != nil {
+		native := NativeScript(*s.JSON)
+		script.Native, script.CBOR = &native, ""
+	}
+	return script
+}
+
+// NativeScriptV6 encodes a NativeScript as {"clause": ..., "from": ...}
+type NativeScriptV6 NativeScript
+
+func (n NativeScriptV6) MarshalJSON() ([]byte, error) {
+	type content struct {
+		Clause  string      `json:"clause"`
+		From    interface{} `json:"from,omitempty"`
+		AtLeast int         `json:"atLeast,omitempty"`
+		Slot    uint64      `json:"slot,omitempty"`
+	}
+
+	var scripts []NativeScriptV6
+	for _, script := range n.Scripts {
+		scripts = append(scripts, NativeScriptV6(script))
+	}
+	if scripts == nil {
+		scripts = []NativeScriptV6{}
+	}
+
+	switch n.Type {
+	case NativeScriptSignature:
+		return json.Marshal(content{Clause: "signature", From: n.KeyHash})
+	case NativeScriptAll, NativeScriptAny:
+		return json.Marshal(content{Clause: n.Type, From: scripts})
+	case NativeScriptAtLeast:
+		return json.Marshal(content{Clause: "some", From: scripts, AtLeast: n.Required})
+	case NativeScriptExpiresAt:
+		return json.Marshal(content{Clause: "before", Slot: n.Slot})
+	case NativeScriptStartsAt:
+		return json.Marshal(content{Clause: "after", Slot: n.Slot})
+	default:
+		return nil, fmt.Errorf("unable to marshal NativeScriptV6: unknown type, %v", n.Type)
+	}
+}
+
+func (n *NativeScriptV6) UnmarshalJSON(data []byte) error {
+	var content struct {
+		Clause  string          `json:"clause"`
+		From    json.RawMessage `json:"from"`
+		AtLeast int             `json:"atLeast"`
+		Slot    uint64          `json:"slot"`
+	}
+	if err := json.Unmarshal(data, &content); err != nil {
+		return fmt.Errorf("failed to unmarshal NativeScriptV6, %v: %w", string(data), err)
+	}
+
+	var script NativeScript
+	switch content.Clause {
+	case "signature":
+		script.Type = NativeScriptSignature
+		if err := json.Unmarshal(content.From, &script.KeyHash); err != nil {
+			return fmt.Errorf("failed to unmarshal NativeScriptV6, %v: %w", string(data), err)
+		}
+		*n = NativeScriptV6(script)
+		return nil
+	case "all", "any":
+		script.Type = content.Clause
+	case "some":
+		script.Type, script.Required = NativeScriptAtLeast, content.AtLeast
+	case "before":
+		*n = NativeScriptV6{Type: NativeScriptExpiresAt, Slot: content.Slot}
+		return nil
+	case "after":
+		*n = NativeScriptV6{Type: NativeScriptStartsAt, Slot: content.Slot}
+		return nil
+	default:
+		return fmt.Errorf("failed to unmarshal NativeScriptV6, %v: unknown clause", string(data))
+	}
+
+	var scripts []NativeScriptV6
+	if err := json.Unmarshal(content.From, &scripts); err != nil {
+		return fmt.Errorf("failed to unmarshal NativeScriptV6, %v: %w", string(data), err)
+	}
+	for _, item := range scripts {
+		script.Scripts = append(script.Scripts, NativeScript(item))
+	}
+	*n = NativeScriptV6(script)
+	return nil
+}
+
+type v6Relay struct {
+	Type     string `json:"type"`
+	IPv4     string `json:"ipv4"`
+	IPv6     string `json:"ipv6"`
+	Hostname string `json:"hostname"`
+	Port     uint16 `json:"port"`
+}
+
+type v6StakePool struct {
+	ID                     string        `json:"id"`
+	VrfVerificationKeyHash string        `json:"vrfVerificationKeyHash"`
+	Pledge                 LovelaceV6    `json:"pledge"`
+	Cost                   LovelaceV6    `json:"cost"`
+	Margin                 Ratio         `json:"margin"`
+	RewardAccount          string        `json:"rewardAccount"`
+	Owners                 []string      `json:"owners"`
+	Relays                 []v6Relay     `json:"relays"`
+	Metadata               *PoolMetadata `json:"metadata"`
+	RetirementEpoch        uint64        `json:"retirementEpoch"`
+}
+
+func (p v6StakePool) parameters() *PoolParameters {
+	params := &PoolParameters{
+		ID:            p.ID,
+		VRF:           p.VrfVerificationKeyHash,
+		Pledge:        num.Int(p.Pledge),
+		Cost:          num.Int(p.Cost),
+		Margin:        p.Margin,
+		RewardAccount: p.RewardAccount,
+		Owners:        p.Owners,
+		Metadata:      p.Metadata,
+	}
+	for _, relay := range p.Relays {
+		params.Relays = append(params.Relays, Relay{
+			IPv4:     relay.IPv4,
+			IPv6:     relay.IPv6,
+			Hostname: relay.Hostname,
+			Port:     relay.Port,
+		})
+	}
+	return params
+}
+
+// certificateV6 converts a v6 certificate to the equivalent certificates.  A
+// conway stake delegation to both a pool and a delegate representative is
+// split into a stake delegation and a vote delegation
+func certificateV6(data json.RawMessage) ([]Certificate, error) {
+	var content struct {
+		Type                   string       `json:"type"`
+		Credential             string       `json:"credential"`
+		StakePool              *v6StakePool `json:"stakePool"`
+		DelegateRepresentative *DRep        `json:"delegateRepresentative"`
+		Deposit                *LovelaceV6  `json:"deposit"`
+		Anchor                 *v6Anchor    `json:"anchor"`
+		Metadata               *v6Anchor    `json:"metadata"`
+		Delegate               struct {
+			ID                     string `json:"id"`
+			VrfVerificationKeyHash string `json:"vrfVerificationKeyHash"`
+		} `json:"delegate"`
+		Issuer struct {
+			ID string `json:"id"`
+		} `json:"issuer"`
+	}
+	if err := json.Unmarshal(data, &content); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal certificate: %w", err)
+	}
+
+	drep := func(certType string) Certificate {
+		cert := DRepCertificate{
+			Type:       certType,
+			Credential: content.Credential,
+			Deposit:    content.Deposit.ptr(),
+			Anchor:     firstAnchor(content.Anchor, content.Metadata),
+		}
+		if content.DelegateRepresentative != nil {
+			cert.DRep = *content.DelegateRepresentative
+		}
+		return Certificate{DRep: &cert}
+	}
+
+	switch content.Type {
+	case "stakeCredentialRegistration":
+		return []Certificate{{StakeKeyRegistration: content.Credential}}, nil
+	case "stakeCredentialDeregistration":
+		return []Certificate{{StakeKeyDeregistration: content.Credential}}, nil
+	case "stakeDelegation":
+		var certs []Certificate
+		if pool := content.StakePool; pool != nil {
+			certs = append(certs, Certificate{
+				StakeDelegation: &StakeDelegationCertificate{
+					Delegator: content.Credential,
+					Delegatee: pool.ID,
+				},
+			})
+		}
+		if content.DelegateRepresentative != nil {
+			certs = append(certs, drep(DRepVoteDelegation))
+		}
+		return certs, nil
+	case "stakePoolRegistration":
+		if content.StakePool == nil {
+			return nil, fmt.Errorf("failed to decode %v certificate: missing stakePool", content.Type)
+		}
+		return []Certificate{{PoolRegistration: content.StakePool.parameters()}}, nil
+	case "stakePoolRetirement":
+		if content.StakePool == nil {
+			return nil, fmt.Errorf("failed to decode %v certificate: missing stakePool", content.Type)
+		}
+		return []Certificate{{
+			PoolRetirement: &PoolRetirementCertificate{
+				PoolID:          content.StakePool.ID,
+				RetirementEpoch: content.StakePool.RetirementEpoch,
+			},
+		}}, nil
+	case "genesisDelegation":
+		return []Certificate{{
+			GenesisDelegation: &GenesisDelegationCertificate{
+				VerificationKeyHash:    content.Issuer.ID,
+				DelegateKeyHash:        content.Delegate.ID,
+				VRFVerificationKeyHash: content.Delegate.VrfVerificationKeyHash,
+			},
+		}}, nil
+	case DRepRegistration, DRepUpdate, DRepRetirement:
+		return []Certificate{drep(content.Type)}, nil
+	default:
+		return []Certificate{{Unknown: data}}, nil
+	}
+}
+
+type v6Block struct {
+	Type         string `json:"type"`
+	Era          string `json:"era"`
+	ID           string `json:"id"`
+	Ancestor     string `json:"ancestor"`
+	Height       uint64 `json:"height"`
+	Slot         uint64 `json:"slot"`
+	Size         struct{ Bytes uint64 }
+	Transactions []TxV6 `json:"transactions"`
+	Issuer       struct {
+		VerificationKey    string `json:"verificationKey"`
+		VrfVerificationKey string `json:"vrfVerificationKey"`
+	} `json:"issuer"`
+	Protocol struct {
+		Version ProtocolVersion `json:"version"`
+	} `json:"protocol"`
+}
+
+func (b v6Block) block() (RollForwardBlock, error) {
+	if b.Era == "byron" {
+		byron := &ByronBlock{
+			Hash: b.ID,
+			Header: ByronHeader{
+				BlockHeight:     b.Height,
+				PrevHash:        b.Ancestor,
+				ProtocolVersion: b.Protocol.Version,
+				Slot:            b.Slot,
+			},
+		}
+		for _, tx := range b.Transactions {
+			byron.Body.TxPayload = append(byron.Body.TxPayload, ByronTxPayload{
+				ID:   tx.ID,
+				Body: ByronTxBody{Inputs: tx.Body.Inputs, Outputs: tx.Body.Outputs},
+			})
+		}
+		return RollForwardBlock{Byron: byron}, nil
+	}
+
+	block := &Block{
+		Header: BlockHeader{
+			BlockHash:   b.ID,
+			BlockHeight: b.Height,
+			BlockSize:   b.Size.Bytes,
+			IssuerVK:    b.Issuer.VerificationKey,
+			IssuerVrf:   b.Issuer.VrfVerificationKey,
+			PrevHash:    b.Ancestor,
+			ProtocolVersion: map[string]int{
+				"major": int(b.Protocol.Version.Major),
+				"minor": int(b.Protocol.Version.Minor),
+			},
+			Slot: b.Slot,
+		},
+		HeaderHash: b.ID,
+	}
+	for _, tx := range b.Transactions {
+		block.Body = append(block.Body, Tx(tx))
+	}
+
+	switch b.Era {
+	case "shelley":
+		return RollForwardBlock{Shelley: block}, nil
+	case "allegra":
+		return RollForwardBlock{Allegra: block}, nil
+	case "mary":
+		return RollForwardBlock{Mary: block}, nil
+	case "alonzo":
+		return RollForwardBlock{Alonzo: block}, nil
+	case "babbage":
+		return RollForwardBlock{Babbage: block}, nil
+	case "conway":
+		return RollForwardBlock{Conway: block}, nil
+	default:
+		return RollForwardBlock{}, fmt.Errorf("unknown era, %v", b.Era)
+	}
+}
+
+// DecodeResponseV6 converts an ogmios v6 findIntersection or nextBlock
+// response into the equivalent v5 Response
+func DecodeResponseV6(data []byte) (*Response, error) {
+	var content struct {
+		Method string          `json:"method"`
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Code    int             `json:"code"`
+			Message string          `json:"message"`
+			Data    json.RawMessage `json:"data"`
+		} `json:"error"`
+		ID json.RawMessage `json:"id"`
+	}
+	if err := json.Unmarshal(data, &content); err != nil {
+		return nil, fmt.Errorf("failed to decode v6 response: %w", err)
+	}
+
+	response := &Response{
+		Type:        "jsonwsp/response",
+		Version:     "1.0",
+		ServiceName: "ogmios",
+		Reflection:  content.ID,
+		Result:      &Result{},
+	}
+
+	switch content.Method {
+	case "findIntersection":
+		response.MethodName = "FindIntersect"
+		if e := content.Error; e != nil {
+			var data struct{ Tip PointV6 }
+			if err := json.Unmarshal(e.Data, &data); err != nil {
+				return nil, fmt.Errorf("failed to decode v6 findIntersection error, %v: %v", e.Code, e.Message)
+			}
+			response.Result.IntersectionNotFound = &IntersectionNotFound{Tip: Point(data.Tip)}
+			return response, nil
+		}
+
+		var result struct {
+			Intersection PointV6
+			Tip          PointV6
+		}
+		if err := json.Unmarshal(content.Result, &result); err != nil {
+			return nil, fmt.Errorf("failed to decode v6 findIntersection: %w", err)
+		}
+		response.Result.IntersectionFound = &IntersectionFound{Point: Point(result.Intersection), Tip: Point(result.Tip)}
+
+	case "nextBlock":
+		response.MethodName = "RequestNext"
+		if e := content.Error; e != nil {
+			return nil, fmt.Errorf("v6 nextBlock failed, %v: %v", e.Code, e.Message)
+		}
+
+		var result struct {
+			Direction string
+			Block     *v6Block
+			Point     PointV6
+			Tip       PointV6
+		}
+		if err := json.Unmarshal(content.Result, &result); err != nil {
+			return nil, fmt.Errorf("failed to decode v6 nextBlock: %w", err)
+		}
+
+		switch result.Direction {
+		case "forward":
+			if result.Block == nil {
+				return nil, fmt.Errorf("failed to decode v6 nextBlock: missing block")
+			}
+			block, err := result.Block.block()
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode v6 nextBlock: %w", err)
+			}
+			response.Result.RollForward = &RollForward{Block: block, Tip: Point(result.Tip)}
+		case "backward":
+			response.Result.RollBackward = &RollBackward{Point: Point(result.Point), Tip: Point(result.Tip)}
+		default:
+			return nil, fmt.Errorf("failed to decode v6 nextBlock: unknown direction, %v", result.Direction)
+		}
+
+	default:
+		return nil, fmt.Errorf("unexpected v6 chain sync method, %v", content.Method)
+	}
+
+	return response, nil
+}
@@ -0,0 +1,219 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/savaki/ogmigo/ouroboros/chainsync/num"
+)
+
+func TestDecodeResponseV6(t *testing.T) {
+	t.Run("nextBlock forward", func(t *testing.T) {
+		data, err := ioutil.ReadFile(filepath.Join("testdata", "v6", "nextBlock.json"))
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+
+		response, err := DecodeResponseV6(data)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := string(response.Reflection), `"1"`; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+
+		rollForward := response.Result.RollForward
+		if rollForward == nil || rollForward.Block.Conway == nil {
+			t.Fatalf("got nil; want conway RollForward")
+		}
+		if got, want := rollForward.Block.PointStruct(), (PointStruct{BlockNo: 2000001, Hash: "d1c0b2e7a1f1c43a3b0d3e2f4e5a6b7c8d9e0f1a2b3c4d5e6f708192a3b4c5d6", Slot: 60000000}); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if tip, _ := rollForward.Tip.PointStruct(); tip.BlockNo != 2000002 {
+			t.Fatalf("got %v; want 2000002", tip.BlockNo)
+		}
+
+		tx := rollForward.Block.Conway.Body[0]
+		if got, want := tx.Body.Fee.Int64(), int64(180000); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := tx.Body.Inputs, []TxIn{{TxHash: "b1c2d3e4f5061728394a5b6c7d8e9f00112233445566778899aabbccddeeff00", Index: 1}}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %#v; want %#v", got, want)
+		}
+		if got, want := tx.Body.ValidityInterval.InvalidHereafter, uint64(60001000); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := tx.Body.Withdrawals["stake_test1uqfu74w3wh4gfzu8m6e7j987h4lq9r3t7ef5gaw497uu85qsqfy27"], int64(42); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := tx.Body.Donation.Int64(), int64(1000000); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+
+		output := tx.Body.Outputs[0]
+		if got, want := output.Value.Coins.Int64(), int64(5000000); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := output.Value.Assets[AssetID("0c8e4a6d3b2c1f0e9d8c7b6a5f4e3d2c1b0a9f8e7d6c5b4a39281706.74657374")], num.Int64(10); got.Int64() != want.Int64() {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+
+		certs := tx.Body.DRepCertificates()
+		if got, want := len(certs), 2; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := certs[0].Anchor.URL, "https://example.com/drep.json"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := certs[1].Type, DRepVoteDelegation; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+
+		if got, want := tx.Body.Votes[0].Proposal.TxID, "d1c2d3e4f5061728394a5b6c7d8e9f00112233445566778899aabbccddeeff00"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := tx.Body.Proposals[0].Anchor.URL, "https://example.com/proposal.json"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := tx.Witness.Signatures["9a8b7c6d5e4f30211a2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6f7081"], "abcdef"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("nextBlock backward", func(t *testing.T) {
+		data := `{"jsonrpc":"2.0","method":"nextBlock","result":{"direction":"backward","point":{"slot":10,"id":"abc"},"tip":{"slot":20,"id":"def","height":3}}}`
+		response, err := DecodeResponseV6([]byte(data))
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		rollBackward := response.Result.RollBackward
+		if rollBackward == nil {
+			t.Fatalf("got nil; want RollBackward")
+		}
+		if ps, _ := rollBackward.Point.PointStruct(); ps.Slot != 10 || ps.Hash != "abc" {
+			t.Fatalf("got %v; want slot 10, hash abc", ps)
+		}
+	})
+
+	t.Run("nextBlock origin", func(t *testing.T) {
+		data := `{"jsonrpc":"2.0","method":"nextBlock","result":{"direction":"backward","point":"origin","tip":"origin"}}`
+		response, err := DecodeResponseV6([]byte(data))
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := response.Result.RollBackward.Point, Origin; !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("intersection found", func(t *testing.T) {
+		data := `{"jsonrpc":"2.0","method":"findIntersection","result":{"intersection":{"slot":10,"id":"abc"},"tip":{"slot":20,"id":"def","height":3}},"id":"INIT"}`
+		response, err := DecodeResponseV6([]byte(data))
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if response.Result.IntersectionFound == nil {
+			t.Fatalf("got nil; want IntersectionFound")
+		}
+	})
+
+	t.Run("intersection not found", func(t *testing.T) {
+		data := `{"jsonrpc":"2.0","method":"findIntersection","error":{"code":1000,"message":"No intersection found.","data":{"tip":{"slot":20,"id":"def","height":3}}},"id":"INIT"}`
+		response, err := DecodeResponseV6([]byte(data))
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		notFound := response.Result.IntersectionNotFound
+		if notFound == nil {
+			t.Fatalf("got nil; want IntersectionNotFound")
+		}
+		if ps, _ := notFound.Tip.PointStruct(); ps.BlockNo != 3 {
+			t.Fatalf("got %v; want 3", ps.BlockNo)
+		}
+	})
+
+	t.Run("v5 round trip", func(t *testing.T) {
+		data := `{"jsonrpc":"2.0","method":"nextBlock","result":{"direction":"backward","point":{"slot":10,"id":"abc"},"tip":{"slot":20,"id":"def","height":3}}}`
+		response, err := DecodeResponseV6([]byte(data))
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		v5, err := json.Marshal(response)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		var got Response
+		if err := json.Unmarshal(v5, &got); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if !reflect.DeepEqual(got.Result, response.Result) {
+			t.Fatalf("got %#v; want %#v", got.Result, response.Result)
+		}
+	})
+}
+
+func TestPointV6(t *testing.T) {
+	want := PointStruct{Hash: "abc", Slot: 10}.Point()
+	data, err := json.Marshal(PointV6(want))
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := string(data), `{"slot":10,"id":"abc"}`; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	data, err = json.Marshal(PointV6(Origin))
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := string(data), `"origin"`; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestValueV6(t *testing.T) {
+	data := `{"ada":{"lovelace":5},"policy":{"":1,"746f6b656e":2}}`
+	var v ValueV6
+	if err := json.Unmarshal([]byte(data), &v); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	value := Value(v)
+	if got, want := value.Coins.Int64(), int64(5); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := value.Assets["policy"].Int64(), int64(1); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := value.Assets["policy.746f6b656e"].Int64(), int64(2); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	var got ValueV6
+	if err := json.Unmarshal(encoded, &got); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if !reflect.DeepEqual(got, v) {
+		t.Fatalf("got %#v; want %#v", got, v)
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"sort"
+
+	"github.com/savaki/ogmigo/ouroboros/chainsync/num"
+)
+
+// Value arithmetic never modifies its operands.  Results are normalized;
+// assets with a zero quantity are removed and Assets is nil if no assets
+// remain.  An asset missing from a Value has a quantity of zero
+
+// Add returns the sum of v and that
+func (v Value) Add(that Value) Value {
+	return combine(v, that, num.Int.Add)
+}
+
+// Sub returns v less that.  Quantities may become negative; see HasNegative
+func (v Value) Sub(that Value) Value {
+	return combine(v, that, num.Int.Sub)
+}
+
+// Neg returns v with every quantity negated
+func (v Value) Neg() Value {
+	return Value{}.Sub(v)
+}
+
+// Equal returns true if v and that hold the same coins and the same nonzero
+// asset quantities
+func (v Value) Equal(that Value) bool {
+	return v.Sub(that).IsZero()
+}
+
+// GreaterOrEqual returns true if v holds at least as many coins and at least
+// as much of every asset as that i.e. v can pay for that
+func (v Value) GreaterOrEqual(that Value) bool {
+	return !v.Sub(that).HasNegative()
+}
+
+// IsZero returns true if v holds no coins and no nonzero asset quantities
+func (v Value) IsZero() bool {
+	if !v.Coins.IsZero() {
+		return false
+	}
+	for _, quantity := range v.Assets {
+		if !quantity.IsZero() {
+			return false
+		}
+	}
+	return true
+}
+
+// HasNegative returns true if the coins or any asset quantity is negative
+func (v Value) HasNegative() bool {
+	if v.Coins.Sign() < 0 {
+		return true
+	}
+	for _, quantity := range v.Assets {
+		if quantity.Sign() < 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Normalize returns a copy of v without zero quantity assets
+func (v Value) Normalize() Value {
+	return v.Add(Value{})
+}
+
+// AssetIDs returns the ids of the nonzero assets in v, sorted
+func (v Value) AssetIDs() []AssetID {
+	var ids []AssetID
+	for id, quantity := range v.Assets {
+		if !quantity.IsZero() {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// PolicyIDs returns the policy ids of the nonzero assets in v, sorted
+func (v Value) PolicyIDs() []string {
+	var policyIDs []string
+	for _, id := range v.AssetIDs() {
+		if policyID := id.PolicyID(); len(policyIDs) == 0 || policyIDs[len(policyIDs)-1] != policyID {
+			policyIDs = append(policyIDs, policyID)
+		}
+	}
+	return policyIDs
+}
+
+// AssetsByPolicy returns the nonzero asset quantities of v keyed by policy
+// id and then by hex encoded asset name
+func (v Value) AssetsByPolicy() map[string]map[string]num.Int {
+	policies := map[string]map[string]num.Int{}
+	for id, quantity := range v.Assets {
+		if quantity.IsZero() {
+			continue
+		}
+		policyID := id.PolicyID()
+		assets, ok := policies[policyID]
+		if !ok {
+			assets = map[string]num.Int{}
+			policies[policyID] = assets
+		}
+		assets[id.AssetName()] = quantity
+	}
+	return policies
+}
+
+// PolicyAssets returns the nonzero quantities of the assets in v minted by
+// the given policy
+func (v Value) PolicyAssets(policyID string) map[AssetID]num.Int {
+	assets := map[AssetID]num.Int{}
+	for id, quantity := range v.Assets {
+		if id.PolicyID() == policyID && !quantity.IsZero() {
+			assets[id] = quantity
+		}
+	}
+	return assets
+}
+
+func combine(a, b Value, fn func(num.Int, num.Int) num.Int) Value {
+	var assets map[AssetID]num.Int
+	set := func(id AssetID, quantity num.Int) {
+		if quantity.IsZero() {
+			return
+		}
+		if assets == nil {
+			assets = map[AssetID]num.Int{}
+		}
+		assets[id] = quantity
+	}
+
+	for id, quantity := range a.Assets {
+		set(id, fn(quantity, b.Assets[id]))
+	}
+	for id, quantity := range b.Assets {
+		if _, ok := a.Assets[id]; !ok {
+			set(id, fn(num.Int{}, quantity))
+		}
+	}
+
+	return Value{
+		Coins:  fn(a.Coins, b.Coins),
+		Assets: assets,
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/savaki/ogmigo/ouroboros/chainsync/num"
+)
+
+const (
+	testPolicyA = "a0028f350aaabe0545fdcb56b039bfb08e4bb4d8c4d7c3c7d481c235"
+	testPolicyB = "f0ff48bbb7bbe9d59a40f1ce90e9e9d0ff5002ec48f232b49ca0fb9a"
+)
+
+var (
+	testAssetA1 = AssetID(testPolicyA + ".484f534b59")
+	testAssetA2 = AssetID(testPolicyA + ".534e454b")
+	testAssetB  = AssetID(testPolicyB)
+)
+
+func TestValue_Arithmetic(t *testing.T) {
+	a := Value{
+		Coins: num.Int64(5_000_000),
+		Assets: map[AssetID]num.Int{
+			testAssetA1: num.Int64(10),
+			testAssetA2: num.Int64(3),
+		},
+	}
+	b := Value{
+		Coins: num.Int64(2_000_000),
+		Assets: map[AssetID]num.Int{
+			testAssetA2: num.Int64(3),
+			testAssetB:  num.Int64(1),
+		},
+	}
+
+	sum := a.Add(b)
+	want := Value{
+		Coins: num.Int64(7_000_000),
+		Assets: map[AssetID]num.Int{
+			testAssetA1: num.Int64(10),
+			testAssetA2: num.Int64(6),
+			testAssetB:  num.Int64(1),
+		},
+	}
+	if !sum.Equal(want) {
+		t.Fatalf("got %v; want %v", sum, want)
+	}
+	if !sum.Sub(b).Equal(a) {
+		t.Fatalf("got %v; want %v", sum.Sub(b), a)
+	}
+
+	diff := a.Sub(b)
+	if _, ok := diff.Assets[testAssetA2]; ok {
+		t.Fatalf("got %v; want zero quantity removed", diff.Assets)
+	}
+	if got, want := diff.Assets[testAssetB].Int(), -1; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if !diff.HasNegative() {
+		t.Fatalf("got false; want true")
+	}
+	if !a.Add(diff.Neg()).Equal(b) {
+		t.Fatalf("got %v; want %v", a.Add(diff.Neg()), b)
+	}
+
+	// operands are not modified
+	if got, want := a.Assets[testAssetA2].Int(), 3; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if _, ok := a.Assets[testAssetB]; ok {
+		t.Fatalf("got %v; want operand unchanged", a.Assets)
+	}
+}
+
+func TestValue_Compare(t *testing.T) {
+	a := Value{
+		Coins:  num.Int64(5),
+		Assets: map[AssetID]num.Int{testAssetA1: num.Int64(2)},
+	}
+	withZero := Value{
+		Coins:  num.Int64(5),
+		Assets: map[AssetID]num.Int{testAssetA1: num.Int64(2), testAssetB: num.Int64(0)},
+	}
+
+	if !a.Equal(withZero) {
+		t.Fatalf("got false; want true")
+	}
+	if !a.GreaterOrEqual(withZero) || !withZero.GreaterOrEqual(a) {
+		t.Fatalf("got false; want true")
+	}
+	if a.GreaterOrEqual(Value{Coins: num.Int64(1), Assets: map[AssetID]num.Int{testAssetB: num.Int64(1)}}) {
+		t.Fatalf("got true; want false")
+	}
+	if a.GreaterOrEqual(Value{Coins: num.Int64(6)}) {
+		t.Fatalf("got true; want false")
+	}
+	if !a.GreaterOrEqual(Value{}) {
+		t.Fatalf("got false; want true")
+	}
+
+	if !(Value{}).IsZero() || !(Value{Assets: map[AssetID]num.Int{testAssetB: {}}}).IsZero() {
+		t.Fatalf("got false; want true")
+	}
+	if a.IsZero() || a.HasNegative() {
+		t.Fatalf("got true; want false")
+	}
+}
+
+func TestValue_Assets(t *testing.T) {
+	v := Value{
+		Coins: num.Int64(1),
+		Assets: map[AssetID]num.Int{
+			testAssetB:                   num.Int64(1),
+			testAssetA2:                  num.Int64(3),
+			testAssetA1:                  num.Int64(10),
+			AssetID(testPolicyB + ".00"): num.Int64(0),
+		},
+	}
+
+	if got, want := v.AssetIDs(), []AssetID{testAssetA1, testAssetA2, testAssetB}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := v.PolicyIDs(), []string{testPolicyA, testPolicyB}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	byPolicy := v.AssetsByPolicy()
+	if got, want := len(byPolicy), 2; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := byPolicy[testPolicyA]["484f534b59"].Int(), 10; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := byPolicy[testPolicyB][""].Int(), 1; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	if got, want := len(v.PolicyAssets(testPolicyA)), 2; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := len(v.PolicyAssets(testPolicyB)), 1; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	normalized := v.Normalize()
+	if got, want := len(normalized.Assets), 3; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got := (Value{Coins: num.Int64(1), Assets: map[AssetID]num.Int{testAssetB: {}}}).Normalize(); got.Assets != nil {
+		t.Fatalf("got %v; want nil", got.Assets)
+	}
+}
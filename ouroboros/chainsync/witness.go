@@ -0,0 +1,336 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/savaki/ogmigo/ouroboros/plutusdata"
+)
+
+// Redeemer purposes
+const (
+	RedeemerSpend       = "spend"
+	RedeemerMint        = "mint"
+	RedeemerCertificate = "certificate"
+	RedeemerWithdrawal  = "withdrawal"
+	RedeemerVote        = "vote"    // conway
+	RedeemerPropose     = "propose" // conway
+)
+
+// Script languages
+const (
+	ScriptNative   = "native"
+	ScriptPlutusV1 = "plutus:v1"
+	ScriptPlutusV2 = "plutus:v2"
+	ScriptPlutusV3 = "plutus:v3"
+)
+
+// Native script types
+const (
+	NativeScriptSignature = "signature"
+	NativeScriptAll       = "all"
+	NativeScriptAny       = "any"
+	NativeScriptAtLeast   = "atLeast"
+	NativeScriptExpiresAt = "expiresAt" // valid before slot
+	NativeScriptStartsAt  = "startsAt"  // valid from slot
+)
+
+// ExecutionUnits measure the resources consumed by plutus scripts
+type ExecutionUnits struct {
+	Memory uint64 `json:"memory" dynamodbav:"memory"`
+	Steps  uint64 `json:"steps"  dynamodbav:"steps"`
+}
+
+// Redeemer holds the argument passed to the plutus script validating the
+// item at Index of the kind given by Purpose e.g. the 2nd input for spend:1
+type Redeemer struct {
+	Purpose        string         `json:"purpose"        dynamodbav:"purpose"`
+	Index          int            `json:"index"          dynamodbav:"index"`
+	Data           string         `json:"data"           dynamodbav:"data"` // plutus data cbor hex
+	ExecutionUnits ExecutionUnits `json:"executionUnits" dynamodbav:"executionUnits"`
+}
+
+// PlutusData decodes the redeemer data
+func (r Redeemer) PlutusData() (plutusdata.Data, error) {
+	return plutusdata.DecodeHex(r.Data)
+}
+
+// Redeemers are encoded as an object keyed by purpose:index e.g.
+// {"spend:0": {"redeemer": "d87980", "executionUnits": {...}}}
+type Redeemers []Redeemer
+
+func (r Redeemers) MarshalJSON() ([]byte, error) {
+	type redeemer struct {
+		Redeemer       string         `json:"redeemer"`
+		ExecutionUnits ExecutionUnits `json:"executionUnits"`
+	}
+	m := make(map[string]redeemer, len(r))
+	for _, item := range r {
+		m[item.Purpose+":"+strconv.Itoa(item.Index)] = redeemer{
+			Redeemer:       item.Data,
+			ExecutionUnits: item.ExecutionUnits,
+		}
+	}
+	return json.Marshal(m)
+}
+
+func (r *Redeemers) UnmarshalDynamoDBAttributeValue(item *dynamodb.AttributeValue) error {
+	switch {
+	case item == nil:
+		return nil
+	case item.B != nil: // redeemers were previously stored as raw json
+		return r.UnmarshalJSON(item.B)
+	}
+
+	var redeemers []Redeemer
+	if err := dynamodbattribute.Unmarshal(item, &redeemers); err != nil {
+		return fmt.Errorf("failed to unmarshal redeemers: %w", err)
+	}
+	*r = redeemers
+	return nil
+}
+
+func (r *Redeemers) UnmarshalJSON(data []byte) error {
+	var m map[string]struct {
+		Redeemer       string
+		ExecutionUnits ExecutionUnits
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return fmt.Errorf("failed to unmarshal redeemers: %w", err)
+	}
+
+	redeemers := make(Redeemers, 0, len(m))
+	for key, item := range m {
+		purpose, index, err := parseRedeemerKey(key)
+		if err != nil {
+			return err
+		}
+		redeemers = append(redeemers, Redeemer{
+			Purpose:        purpose,
+			Index:          index,
+			Data:           item.Redeemer,
+			ExecutionUnits: item.ExecutionUnits,
+		})
+	}
+	redeemers.sort()
+
+	*r = redeemers
+	return nil
+}
+
+func (r Redeemers) sort() {
+	sort.Slice(r, func(i, j int) bool {
+		if r[i].Purpose != r[j].Purpose {
+			return r[i].Purpose < r[j].Purpose
+		}
+		return r[i].Index < r[j].Index
+	})
+}
+
+func parseRedeemerKey(key string) (string, int, error) {
+	segments := strings.Split(key, ":")
+	if len(segments) != 2 {
+		return "", 0, fmt.Errorf("failed to parse redeemer key, %v: want purpose:index", key)
+	}
+	index, err := strconv.Atoi(segments[1])
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to parse redeemer key, %v: %w", key, err)
+	}
+	return segments[0], index, nil
+}
+
+// Script holds either a native script or the cbor of a plutus script.
+// Scripts are encoded as an object keyed by language e.g.
+// {"plutus:v2": "4e4d..."} or {"native": {"all": [...]}}
+type Script struct {
+	Language string        `json:"language"         dynamodbav:"language"`
+	Native   *NativeScript `json:"native,omitempty" dynamodbav:"native,omitempty"`
+	CBOR     string        `json:"cbor,omitempty"   dynamodbav:"cbor,omitempty"` // hex
+}
+
+func (s Script) MarshalJSON() ([]byte, error) {
+	if s.Language == ScriptNative {
+		return json.Marshal(map[string]*NativeScript{ScriptNative: s.Native})
+	}
+	return json.Marshal(map[string]string{s.Language: s.CBOR})
+}
+
+func (s *Script) UnmarshalJSON(data []byte) error {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return fmt.Errorf("failed to unmarshal script, %v: %w", string(data), err)
+	}
+	if len(m) != 1 {
+		return fmt.Errorf("failed to unmarshal script, %v: want one language", string(data))
+	}
+
+	for language, raw := range m {
+		script := Script{Language: language}
+		if language == ScriptNative {
+			if err := json.Unmarshal(raw, &script.Native); err != nil {
+				return fmt.Errorf("failed to unmarshal native script: %w", err)
+			}
+		} else if err := json.Unmarshal(raw, &script.CBOR); err != nil {
+			return fmt.Errorf("failed to unmarshal %v script: %w", language, err)
+		}
+		*s = script
+	}
+	return nil
+}
+
+// Scripts holds the scripts of a tx keyed by script hash
+type Scripts map[string]Script
+
+func (s *Scripts) UnmarshalDynamoDBAttributeValue(item *dynamodb.AttributeValue) error {
+	switch {
+	case item == nil:
+		return nil
+	case item.B != nil: // scripts were previously stored as raw json
+		var scripts map[string]Script
+		if err := json.Unmarshal(item.B, &scripts); err != nil {
+			return fmt.Errorf("failed to unmarshal scripts: %w", err)
+		}
+		*s = scripts
+		return nil
+	}
+
+	var scripts map[string]Script
+	if err := dynamodbattribute.Unmarshal(item, &scripts); err != nil {
+		return fmt.Errorf("failed to unmarshal scripts: %w", err)
+	}
+	*s = scripts
+	return nil
+}
+
+// Datums holds the datums of a tx keyed by datum hash.  Each datum is plutus
+// data cbor hex; see PlutusDatum
+type Datums map[string]string
+
+func (d *Datums) UnmarshalDynamoDBAttributeValue(item *dynamodb.AttributeValue) error {
+	if item == nil || item.M == nil {
+		var datums map[string]string
+		if err := dynamodbattribute.Unmarshal(item, &datums); err != nil {
+			return fmt.Errorf("failed to unmarshal datums: %w", err)
+		}
+		*d = datums
+		return nil
+	}
+
+	datums := make(Datums, len(item.M))
+	for hash, v := range item.M {
+		switch {
+		case v.B != nil: // datums were previously stored as raw cbor
+			datums[hash] = hex.EncodeToString(v.B)
+		case v.S != nil:
+			datums[hash] = *v.S
+		default:
+			return fmt.Errorf("failed to unmarshal datums: unexpected value for datum, %v", hash)
+		}
+	}
+	*d = datums
+	return nil
+}
+
+// NativeScript is a node of a native script tree.  KeyHash is set for
+// signature, Scripts for all, any and atLeast, Required for atLeast and Slot
+// for the expiresAt and startsAt timelocks.  Native scripts are encoded as
+// "keyHash", {"all": [...]}, {"any": [...]}, {"<required>": [...]},
+// {"expiresAt": slot} or {"startsAt": slot}
+type NativeScript struct {
+	Type     string         `json:"type"               dynamodbav:"type"`
+	KeyHash  string         `json:"keyHash,omitempty"  dynamodbav:"keyHash,omitempty"`
+	Scripts  []NativeScript `json:"scripts,omitempty"  dynamodbav:"scripts,omitempty"`
+	Required int            `json:"required,omitempty" dynamodbav:"required,omitempty"`
+	Slot     uint64         `json:"slot,omitempty"     dynamodbav:"slot,omitempty"`
+}
+
+func (n NativeScript) MarshalJSON() ([]byte, error) {
+	scripts := n.Scripts
+	if scripts == nil {
+		scripts = []NativeScript{}
+	}
+
+	switch n.Type {
+	case NativeScriptSignature:
+		return json.Marshal(n.KeyHash)
+	case NativeScriptAll, NativeScriptAny:
+		return json.Marshal(map[string][]NativeScript{n.Type: scripts})
+	case NativeScriptAtLeast:
+		return json.Marshal(map[string][]NativeScript{strconv.Itoa(n.Required): scripts})
+	case NativeScriptExpiresAt, NativeScriptStartsAt:
+		return json.Marshal(map[string]uint64{n.Type: n.Slot})
+	default:
+		return nil, fmt.Errorf("unable to marshal native script: unknown type, %v", n.Type)
+	}
+}
+
+func (n *NativeScript) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var keyHash string
+		if err := json.Unmarshal(data, &keyHash); err != nil {
+			return fmt.Errorf("failed to unmarshal native script, %v: %w", string(data), err)
+		}
+		*n = NativeScript{Type: NativeScriptSignature, KeyHash: keyHash}
+		return nil
+	}
+
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return fmt.Errorf("failed to unmarshal native script, %v: %w", string(data), err)
+	}
+	if len(m) != 1 {
+		return fmt.Errorf("failed to unmarshal native script, %v: want one clause", string(data))
+	}
+
+	for key, raw := range m {
+		script := NativeScript{Type: key}
+		switch key {
+		case NativeScriptExpiresAt, NativeScriptStartsAt:
+			if err := json.Unmarshal(raw, &script.Slot); err != nil {
+				return fmt.Errorf("failed to unmarshal native script, %v: %w", string(data), err)
+			}
+		default:
+			if key != NativeScriptAll && key != NativeScriptAny {
+				required, err := strconv.Atoi(key)
+				if err != nil {
+					return fmt.Errorf("failed to unmarshal native script, %v: unknown clause", string(data))
+				}
+				script.Type, script.Required = NativeScriptAtLeast, required
+			}
+			if err := json.Unmarshal(raw, &script.Scripts); err != nil {
+				return fmt.Errorf("failed to unmarshal native script, %v: %w", string(data), err)
+			}
+		}
+		*n = script
+	}
+	return nil
+}
+
+// PlutusDatum decodes the datum with the given hash from the witness
+func (w Witness) PlutusDatum(hash string) (plutusdata.Data, error) {
+	datum, ok := w.Datums[hash]
+	if !ok {
+		return plutusdata.Data{}, fmt.Errorf("datum not found, %v", hash)
+	}
+	return plutusdata.DecodeHex(datum)
+}
@@ -0,0 +1,213 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/savaki/ogmigo/ouroboros/plutusdata"
+)
+
+const witnessJSON = `{
+  "datums": {"9e1199a988ba72ffd6e9c269cadb3b53b5f360ff99f112d9b2ee30c4d74ad88b": "d8799f4100ff"},
+  "redeemers": {
+    "spend:1": {"redeemer": "d87980", "executionUnits": {"memory": 1700, "steps": 476468}},
+    "mint:0": {"redeemer": "d87a80", "executionUnits": {"memory": 200, "steps": 5000}}
+  },
+  "scripts": {
+    "a1": {"plutus:v2": "4e4d01000033222220051200120011"},
+    "b2": {"native": {"any": ["3c07030e36bfff7cd2f004356ef320f3fe3c07030e7cd2f004356437", {"2": [{"startsAt": 100}, {"expiresAt": 200}, "d2f0"]}]}}
+  }
+}`
+
+func TestWitness(t *testing.T) {
+	var w Witness
+	if err := json.Unmarshal([]byte(witnessJSON), &w); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	want := Redeemers{
+		{Purpose: RedeemerMint, Index: 0, Data: "d87a80", ExecutionUnits: ExecutionUnits{Memory: 200, Steps: 5000}},
+		{Purpose: RedeemerSpend, Index: 1, Data: "d87980", ExecutionUnits: ExecutionUnits{Memory: 1700, Steps: 476468}},
+	}
+	if got := w.Redeemers; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v; want %#v", got, want)
+	}
+	data, err := w.Redeemers[0].PlutusData()
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := data, plutusdata.Constr(1); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	datum, err := w.PlutusDatum("9e1199a988ba72ffd6e9c269cadb3b53b5f360ff99f112d9b2ee30c4d74ad88b")
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := datum.String(), `{"constructor":0,"fields":[{"bytes":"00"}]}`; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if _, err := w.PlutusDatum("missing"); err == nil {
+		t.Fatalf("got nil; want error")
+	}
+
+	if got, want := w.Scripts["a1"], (Script{Language: ScriptPlutusV2, CBOR: "4e4d01000033222220051200120011"}); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v; want %#v", got, want)
+	}
+	native := NativeScript{
+		Type: NativeScriptAny,
+		Scripts: []NativeScript{
+			{Type: NativeScriptSignature, KeyHash: "3c07030e36bfff7cd2f004356ef320f3fe3c07030e7cd2f004356437"},
+			{
+				Type:     NativeScriptAtLeast,
+				Required: 2,
+				Scripts: []NativeScript{
+					{Type: NativeScriptStartsAt, Slot: 100},
+					{Type: NativeScriptExpiresAt, Slot: 200},
+					{Type: NativeScriptSignature, KeyHash: "d2f0"},
+				},
+			},
+		},
+	}
+	if got, want := w.Scripts["b2"], (Script{Language: ScriptNative, Native: &native}); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v; want %#v", got, want)
+	}
+
+	t.Run("json round trip", func(t *testing.T) {
+		encoded, err := json.Marshal(w)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		assertJSONEqual(t, encoded, []byte(witnessJSON))
+	})
+
+	t.Run("dynamodb round trip", func(t *testing.T) {
+		item, err := dynamodbattribute.Marshal(w)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		var got Witness
+		if err := dynamodbattribute.Unmarshal(item, &got); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if !reflect.DeepEqual(got, w) {
+			t.Fatalf("got %#v; want %#v", got, w)
+		}
+	})
+
+	t.Run("legacy dynamodb", func(t *testing.T) {
+		var raw map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(witnessJSON), &raw); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+
+		var redeemers Redeemers
+		if err := redeemers.UnmarshalDynamoDBAttributeValue(&dynamodb.AttributeValue{B: raw["redeemers"]}); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if !reflect.DeepEqual(redeemers, w.Redeemers) {
+			t.Fatalf("got %#v; want %#v", redeemers, w.Redeemers)
+		}
+
+		var scripts Scripts
+		if err := scripts.UnmarshalDynamoDBAttributeValue(&dynamodb.AttributeValue{B: raw["scripts"]}); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if !reflect.DeepEqual(scripts, w.Scripts) {
+			t.Fatalf("got %#v; want %#v", scripts, w.Scripts)
+		}
+
+		legacy, err := dynamodbattribute.Marshal(map[string][]byte{
+			"9e1199a988ba72ffd6e9c269cadb3b53b5f360ff99f112d9b2ee30c4d74ad88b": {0xd8, 0x79, 0x9f, 0x41, 0x00, 0xff},
+		})
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		var datums Datums
+		if err := datums.UnmarshalDynamoDBAttributeValue(legacy); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if !reflect.DeepEqual(datums, w.Datums) {
+			t.Fatalf("got %#v; want %#v", datums, w.Datums)
+		}
+	})
+}
+
+func TestWitnessV6(t *testing.T) {
+	data := `{
+  "id": "abc",
+  "inputs": [],
+  "outputs": [],
+  "fee": {"ada": {"lovelace": 1}},
+  "datums": {"9e11": "d87980"},
+  "redeemers": [
+    {"validator": {"purpose": "publish", "index": 0}, "redeemer": "d87980", "executionUnits": {"memory": 10, "cpu": 20}},
+    {"validator": {"purpose": "spend", "index": 2}, "redeemer": "d87a80", "executionUnits": {"memory": 30, "cpu": 40}}
+  ],
+  "scripts": {
+    "a1": {"language": "plutus:v3", "cbor": "4e4d"},
+    "b2": {"language": "native", "json": {"clause": "some", "atLeast": 1, "from": [{"clause": "signature", "from": "d2f0"}, {"clause": "before", "slot": 200}]}, "cbor": "8201"}
+  }
+}`
+
+	var tx TxV6
+	if err := json.Unmarshal([]byte(data), &tx); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	w := Tx(tx).Witness
+
+	if got, want := w.Datums["9e11"], "d87980"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	want := Redeemers{
+		{Purpose: RedeemerCertificate, Index: 0, Data: "d87980", ExecutionUnits: ExecutionUnits{Memory: 10, Steps: 20}},
+		{Purpose: RedeemerSpend, Index: 2, Data: "d87a80", ExecutionUnits: ExecutionUnits{Memory: 30, Steps: 40}},
+	}
+	if got := w.Redeemers; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v; want %#v", got, want)
+	}
+	if got, want := w.Scripts["a1"], (Script{Language: ScriptPlutusV3, CBOR: "4e4d"}); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v; want %#v", got, want)
+	}
+
+	native := NativeScript{
+		Type:     NativeScriptAtLeast,
+		Required: 1,
+		Scripts: []NativeScript{
+			{Type: NativeScriptSignature, KeyHash: "d2f0"},
+			{Type: NativeScriptExpiresAt, Slot: 200},
+		},
+	}
+	if got, want := w.Scripts["b2"], (Script{Language: ScriptNative, Native: &native}); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v; want %#v", got, want)
+	}
+
+	encoded, err := json.Marshal(NativeScriptV6(native))
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	var got NativeScriptV6
+	if err := json.Unmarshal(encoded, &got); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if !reflect.DeepEqual(NativeScript(got), native) {
+		t.Fatalf("got %#v; want %#v", got, native)
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package coinselection selects the utxos that cover a target value using
+// the algorithms of CIP-2; largest-first and random-improve.  Native assets
+// are covered one at a time, in asset id order, followed by coins.  See
+// https://cips.cardano.org/cips/cip2/
+package coinselection
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/savaki/ogmigo/ouroboros/chainsync"
+	"github.com/savaki/ogmigo/ouroboros/chainsync/num"
+	"github.com/savaki/ogmigo/ouroboros/statequery"
+)
+
+var (
+	// ErrInsufficientFunds is matched, by errors.Is, by InsufficientFundsError
+	ErrInsufficientFunds = errors.New("insufficient funds")
+
+	// ErrMaxInputsExceeded is returned when covering the target requires more
+	// inputs than allowed by WithMaxInputs
+	ErrMaxInputsExceeded = errors.New("max inputs exceeded")
+)
+
+// InsufficientFundsError reports the coins, or the asset if AssetID is set,
+// that the utxos could not cover
+type InsufficientFundsError struct {
+	AssetID   chainsync.AssetID // blank for coins
+	Required  num.Int
+	Available num.Int
+}
+
+func (e InsufficientFundsError) Error() string {
+	what := "coins"
+	if e.AssetID != "" {
+		what = "asset " + string(e.AssetID)
+	}
+	return fmt.Sprintf("%v: %v required %v; available %v", ErrInsufficientFunds, what, e.Required, e.Available)
+}
+
+// Is allows errors.Is(err, ErrInsufficientFunds)
+func (e InsufficientFundsError) Is(target error) bool {
+	return target == ErrInsufficientFunds
+}
+
+// Selection holds the utxos selected and the change; the value of the
+// inputs less the target
+type Selection struct {
+	Inputs []statequery.Utxo
+	Change chainsync.Value
+}
+
+// Algorithm selects utxos covering target
+type Algorithm func(utxos []statequery.Utxo, target chainsync.Value, opts ...Option) (Selection, error)
+
+// Options for coin selection
+type Options struct {
+	maxInputs     int
+	params        *statequery.ProtocolParameters
+	changeAddress string
+	rand          *rand.Rand
+}
+
+// Option to coin selection
+type Option func(*Options)
+
+// WithMaxInputs limits the number of utxos selected; unlimited by default
+func WithMaxInputs(n int) Option {
+	return func(opts *Options) {
+		opts.maxInputs = n
+	}
+}
+
+// WithMinUtxoChange selects additional utxos, if needed, so the change paid
+// to the change address holds at least the min utxo
+func WithMinUtxoChange(params statequery.ProtocolParameters, changeAddress string) Option {
+	return func(opts *Options) {
+		opts.params = &params
+		opts.changeAddress = changeAddress
+	}
+}
+
+// WithRand specifies the source of randomness for RandomImprove
+func WithRand(r *rand.Rand) Option {
+	return func(opts *Options) {
+		opts.rand = r
+	}
+}
+
+func buildOptions(opts ...Option) Options {
+	var options Options
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.rand == nil {
+		options.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return options
+}
+
+// component identifies the coins, or an asset, of a value
+type component struct {
+	assetID chainsync.AssetID // blank for coins
+}
+
+func (c component) quantity(v chainsync.Value) num.Int {
+	if c.assetID == "" {
+		return v.Coins
+	}
+	return v.Assets[c.assetID]
+}
+
+// components returns the components of target to cover, in order; assets
+// then coins
+func components(target chainsync.Value) []component {
+	var cc []component
+	for _, id := range target.AssetIDs() {
+		cc = append(cc, component{assetID: id})
+	}
+	return append(cc, component{})
+}
+
+// positive returns the positive quantities of v
+func positive(v chainsync.Value) chainsync.Value {
+	p := chainsync.Value{}
+	if v.Coins.Sign() > 0 {
+		p.Coins = v.Coins
+	}
+	for _, id := range v.AssetIDs() {
+		if quantity := v.Assets[id]; quantity.Sign() > 0 {
+			p = p.Add(chainsync.Value{Assets: map[chainsync.AssetID]num.Int{id: quantity}})
+		}
+	}
+	return p
+}
+
+func sum(utxos []statequery.Utxo) chainsync.Value {
+	total := chainsync.Value{}
+	for _, utxo := range utxos {
+		total = total.Add(utxo.TxOut.Value)
+	}
+	return total
+}
+
+// checkAvailable returns an InsufficientFundsError for the first component
+// of target the utxos cannot cover
+func checkAvailable(utxos []statequery.Utxo, target chainsync.Value) error {
+	available := sum(utxos)
+	for _, c := range components(target) {
+		if required, got := c.quantity(target), c.quantity(available); got.Cmp(required) < 0 {
+			return InsufficientFundsError{AssetID: c.assetID, Required: required, Available: got}
+		}
+	}
+	return nil
+}
+
+// take moves the utxo at index i of remaining to selected
+func take(selected, remaining []statequery.Utxo, i int) ([]statequery.Utxo, []statequery.Utxo) {
+	selected = append(selected, remaining[i])
+	remaining = append(remaining[:i:i], remaining[i+1:]...)
+	return selected, remaining
+}
+
+// finish verifies the max inputs and tops up the change to the min utxo
+func finish(selected, remaining []statequery.Utxo, target chainsync.Value, options Options) (Selection, error) {
+	for {
+		if max := options.maxInputs; max > 0 && len(selected) > max {
+			return Selection{}, fmt.Errorf("%w: %v inputs required; max %v", ErrMaxInputsExceeded, len(selected), max)
+		}
+
+		change := sum(selected).Sub(target)
+		if options.params == nil || change.IsZero() {
+			return Selection{Inputs: selected, Change: change}, nil
+		}
+
+		minUtxo, err := options.params.MinUtxo(chainsync.TxOut{Address: options.changeAddress, Value: change})
+		if err != nil {
+			return Selection{}, err
+		}
+		if change.Coins.Cmp(minUtxo) >= 0 {
+			return Selection{Inputs: selected, Change: change}, nil
+		}
+
+		// add the utxo holding the most coins and try again
+		best := -1
+		for i, utxo := range remaining {
+			if best < 0 || utxo.TxOut.Value.Coins.Cmp(remaining[best].TxOut.Value.Coins) > 0 {
+				best = i
+			}
+		}
+		if best < 0 || remaining[best].TxOut.Value.Coins.Sign() <= 0 {
+			return Selection{}, InsufficientFundsError{
+				Required:  target.Coins.Add(minUtxo),
+				Available: sum(selected).Coins,
+			}
+		}
+		selected, remaining = take(selected, remaining, best)
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package coinselection
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/savaki/ogmigo/ouroboros/chainsync"
+	"github.com/savaki/ogmigo/ouroboros/chainsync/num"
+	"github.com/savaki/ogmigo/ouroboros/statequery"
+)
+
+const (
+	testAddress = "addr_test1vz2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzerspjrlsz"
+	testAssetA  = chainsync.AssetID("2a286ad895d091f2b3d168a6091ad2627d30a72761a5bc36eef00740.414243")
+	testAssetB  = chainsync.AssetID("b8a4cc07fe5e73a4ea6a8cc8dfd6c1a2a1d8ac0bd9c1e4fe5e0ca2c9.58595a")
+)
+
+func testParams(t *testing.T) statequery.ProtocolParameters {
+	t.Helper()
+	data, err := ioutil.ReadFile("testdata/currentProtocolParametersBabbage.json")
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	var params statequery.ProtocolParameters
+	if err := json.Unmarshal(data, &params); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	return params
+}
+
+func testUtxo(index int, coins int64, assets map[chainsync.AssetID]num.Int) statequery.Utxo {
+	return statequery.Utxo{
+		TxIn: chainsync.TxIn{
+			TxHash: strings.Repeat("cd", 32),
+			Index:  index,
+		},
+		TxOut: chainsync.TxOut{
+			Address: testAddress,
+			Value:   chainsync.Value{Coins: num.Int64(coins), Assets: assets},
+		},
+	}
+}
+
+func indexes(utxos []statequery.Utxo) []int {
+	var ii []int
+	for _, utxo := range utxos {
+		ii = append(ii, utxo.TxIn.Index)
+	}
+	return ii
+}
+
+// assertCovers verifies the selection covers target and its change is the
+// value of the inputs less target
+func assertCovers(t *testing.T, selection Selection, target chainsync.Value) {
+	t.Helper()
+	total := sum(selection.Inputs)
+	if !total.GreaterOrEqual(target) {
+		t.Fatalf("got %v; want at least %v", total, target)
+	}
+	if got, want := selection.Change, total.Sub(target); !got.Equal(want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestInsufficientFundsError(t *testing.T) {
+	utxos := []statequery.Utxo{
+		testUtxo(0, 5000000, map[chainsync.AssetID]num.Int{testAssetA: num.Int64(10)}),
+	}
+
+	testCases := map[string]struct {
+		Target chainsync.Value
+		Want   InsufficientFundsError
+	}{
+		"coins": {
+			Target: chainsync.Value{Coins: num.Int64(6000000)},
+			Want:   InsufficientFundsError{Required: num.Int64(6000000), Available: num.Int64(5000000)},
+		},
+		"asset": {
+			Target: chainsync.Value{Coins: num.Int64(1000000), Assets: map[chainsync.AssetID]num.Int{testAssetA: num.Int64(11)}},
+			Want:   InsufficientFundsError{AssetID: testAssetA, Required: num.Int64(11), Available: num.Int64(10)},
+		},
+		"missing asset": {
+			Target: chainsync.Value{Assets: map[chainsync.AssetID]num.Int{testAssetB: num.Int64(1)}},
+			Want:   InsufficientFundsError{AssetID: testAssetB, Required: num.Int64(1)},
+		},
+	}
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			for name, algorithm := range map[string]Algorithm{"largest first": LargestFirst, "random improve": RandomImprove} {
+				_, err := algorithm(utxos, tc.Target)
+				if !errors.Is(err, ErrInsufficientFunds) {
+					t.Fatalf("%v: got %v; want ErrInsufficientFunds", name, err)
+				}
+				var got InsufficientFundsError
+				if !errors.As(err, &got) {
+					t.Fatalf("%v: got %T; want InsufficientFundsError", name, err)
+				}
+				if got.AssetID != tc.Want.AssetID || got.Required.Cmp(tc.Want.Required) != 0 || got.Available.Cmp(tc.Want.Available) != 0 {
+					t.Fatalf("%v: got %v; want %v", name, got, tc.Want)
+				}
+			}
+		})
+	}
+}
+
+func TestWithMinUtxoChange(t *testing.T) {
+	params := testParams(t)
+	utxos := []statequery.Utxo{
+		testUtxo(0, 3000000, nil),
+		testUtxo(1, 1500000, nil),
+	}
+	target := chainsync.Value{Coins: num.Int64(2500000)}
+
+	// change of 0.5 ada is below the min utxo so the second utxo is selected
+	selection, err := LargestFirst(utxos, target, WithMinUtxoChange(params, testAddress))
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	assertCovers(t, selection, target)
+	if got, want := len(selection.Inputs), 2; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := selection.Change.Coins.Int64(), int64(2000000); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	// exact amounts need no change
+	selection, err = LargestFirst(utxos, chainsync.Value{Coins: num.Int64(3000000)}, WithMinUtxoChange(params, testAddress))
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := len(selection.Inputs), 1; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	// nothing left to top up the change
+	_, err = LargestFirst(utxos, chainsync.Value{Coins: num.Int64(4000000)}, WithMinUtxoChange(params, testAddress))
+	if !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatalf("got %v; want ErrInsufficientFunds", err)
+	}
+}
+
+func TestPositive(t *testing.T) {
+	v := chainsync.Value{
+		Coins: num.Int64(-5),
+		Assets: map[chainsync.AssetID]num.Int{
+			testAssetA: num.Int64(3),
+			testAssetB: num.Int64(-2),
+		},
+	}
+	want := chainsync.Value{Assets: map[chainsync.AssetID]num.Int{testAssetA: num.Int64(3)}}
+	if got := positive(v); !got.Equal(want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
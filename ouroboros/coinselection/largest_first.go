@@ -0,0 +1,62 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package coinselection
+
+import (
+	"sort"
+
+	"github.com/savaki/ogmigo/ouroboros/chainsync"
+	"github.com/savaki/ogmigo/ouroboros/chainsync/num"
+	"github.com/savaki/ogmigo/ouroboros/statequery"
+)
+
+var _ Algorithm = LargestFirst
+
+// LargestFirst selects, for each asset of target and then coins, the utxos
+// holding the most of it until target is covered.  Only the positive
+// quantities of target are considered
+func LargestFirst(utxos []statequery.Utxo, target chainsync.Value, opts ...Option) (Selection, error) {
+	options := buildOptions(opts...)
+	target = positive(target)
+	if err := checkAvailable(utxos, target); err != nil {
+		return Selection{}, err
+	}
+
+	remaining := append([]statequery.Utxo{}, utxos...)
+	var selected []statequery.Utxo
+	for _, c := range components(target) {
+		selected, remaining = largestFirst(selected, remaining, c, c.quantity(target))
+	}
+	return finish(selected, remaining, target, options)
+}
+
+// largestFirst moves the utxos of remaining holding the most of c to
+// selected until selected covers required
+func largestFirst(selected, remaining []statequery.Utxo, c component, required num.Int) ([]statequery.Utxo, []statequery.Utxo) {
+	sort.SliceStable(remaining, func(i, j int) bool {
+		return c.quantity(remaining[i].TxOut.Value).Cmp(c.quantity(remaining[j].TxOut.Value)) > 0
+	})
+
+	total := c.quantity(sum(selected))
+	for total.Cmp(required) < 0 && len(remaining) > 0 {
+		quantity := c.quantity(remaining[0].TxOut.Value)
+		if quantity.Sign() <= 0 {
+			break
+		}
+		total = total.Add(quantity)
+		selected, remaining = take(selected, remaining, 0)
+	}
+	return selected, remaining
+}
@@ -0,0 +1,96 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package coinselection
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/savaki/ogmigo/ouroboros/chainsync"
+	"github.com/savaki/ogmigo/ouroboros/chainsync/num"
+	"github.com/savaki/ogmigo/ouroboros/statequery"
+)
+
+func TestLargestFirst(t *testing.T) {
+	utxos := []statequery.Utxo{
+		testUtxo(0, 1000000, nil),
+		testUtxo(1, 2000000, map[chainsync.AssetID]num.Int{testAssetA: num.Int64(5)}),
+		testUtxo(2, 4000000, nil),
+		testUtxo(3, 1500000, map[chainsync.AssetID]num.Int{testAssetA: num.Int64(20), testAssetB: num.Int64(1)}),
+		testUtxo(4, 3000000, nil),
+	}
+
+	testCases := map[string]struct {
+		Target chainsync.Value
+		Want   []int
+	}{
+		"coins": {
+			Target: chainsync.Value{Coins: num.Int64(6000000)},
+			Want:   []int{2, 4},
+		},
+		"exact": {
+			Target: chainsync.Value{Coins: num.Int64(4000000)},
+			Want:   []int{2},
+		},
+		"assets first": {
+			Target: chainsync.Value{Coins: num.Int64(2000000), Assets: map[chainsync.AssetID]num.Int{testAssetA: num.Int64(22)}},
+			Want:   []int{3, 1},
+		},
+		"assets then coins": {
+			Target: chainsync.Value{Coins: num.Int64(5000000), Assets: map[chainsync.AssetID]num.Int{testAssetB: num.Int64(1)}},
+			Want:   []int{3, 2},
+		},
+		"negative ignored": {
+			Target: chainsync.Value{Coins: num.Int64(1), Assets: map[chainsync.AssetID]num.Int{testAssetA: num.Int64(-5)}},
+			Want:   []int{2},
+		},
+	}
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			selection, err := LargestFirst(utxos, tc.Target)
+			if err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			assertCovers(t, selection, positive(tc.Target))
+			if got, want := indexes(selection.Inputs), tc.Want; !reflect.DeepEqual(got, want) {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+		})
+	}
+
+	// the utxos passed in are not reordered
+	if got, want := indexes(utxos), []int{0, 1, 2, 3, 4}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestLargestFirst_MaxInputs(t *testing.T) {
+	utxos := []statequery.Utxo{
+		testUtxo(0, 1000000, nil),
+		testUtxo(1, 2000000, nil),
+		testUtxo(2, 3000000, nil),
+	}
+	target := chainsync.Value{Coins: num.Int64(5000000)}
+
+	if _, err := LargestFirst(utxos, target, WithMaxInputs(2)); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	target = chainsync.Value{Coins: num.Int64(5500000)}
+	if _, err := LargestFirst(utxos, target, WithMaxInputs(2)); !errors.Is(err, ErrMaxInputsExceeded) {
+		t.Fatalf("got %v; want ErrMaxInputsExceeded", err)
+	}
+}
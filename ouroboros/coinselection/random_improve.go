@@ -0,0 +1,94 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package coinselection
+
+import (
+	"github.com/savaki/ogmigo/ouroboros/chainsync"
+	"github.com/savaki/ogmigo/ouroboros/chainsync/num"
+	"github.com/savaki/ogmigo/ouroboros/statequery"
+)
+
+var _ Algorithm = RandomImprove
+
+// RandomImprove implements the random-improve algorithm of CIP-2.  For each
+// asset of target and then coins, utxos holding it are selected at random
+// until it is covered.  Each is then improved by selecting further random
+// utxos while they bring the total closer to twice the target, without
+// exceeding three times the target or the max inputs.  Falls back to
+// LargestFirst if the random phase exceeds the max inputs
+func RandomImprove(utxos []statequery.Utxo, target chainsync.Value, opts ...Option) (Selection, error) {
+	options := buildOptions(opts...)
+	target = positive(target)
+	if err := checkAvailable(utxos, target); err != nil {
+		return Selection{}, err
+	}
+
+	remaining := append([]statequery.Utxo{}, utxos...)
+	var selected []statequery.Utxo
+
+	// random phase
+	cc := components(target)
+	for _, c := range cc {
+		required := c.quantity(target)
+		for c.quantity(sum(selected)).Cmp(required) < 0 {
+			i, ok := pick(remaining, c, options)
+			if !ok {
+				return LargestFirst(utxos, target, opts...)
+			}
+			selected, remaining = take(selected, remaining, i)
+		}
+		if options.maxInputs > 0 && len(selected) > options.maxInputs {
+			return LargestFirst(utxos, target, opts...)
+		}
+	}
+
+	// improvement phase
+	for _, c := range cc {
+		required := c.quantity(target)
+		if required.IsZero() {
+			continue
+		}
+		ideal := required.Mul(num.Int64(2))
+		upper := required.Mul(num.Int64(3))
+		for options.maxInputs <= 0 || len(selected) < options.maxInputs {
+			i, ok := pick(remaining, c, options)
+			if !ok {
+				break
+			}
+			total := c.quantity(sum(selected))
+			next := total.Add(c.quantity(remaining[i].TxOut.Value))
+			if next.Cmp(upper) > 0 || ideal.Sub(next).Abs().Cmp(ideal.Sub(total).Abs()) >= 0 {
+				break
+			}
+			selected, remaining = take(selected, remaining, i)
+		}
+	}
+
+	return finish(selected, remaining, target, options)
+}
+
+// pick returns the index of a random utxo of remaining holding some of c
+func pick(remaining []statequery.Utxo, c component, options Options) (int, bool) {
+	var candidates []int
+	for i, utxo := range remaining {
+		if c.quantity(utxo.TxOut.Value).Sign() > 0 {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) == 0 {
+		return 0, false
+	}
+	return candidates[options.rand.Intn(len(candidates))], true
+}
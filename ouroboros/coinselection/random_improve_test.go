@@ -0,0 +1,114 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package coinselection
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/savaki/ogmigo/ouroboros/chainsync"
+	"github.com/savaki/ogmigo/ouroboros/chainsync/num"
+	"github.com/savaki/ogmigo/ouroboros/statequery"
+)
+
+func TestRandomImprove(t *testing.T) {
+	var utxos []statequery.Utxo
+	for i := 0; i < 50; i++ {
+		var assets map[chainsync.AssetID]num.Int
+		if i%10 == 0 {
+			assets = map[chainsync.AssetID]num.Int{testAssetA: num.Int64(int64(i + 1))}
+		}
+		utxos = append(utxos, testUtxo(i, int64(1000000+i*100000), assets))
+	}
+	target := chainsync.Value{
+		Coins:  num.Int64(10000000),
+		Assets: map[chainsync.AssetID]num.Int{testAssetA: num.Int64(15)},
+	}
+
+	for seed := int64(0); seed < 20; seed++ {
+		selection, err := RandomImprove(utxos, target, WithRand(rand.New(rand.NewSource(seed))))
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		assertCovers(t, selection, target)
+
+		// improvement never takes a component past 3x the target
+		total := sum(selection.Inputs)
+		if upper := target.Coins.Mul(num.Int64(3)); total.Coins.Cmp(upper) > 0 {
+			t.Fatalf("got %v; want at most %v", total.Coins, upper)
+		}
+
+		seen := map[int]bool{}
+		for _, i := range indexes(selection.Inputs) {
+			if seen[i] {
+				t.Fatalf("got duplicate input %v; want unique", i)
+			}
+			seen[i] = true
+		}
+	}
+}
+
+func TestRandomImprove_Deterministic(t *testing.T) {
+	var utxos []statequery.Utxo
+	for i := 0; i < 20; i++ {
+		utxos = append(utxos, testUtxo(i, 1000000, nil))
+	}
+	target := chainsync.Value{Coins: num.Int64(3000000)}
+
+	a, err := RandomImprove(utxos, target, WithRand(rand.New(rand.NewSource(1))))
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	b, err := RandomImprove(utxos, target, WithRand(rand.New(rand.NewSource(1))))
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := indexes(a.Inputs), indexes(b.Inputs); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	// equal utxos improve to exactly twice the target
+	if got, want := sum(a.Inputs).Coins.Int64(), int64(6000000); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestRandomImprove_MaxInputs(t *testing.T) {
+	utxos := []statequery.Utxo{
+		testUtxo(0, 1000000, nil),
+		testUtxo(1, 1000000, nil),
+		testUtxo(2, 1000000, nil),
+		testUtxo(3, 5000000, nil),
+	}
+	target := chainsync.Value{Coins: num.Int64(2500000)}
+
+	// the random phase may require 3 inputs; largest first requires 1
+	for seed := int64(0); seed < 20; seed++ {
+		selection, err := RandomImprove(utxos, target, WithMaxInputs(1), WithRand(rand.New(rand.NewSource(seed))))
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := len(selection.Inputs), 1; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	}
+
+	_, err := RandomImprove(utxos[:3], target, WithMaxInputs(2))
+	if !errors.Is(err, ErrMaxInputsExceeded) {
+		t.Fatalf("got %v; want ErrMaxInputsExceeded", err)
+	}
+}
@@ -0,0 +1,254 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plutusdata
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+)
+
+// cbor major types
+const (
+	majorUint   = 0
+	majorNegInt = 1
+	majorBytes  = 2
+	majorList   = 4
+	majorMap    = 5
+	majorTag    = 6
+)
+
+// cbor tags used by plutus data.  Constructors 0-6 are tagged 121-127,
+// constructors 7-127 are tagged 1280-1400 and any constructor may be encoded
+// as 102([constructor, fields])
+const (
+	tagPosBignum      = 2
+	tagNegBignum      = 3
+	tagConstr         = 102
+	tagConstrSmall    = 121
+	tagConstrSmallMax = 127
+	tagConstrLarge    = 1280
+	tagConstrLargeMax = 1400
+)
+
+const (
+	breakCode          = 0xff
+	indefiniteArgument = 31
+	maxDepth           = 1024
+)
+
+// head decodes the initial byte and argument of a cbor item.  indefinite is
+// true for indefinite length byte strings, lists and maps
+func head(data []byte) (major byte, arg uint64, n int, indefinite bool, err error) {
+	if len(data) == 0 {
+		return 0, 0, 0, false, fmt.Errorf("unexpected end of data")
+	}
+
+	major, info := data[0]>>5, data[0]&0x1f
+	switch {
+	case info < 24:
+		return major, uint64(info), 1, false, nil
+	case info == indefiniteArgument:
+		return major, 0, 1, true, nil
+	case info > 27:
+		return 0, 0, 0, false, fmt.Errorf("invalid additional info, %v", info)
+	}
+
+	size := 1 << (info - 24)
+	if len(data) < 1+size {
+		return 0, 0, 0, false, fmt.Errorf("unexpected end of data")
+	}
+	switch size {
+	case 1:
+		arg = uint64(data[1])
+	case 2:
+		arg = uint64(binary.BigEndian.Uint16(data[1:]))
+	case 4:
+		arg = uint64(binary.BigEndian.Uint32(data[1:]))
+	default:
+		arg = binary.BigEndian.Uint64(data[1:])
+	}
+	return major, arg, 1 + size, false, nil
+}
+
+func decode(data []byte, depth int) (Data, []byte, error) {
+	if depth > maxDepth {
+		return Data{}, nil, fmt.Errorf("exceeded max depth, %v", maxDepth)
+	}
+
+	major, arg, n, indefinite, err := head(data)
+	if err != nil {
+		return Data{}, nil, err
+	}
+	if indefinite && major != majorBytes && major != majorList && major != majorMap {
+		return Data{}, nil, fmt.Errorf("invalid indefinite length, major type %v", major)
+	}
+
+	switch major {
+	case majorUint:
+		return Data{Type: DataTypeInt, Int: new(big.Int).SetUint64(arg)}, data[n:], nil
+
+	case majorNegInt:
+		v := new(big.Int).SetUint64(arg)
+		return Data{Type: DataTypeInt, Int: v.Neg(v).Sub(v, big.NewInt(1))}, data[n:], nil
+
+	case majorBytes:
+		v, rest, err := decodeBytes(data)
+		if err != nil {
+			return Data{}, nil, err
+		}
+		return Bytes(v), rest, nil
+
+	case majorList:
+		items, rest, err := decodeItems(data[n:], arg, indefinite, depth)
+		if err != nil {
+			return Data{}, nil, err
+		}
+		return List(items...), rest, nil
+
+	case majorMap:
+		items, rest, err := decodeItems(data[n:], arg*2, indefinite, depth)
+		if err != nil {
+			return Data{}, nil, err
+		}
+		if len(items)%2 != 0 {
+			return Data{}, nil, fmt.Errorf("map holds odd number of items, %v", len(items))
+		}
+		pairs := make([]Pair, 0, len(items)/2)
+		for i := 0; i < len(items); i += 2 {
+			pairs = append(pairs, Pair{Key: items[i], Value: items[i+1]})
+		}
+		return Map(pairs...), rest, nil
+
+	case majorTag:
+		return decodeTag(arg, data[n:], depth)
+
+	default:
+		return Data{}, nil, fmt.Errorf("unsupported major type, %v", major)
+	}
+}
+
+// decodeItems decodes count items or, if indefinite, items until the break
+func decodeItems(data []byte, count uint64, indefinite bool, depth int) ([]Data, []byte, error) {
+	var items []Data
+	for i := uint64(0); indefinite || i < count; i++ {
+		if indefinite {
+			if len(data) == 0 {
+				return nil, nil, fmt.Errorf("unexpected end of data")
+			}
+			if data[0] == breakCode {
+				return items, data[1:], nil
+			}
+		}
+
+		item, rest, err := decode(data, depth+1)
+		if err != nil {
+			return nil, nil, err
+		}
+		items = append(items, item)
+		data = rest
+	}
+	return items, data, nil
+}
+
+// decodeBytes decodes a byte string; indefinite length byte strings are
+// concatenated from their chunks
+func decodeBytes(data []byte) ([]byte, []byte, error) {
+	major, arg, n, indefinite, err := head(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if major != majorBytes {
+		return nil, nil, fmt.Errorf("got major type %v; want bytes", major)
+	}
+
+	if !indefinite {
+		if uint64(len(data)-n) < arg {
+			return nil, nil, fmt.Errorf("unexpected end of data")
+		}
+		end := n + int(arg)
+		return append([]byte{}, data[n:end]...), data[end:], nil
+	}
+
+	v := []byte{}
+	data = data[n:]
+	for {
+		if len(data) == 0 {
+			return nil, nil, fmt.Errorf("unexpected end of data")
+		}
+		if data[0] == breakCode {
+			return v, data[1:], nil
+		}
+		if _, _, _, nested, _ := head(data); nested {
+			return nil, nil, fmt.Errorf("invalid nested indefinite byte string")
+		}
+
+		chunk, rest, err := decodeBytes(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		v = append(v, chunk...)
+		data = rest
+	}
+}
+
+func decodeTag(tag uint64, data []byte, depth int) (Data, []byte, error) {
+	switch {
+	case tag == tagPosBignum || tag == tagNegBignum:
+		v, rest, err := decodeBytes(data)
+		if err != nil {
+			return Data{}, nil, fmt.Errorf("invalid bignum: %w", err)
+		}
+		i := new(big.Int).SetBytes(v)
+		if tag == tagNegBignum {
+			i.Neg(i).Sub(i, big.NewInt(1))
+		}
+		return Data{Type: DataTypeInt, Int: i}, rest, nil
+
+	case tag >= tagConstrSmall && tag <= tagConstrSmallMax:
+		return decodeConstr(tag-tagConstrSmall, data, depth)
+
+	case tag >= tagConstrLarge && tag <= tagConstrLargeMax:
+		return decodeConstr(tag-tagConstrLarge+7, data, depth)
+
+	case tag == tagConstr:
+		d, rest, err := decode(data, depth+1)
+		if err != nil {
+			return Data{}, nil, err
+		}
+		if d.Type != DataTypeList || len(d.Fields) != 2 {
+			return Data{}, nil, fmt.Errorf("invalid constr, got %v; want [constructor, fields]", d)
+		}
+		constructor, fields := d.Fields[0], d.Fields[1]
+		if constructor.Type != DataTypeInt || !constructor.Int.IsUint64() || fields.Type != DataTypeList {
+			return Data{}, nil, fmt.Errorf("invalid constr, got %v; want [constructor, fields]", d)
+		}
+		return Constr(constructor.Int.Uint64(), fields.Fields...), rest, nil
+
+	default:
+		return Data{}, nil, fmt.Errorf("unsupported tag, %v", tag)
+	}
+}
+
+func decodeConstr(constructor uint64, data []byte, depth int) (Data, []byte, error) {
+	fields, rest, err := decode(data, depth+1)
+	if err != nil {
+		return Data{}, nil, err
+	}
+	if fields.Type != DataTypeList {
+		return Data{}, nil, fmt.Errorf("invalid constr %v: got %v; want list of fields", constructor, fields.Type)
+	}
+	return Constr(constructor, fields.Fields...), rest, nil
+}
@@ -0,0 +1,238 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package plutusdata decodes plutus data, the datums and redeemers passed to
+// plutus scripts, into a generic tree.  The tree renders as json using the
+// detailed schema of cardano-cli e.g. {"constructor": 0, "fields": []}
+package plutusdata
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+type DataType int
+
+const (
+	DataTypeConstr DataType = 1
+	DataTypeMap    DataType = 2
+	DataTypeList   DataType = 3
+	DataTypeInt    DataType = 4
+	DataTypeBytes  DataType = 5
+)
+
+func (t DataType) String() string {
+	switch t {
+	case DataTypeConstr:
+		return "constr"
+	case DataTypeMap:
+		return "map"
+	case DataTypeList:
+		return "list"
+	case DataTypeInt:
+		return "int"
+	case DataTypeBytes:
+		return "bytes"
+	default:
+		return "invalid"
+	}
+}
+
+// Data is a node of a plutus data tree.  Only the fields relevant to Type
+// are set; Fields holds the fields of a constr or the items of a list
+type Data struct {
+	Type        DataType
+	Constructor uint64
+	Fields      []Data
+	Map         []Pair
+	Int         *big.Int
+	Bytes       []byte
+}
+
+// Pair holds an entry of a plutus data map.  Maps preserve the order of
+// their entries and may hold duplicate keys
+type Pair struct {
+	Key   Data
+	Value Data
+}
+
+// Constr returns a constructor with the given fields
+func Constr(constructor uint64, fields ...Data) Data {
+	if fields == nil {
+		fields = []Data{}
+	}
+	return Data{Type: DataTypeConstr, Constructor: constructor, Fields: fields}
+}
+
+// Map returns a map holding the given pairs
+func Map(pairs ...Pair) Data {
+	if pairs == nil {
+		pairs = []Pair{}
+	}
+	return Data{Type: DataTypeMap, Map: pairs}
+}
+
+// List returns a list holding the given items
+func List(items ...Data) Data {
+	if items == nil {
+		items = []Data{}
+	}
+	return Data{Type: DataTypeList, Fields: items}
+}
+
+// Int returns an integer
+func Int(v int64) Data {
+	return Data{Type: DataTypeInt, Int: big.NewInt(v)}
+}
+
+// BigInt returns an integer; v is copied
+func BigInt(v *big.Int) Data {
+	return Data{Type: DataTypeInt, Int: new(big.Int).Set(v)}
+}
+
+// Bytes returns a byte string
+func Bytes(v []byte) Data {
+	if v == nil {
+		v = []byte{}
+	}
+	return Data{Type: DataTypeBytes, Bytes: v}
+}
+
+// DecodeHex decodes hex encoded plutus data cbor e.g. TxOut.Datum
+func DecodeHex(s string) (Data, error) {
+	data, err := hex.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return Data{}, fmt.Errorf("failed to decode plutus data: invalid hex: %w", err)
+	}
+	return Decode(data)
+}
+
+// Decode decodes plutus data cbor
+func Decode(data []byte) (Data, error) {
+	d, rest, err := decode(data, 0)
+	if err != nil {
+		return Data{}, fmt.Errorf("failed to decode plutus data: %w", err)
+	}
+	if len(rest) > 0 {
+		return Data{}, fmt.Errorf("failed to decode plutus data: %v trailing bytes", len(rest))
+	}
+	return d, nil
+}
+
+// String returns the json encoding of the data
+func (d Data) String() string {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return "invalid plutus data"
+	}
+	return string(data)
+}
+
+func (d Data) MarshalJSON() ([]byte, error) {
+	switch d.Type {
+	case DataTypeConstr:
+		fields := d.Fields
+		if fields == nil {
+			fields = []Data{}
+		}
+		return json.Marshal(struct {
+			Constructor uint64 `json:"constructor"`
+			Fields      []Data `json:"fields"`
+		}{Constructor: d.Constructor, Fields: fields})
+
+	case DataTypeMap:
+		type pair struct {
+			K Data `json:"k"`
+			V Data `json:"v"`
+		}
+		pairs := make([]pair, 0, len(d.Map))
+		for _, p := range d.Map {
+			pairs = append(pairs, pair{K: p.Key, V: p.Value})
+		}
+		return json.Marshal(map[string][]pair{"map": pairs})
+
+	case DataTypeList:
+		items := d.Fields
+		if items == nil {
+			items = []Data{}
+		}
+		return json.Marshal(map[string][]Data{"list": items})
+
+	case DataTypeInt:
+		if d.Int == nil {
+			return []byte(`{"int":0}`), nil
+		}
+		return []byte(`{"int":` + d.Int.String() + `}`), nil
+
+	case DataTypeBytes:
+		return json.Marshal(map[string]string{"bytes": hex.EncodeToString(d.Bytes)})
+
+	default:
+		return nil, fmt.Errorf("unable to marshal plutus data: unknown type")
+	}
+}
+
+func (d *Data) UnmarshalJSON(data []byte) error {
+	var content struct {
+		Constructor *uint64
+		Fields      []Data
+		Map         []struct {
+			K Data
+			V Data
+		}
+		List  []Data
+		Int   *json.Number
+		Bytes *string
+	}
+	if err := json.Unmarshal(data, &content); err != nil {
+		return fmt.Errorf("failed to unmarshal plutus data, %v: %w", string(data), err)
+	}
+
+	switch {
+	case content.Constructor != nil:
+		*d = Constr(*content.Constructor, content.Fields...)
+
+	case content.Map != nil:
+		pairs := make([]Pair, 0, len(content.Map))
+		for _, p := range content.Map {
+			pairs = append(pairs, Pair{Key: p.K, Value: p.V})
+		}
+		*d = Map(pairs...)
+
+	case content.List != nil:
+		*d = List(content.List...)
+
+	case content.Int != nil:
+		v, ok := new(big.Int).SetString(content.Int.String(), 10)
+		if !ok {
+			return fmt.Errorf("failed to unmarshal plutus data: invalid int, %v", content.Int)
+		}
+		*d = Data{Type: DataTypeInt, Int: v}
+
+	case content.Bytes != nil:
+		v, err := hex.DecodeString(*content.Bytes)
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal plutus data: invalid bytes: %w", err)
+		}
+		*d = Bytes(v)
+
+	default:
+		return fmt.Errorf("failed to unmarshal plutus data, %v: unknown type", string(data))
+	}
+
+	return nil
+}
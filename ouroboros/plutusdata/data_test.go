@@ -0,0 +1,126 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plutusdata
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeHex(t *testing.T) {
+	twoPow64 := new(big.Int).Lsh(big.NewInt(1), 64)
+
+	testCases := map[string]struct {
+		Hex  string
+		Want Data
+		JSON string
+	}{
+		"unit": {
+			Hex:  "d87980",
+			Want: Constr(0),
+			JSON: `{"constructor":0,"fields":[]}`,
+		},
+		"constr indefinite": {
+			Hex:  "d8799f430102031903e8ff",
+			Want: Constr(0, Bytes([]byte{1, 2, 3}), Int(1000)),
+			JSON: `{"constructor":0,"fields":[{"bytes":"010203"},{"int":1000}]}`,
+		},
+		"constr 7": {
+			Hex:  "d9050080",
+			Want: Constr(7),
+			JSON: `{"constructor":7,"fields":[]}`,
+		},
+		"constr general": {
+			Hex:  "d866821904d280",
+			Want: Constr(1234),
+			JSON: `{"constructor":1234,"fields":[]}`,
+		},
+		"negative": {
+			Hex:  "3903e7",
+			Want: Int(-1000),
+			JSON: `{"int":-1000}`,
+		},
+		"bignum": {
+			Hex:  "c249010000000000000000",
+			Want: BigInt(twoPow64),
+			JSON: `{"int":18446744073709551616}`,
+		},
+		"negative bignum": {
+			Hex:  "c349010000000000000000",
+			Want: BigInt(new(big.Int).Sub(new(big.Int).Neg(twoPow64), big.NewInt(1))),
+			JSON: `{"int":-18446744073709551617}`,
+		},
+		"map": {
+			Hex:  "a2010241ff9fff",
+			Want: Map(Pair{Key: Int(1), Value: Int(2)}, Pair{Key: Bytes([]byte{0xff}), Value: List()}),
+			JSON: `{"map":[{"k":{"int":1},"v":{"int":2}},{"k":{"bytes":"ff"},"v":{"list":[]}}]}`,
+		},
+		"chunked bytes": {
+			Hex:  "5f4201024103ff",
+			Want: Bytes([]byte{1, 2, 3}),
+			JSON: `{"bytes":"010203"}`,
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			got, err := DecodeHex(tc.Hex)
+			if err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			if !reflect.DeepEqual(got, tc.Want) {
+				t.Fatalf("got %v; want %v", got, tc.Want)
+			}
+
+			data, err := json.Marshal(got)
+			if err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			if got, want := string(data), tc.JSON; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+
+			var d Data
+			if err := json.Unmarshal(data, &d); err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			if !reflect.DeepEqual(d, tc.Want) {
+				t.Fatalf("got %v; want %v", d, tc.Want)
+			}
+		})
+	}
+}
+
+func TestDecode_Invalid(t *testing.T) {
+	testCases := map[string]string{
+		"empty":         "",
+		"truncated":     "d8799f43010203",
+		"trailing":      "d8798000",
+		"text":          "6161",
+		"unknown tag":   "d8184100",
+		"constr fields": "d87901",
+		"float":         "f93c00",
+	}
+
+	for label, s := range testCases {
+		t.Run(label, func(t *testing.T) {
+			if _, err := DecodeHex(s); err == nil {
+				t.Fatalf("got nil; want error")
+			}
+		})
+	}
+}
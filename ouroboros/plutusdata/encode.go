@@ -0,0 +1,191 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plutusdata
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// bytes longer than chunkSize are encoded as an indefinite length byte
+// string of chunkSize chunks, as required by the ledger
+const chunkSize = 64
+
+var maxUint64 = new(big.Int).SetUint64(math.MaxUint64)
+
+// Encode returns the cbor encoding of the data.  Encoding follows the
+// plutus ledger: non-empty lists and constr fields are indefinite length,
+// maps are definite length and long byte strings are chunked.  Data decoded
+// from cbor encoded differently will not encode to the same bytes; use
+// HashHex to hash a datum as it appears on chain
+func (d Data) Encode() ([]byte, error) {
+	return encode(nil, d, 0)
+}
+
+// Hash returns the hex encoded blake2b-256 hash of the encoded data
+func (d Data) Hash() (string, error) {
+	data, err := d.Encode()
+	if err != nil {
+		return "", err
+	}
+	return Hash(data), nil
+}
+
+// Hash returns the datum hash, the hex encoded blake2b-256 hash, of the
+// plutus data cbor
+func Hash(data []byte) string {
+	hash := blake2b.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+// HashHex returns the datum hash of the hex encoded plutus data cbor e.g.
+// TxOut.Datum
+func HashHex(s string) (string, error) {
+	data, err := hex.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return "", fmt.Errorf("failed to hash plutus data: invalid hex: %w", err)
+	}
+	return Hash(data), nil
+}
+
+func appendHead(buf []byte, major byte, arg uint64) []byte {
+	major <<= 5
+
+	var scratch [8]byte
+	switch {
+	case arg < 24:
+		return append(buf, major|byte(arg))
+	case arg <= math.MaxUint8:
+		return append(buf, major|24, byte(arg))
+	case arg <= math.MaxUint16:
+		binary.BigEndian.PutUint16(scratch[:], uint16(arg))
+		return append(append(buf, major|25), scratch[:2]...)
+	case arg <= math.MaxUint32:
+		binary.BigEndian.PutUint32(scratch[:], uint32(arg))
+		return append(append(buf, major|26), scratch[:4]...)
+	default:
+		binary.BigEndian.PutUint64(scratch[:], arg)
+		return append(append(buf, major|27), scratch[:]...)
+	}
+}
+
+func appendBytes(buf, v []byte) []byte {
+	if len(v) <= chunkSize {
+		buf = appendHead(buf, majorBytes, uint64(len(v)))
+		return append(buf, v...)
+	}
+
+	buf = append(buf, majorBytes<<5|indefiniteArgument)
+	for len(v) > 0 {
+		n := chunkSize
+		if len(v) < n {
+			n = len(v)
+		}
+		buf = appendHead(buf, majorBytes, uint64(n))
+		buf = append(buf, v[:n]...)
+		v = v[n:]
+	}
+	return append(buf, breakCode)
+}
+
+func appendInt(buf []byte, v *big.Int) []byte {
+	if v == nil {
+		return appendHead(buf, majorUint, 0)
+	}
+
+	if v.Sign() >= 0 {
+		if v.IsUint64() {
+			return appendHead(buf, majorUint, v.Uint64())
+		}
+		buf = appendHead(buf, majorTag, tagPosBignum)
+		return appendBytes(buf, v.Bytes())
+	}
+
+	// negative integers n are encoded as -1 - n
+	n := new(big.Int).Neg(v)
+	n.Sub(n, big.NewInt(1))
+	if n.Cmp(maxUint64) <= 0 {
+		return appendHead(buf, majorNegInt, n.Uint64())
+	}
+	buf = appendHead(buf, majorTag, tagNegBignum)
+	return appendBytes(buf, n.Bytes())
+}
+
+func appendList(buf []byte, items []Data, depth int) ([]byte, error) {
+	if len(items) == 0 {
+		return appendHead(buf, majorList, 0), nil
+	}
+
+	buf = append(buf, majorList<<5|indefiniteArgument)
+	for _, item := range items {
+		var err error
+		if buf, err = encode(buf, item, depth+1); err != nil {
+			return nil, err
+		}
+	}
+	return append(buf, breakCode), nil
+}
+
+func encode(buf []byte, d Data, depth int) ([]byte, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("failed to encode plutus data: exceeded max depth, %v", maxDepth)
+	}
+
+	switch d.Type {
+	case DataTypeConstr:
+		switch c := d.Constructor; {
+		case c <= tagConstrSmallMax-tagConstrSmall:
+			buf = appendHead(buf, majorTag, tagConstrSmall+c)
+		case c <= tagConstrLargeMax-tagConstrLarge+7:
+			buf = appendHead(buf, majorTag, tagConstrLarge+c-7)
+		default:
+			buf = appendHead(buf, majorTag, tagConstr)
+			buf = appendHead(buf, majorList, 2)
+			buf = appendHead(buf, majorUint, c)
+		}
+		return appendList(buf, d.Fields, depth)
+
+	case DataTypeMap:
+		buf = appendHead(buf, majorMap, uint64(len(d.Map)))
+		for _, pair := range d.Map {
+			var err error
+			if buf, err = encode(buf, pair.Key, depth+1); err != nil {
+				return nil, err
+			}
+			if buf, err = encode(buf, pair.Value, depth+1); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+
+	case DataTypeList:
+		return appendList(buf, d.Fields, depth)
+
+	case DataTypeInt:
+		return appendInt(buf, d.Int), nil
+
+	case DataTypeBytes:
+		return appendBytes(buf, d.Bytes), nil
+
+	default:
+		return nil, fmt.Errorf("failed to encode plutus data: unknown type")
+	}
+}
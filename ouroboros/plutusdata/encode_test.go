@@ -0,0 +1,119 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plutusdata
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestData_Encode(t *testing.T) {
+	long := bytes.Repeat([]byte{0xab}, 65)
+	huge, _ := new(big.Int).SetString("-340282366920938463463374607431768211457", 10)
+
+	testCases := map[string]struct {
+		Data Data
+		Hex  string
+	}{
+		"unit": {
+			Data: Constr(0),
+			Hex:  "d87980",
+		},
+		"constr fields": {
+			Data: Constr(0, Bytes([]byte{1, 2, 3}), Int(1000)),
+			Hex:  "d8799f430102031903e8ff",
+		},
+		"constr 7": {
+			Data: Constr(7),
+			Hex:  "d9050080",
+		},
+		"constr 200": {
+			Data: Constr(200, Int(-1)),
+			Hex:  "d8668218c89f20ff",
+		},
+		"map": {
+			Data: Map(Pair{Key: Int(1), Value: List()}),
+			Hex:  "a10180",
+		},
+		"max uint64": {
+			Data: BigInt(new(big.Int).SetUint64(1<<64 - 1)),
+			Hex:  "1bffffffffffffffff",
+		},
+		"bignum": {
+			Data: BigInt(new(big.Int).Lsh(big.NewInt(1), 64)),
+			Hex:  "c249010000000000000000",
+		},
+		"negative bignum": {
+			Data: BigInt(huge),
+			Hex:  "c35101" + strings.Repeat("00", 16),
+		},
+		"chunked bytes": {
+			Data: Bytes(long),
+			Hex:  "5f5840" + strings.Repeat("ab", 64) + "41abff",
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			data, err := tc.Data.Encode()
+			if err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			if got, want := hex.EncodeToString(data), tc.Hex; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+
+			got, err := Decode(data)
+			if err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			if !reflect.DeepEqual(got, tc.Data) {
+				t.Fatalf("got %v; want %v", got, tc.Data)
+			}
+		})
+	}
+
+	if _, err := (Data{}).Encode(); err == nil {
+		t.Fatalf("got nil; want error")
+	}
+}
+
+func TestHash(t *testing.T) {
+	const unitHash = "923918e403bf43c34b4ef6b48eb2ee04babed17320d8d1b9ff9ad086e86f44ec"
+
+	got, err := HashHex("d87980")
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got != unitHash {
+		t.Fatalf("got %v; want %v", got, unitHash)
+	}
+
+	got, err = Constr(0).Hash()
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got != unitHash {
+		t.Fatalf("got %v; want %v", got, unitHash)
+	}
+
+	if _, err := HashHex("zz"); err == nil {
+		t.Fatalf("got nil; want error")
+	}
+}
@@ -0,0 +1,423 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plutusdata
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	bigIntType = reflect.TypeOf(big.Int{})
+	dataType   = reflect.TypeOf(Data{})
+)
+
+// Marshal converts v to plutus data.  Go values are converted as follows:
+//
+//   - Data is used as is
+//   - integers and big.Int become int
+//   - []byte and string become bytes; strings tagged `plutus:",hex"` are hex
+//     decoded
+//   - bool becomes constr 0 (false) or constr 1 (true)
+//   - pointers become constr 0 [v] (Just) or, if nil, constr 1 [] (Nothing)
+//   - slices and arrays become list
+//   - maps become map, sorted by encoded key
+//   - structs become constr; exported fields are the fields of the constr,
+//     in declaration order.  The constructor defaults to 0 and may be set by
+//     tagging a blank field e.g. _ struct{} `plutus:"constr=1"`.  Fields
+//     tagged `plutus:"-"` are skipped
+func Marshal(v interface{}) (Data, error) {
+	return marshal(reflect.ValueOf(v), fieldOptions{}, 0)
+}
+
+// Unmarshal decodes plutus data into the value pointed to by v.  See Marshal
+// for how plutus data maps to go values.  Unmarshalling into an interface{}
+// stores the Data
+func Unmarshal(d Data, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("failed to unmarshal plutus data: got %T; want non-nil pointer", v)
+	}
+	return unmarshal(d, rv.Elem(), fieldOptions{}, 0)
+}
+
+// UnmarshalHex decodes hex encoded plutus data cbor into v.  See Unmarshal
+func UnmarshalHex(s string, v interface{}) error {
+	d, err := DecodeHex(s)
+	if err != nil {
+		return err
+	}
+	return Unmarshal(d, v)
+}
+
+type fieldOptions struct {
+	hex bool
+}
+
+// structInfo describes how a struct maps to a constr
+type structInfo struct {
+	constructor uint64
+	fields      []int
+	options     []fieldOptions
+}
+
+func getStructInfo(t reflect.Type) (structInfo, error) {
+	var info structInfo
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("plutus")
+
+		if field.Name == "_" {
+			if strings.HasPrefix(tag, "constr=") {
+				constructor, err := strconv.ParseUint(strings.TrimPrefix(tag, "constr="), 10, 64)
+				if err != nil {
+					return structInfo{}, fmt.Errorf("invalid plutus tag on %v, %v: %w", t, tag, err)
+				}
+				info.constructor = constructor
+			}
+			continue
+		}
+		if tag == "-" || field.PkgPath != "" {
+			continue
+		}
+
+		var options fieldOptions
+		for _, option := range strings.Split(tag, ",")[1:] {
+			switch option {
+			case "hex":
+				options.hex = true
+			default:
+				return structInfo{}, fmt.Errorf("invalid plutus tag on %v.%v, %v", t, field.Name, tag)
+			}
+		}
+		info.fields = append(info.fields, i)
+		info.options = append(info.options, options)
+	}
+	return info, nil
+}
+
+func marshal(v reflect.Value, options fieldOptions, depth int) (Data, error) {
+	if depth > maxDepth {
+		return Data{}, fmt.Errorf("failed to marshal plutus data: exceeded max depth, %v", maxDepth)
+	}
+	if !v.IsValid() {
+		return Data{}, fmt.Errorf("failed to marshal plutus data: invalid value")
+	}
+
+	switch v.Type() {
+	case dataType:
+		return v.Interface().(Data), nil
+	case bigIntType:
+		i := v.Interface().(big.Int)
+		return BigInt(&i), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Int(v.Int()), nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return BigInt(new(big.Int).SetUint64(v.Uint())), nil
+
+	case reflect.Bool:
+		if v.Bool() {
+			return Constr(1), nil
+		}
+		return Constr(0), nil
+
+	case reflect.String:
+		if options.hex {
+			b, err := hex.DecodeString(v.String())
+			if err != nil {
+				return Data{}, fmt.Errorf("failed to marshal plutus data: invalid hex: %w", err)
+			}
+			return Bytes(b), nil
+		}
+		return Bytes([]byte(v.String())), nil
+
+	case reflect.Ptr:
+		if v.IsNil() {
+			return Constr(1), nil
+		}
+		if v.Type().Elem() == bigIntType {
+			return BigInt(v.Interface().(*big.Int)), nil
+		}
+		d, err := marshal(v.Elem(), options, depth+1)
+		if err != nil {
+			return Data{}, err
+		}
+		return Constr(0, d), nil
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return Data{}, fmt.Errorf("failed to marshal plutus data: nil interface")
+		}
+		return marshal(v.Elem(), options, depth+1)
+
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			return Bytes(b), nil
+		}
+		items := make([]Data, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			item, err := marshal(v.Index(i), fieldOptions{}, depth+1)
+			if err != nil {
+				return Data{}, err
+			}
+			items = append(items, item)
+		}
+		return List(items...), nil
+
+	case reflect.Map:
+		type entry struct {
+			key  []byte
+			pair Pair
+		}
+		entries := make([]entry, 0, v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			key, err := marshal(iter.Key(), fieldOptions{}, depth+1)
+			if err != nil {
+				return Data{}, err
+			}
+			value, err := marshal(iter.Value(), fieldOptions{}, depth+1)
+			if err != nil {
+				return Data{}, err
+			}
+			encoded, err := key.Encode()
+			if err != nil {
+				return Data{}, err
+			}
+			entries = append(entries, entry{key: encoded, pair: Pair{Key: key, Value: value}})
+		}
+		sort.Slice(entries, func(i, j int) bool { return bytes.Compare(entries[i].key, entries[j].key) < 0 })
+
+		pairs := make([]Pair, 0, len(entries))
+		for _, e := range entries {
+			pairs = append(pairs, e.pair)
+		}
+		return Map(pairs...), nil
+
+	case reflect.Struct:
+		info, err := getStructInfo(v.Type())
+		if err != nil {
+			return Data{}, fmt.Errorf("failed to marshal plutus data: %w", err)
+		}
+		fields := make([]Data, 0, len(info.fields))
+		for i, index := range info.fields {
+			field, err := marshal(v.Field(index), info.options[i], depth+1)
+			if err != nil {
+				return Data{}, fmt.Errorf("failed to marshal %v.%v: %w", v.Type(), v.Type().Field(index).Name, err)
+			}
+			fields = append(fields, field)
+		}
+		return Constr(info.constructor, fields...), nil
+
+	default:
+		return Data{}, fmt.Errorf("failed to marshal plutus data: unsupported type, %v", v.Type())
+	}
+}
+
+func unmarshal(d Data, v reflect.Value, options fieldOptions, depth int) error {
+	if depth > maxDepth {
+		return fmt.Errorf("failed to unmarshal plutus data: exceeded max depth, %v", maxDepth)
+	}
+
+	if d.Type == DataTypeInt && d.Int == nil {
+		d.Int = new(big.Int)
+	}
+
+	want := func(t DataType) error {
+		if d.Type != t {
+			return fmt.Errorf("failed to unmarshal plutus data into %v: got %v; want %v", v.Type(), d.Type, t)
+		}
+		return nil
+	}
+
+	switch v.Type() {
+	case dataType:
+		v.Set(reflect.ValueOf(d))
+		return nil
+	case bigIntType:
+		if err := want(DataTypeInt); err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(*new(big.Int).Set(d.Int)))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if err := want(DataTypeInt); err != nil {
+			return err
+		}
+		if !d.Int.IsInt64() || v.OverflowInt(d.Int.Int64()) {
+			return fmt.Errorf("failed to unmarshal plutus data: %v overflows %v", d.Int, v.Type())
+		}
+		v.SetInt(d.Int.Int64())
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if err := want(DataTypeInt); err != nil {
+			return err
+		}
+		if !d.Int.IsUint64() || v.OverflowUint(d.Int.Uint64()) {
+			return fmt.Errorf("failed to unmarshal plutus data: %v overflows %v", d.Int, v.Type())
+		}
+		v.SetUint(d.Int.Uint64())
+
+	case reflect.Bool:
+		if err := want(DataTypeConstr); err != nil {
+			return err
+		}
+		if d.Constructor > 1 || len(d.Fields) > 0 {
+			return fmt.Errorf("failed to unmarshal plutus data: got constr %v; want bool", d.Constructor)
+		}
+		v.SetBool(d.Constructor == 1)
+
+	case reflect.String:
+		if err := want(DataTypeBytes); err != nil {
+			return err
+		}
+		if options.hex {
+			v.SetString(hex.EncodeToString(d.Bytes))
+		} else {
+			v.SetString(string(d.Bytes))
+		}
+
+	case reflect.Ptr:
+		if v.Type().Elem() == bigIntType {
+			if err := want(DataTypeInt); err != nil {
+				return err
+			}
+			v.Set(reflect.ValueOf(new(big.Int).Set(d.Int)))
+			return nil
+		}
+		if err := want(DataTypeConstr); err != nil {
+			return err
+		}
+		switch {
+		case d.Constructor == 1 && len(d.Fields) == 0:
+			v.Set(reflect.Zero(v.Type()))
+		case d.Constructor == 0 && len(d.Fields) == 1:
+			elem := reflect.New(v.Type().Elem())
+			if err := unmarshal(d.Fields[0], elem.Elem(), options, depth+1); err != nil {
+				return err
+			}
+			v.Set(elem)
+		default:
+			return fmt.Errorf("failed to unmarshal plutus data: got constr %v; want maybe", d.Constructor)
+		}
+
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return fmt.Errorf("failed to unmarshal plutus data: unsupported type, %v", v.Type())
+		}
+		v.Set(reflect.ValueOf(d))
+
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			if err := want(DataTypeBytes); err != nil {
+				return err
+			}
+			b := reflect.MakeSlice(v.Type(), len(d.Bytes), len(d.Bytes))
+			reflect.Copy(b, reflect.ValueOf(d.Bytes))
+			v.Set(b)
+			return nil
+		}
+		if err := want(DataTypeList); err != nil {
+			return err
+		}
+		items := reflect.MakeSlice(v.Type(), len(d.Fields), len(d.Fields))
+		for i, item := range d.Fields {
+			if err := unmarshal(item, items.Index(i), fieldOptions{}, depth+1); err != nil {
+				return err
+			}
+		}
+		v.Set(items)
+
+	case reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			if err := want(DataTypeBytes); err != nil {
+				return err
+			}
+			if len(d.Bytes) != v.Len() {
+				return fmt.Errorf("failed to unmarshal plutus data: got %v bytes; want %v", len(d.Bytes), v.Len())
+			}
+			reflect.Copy(v, reflect.ValueOf(d.Bytes))
+			return nil
+		}
+		if err := want(DataTypeList); err != nil {
+			return err
+		}
+		if len(d.Fields) != v.Len() {
+			return fmt.Errorf("failed to unmarshal plutus data: got %v items; want %v", len(d.Fields), v.Len())
+		}
+		for i, item := range d.Fields {
+			if err := unmarshal(item, v.Index(i), fieldOptions{}, depth+1); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Map:
+		if err := want(DataTypeMap); err != nil {
+			return err
+		}
+		m := reflect.MakeMapWithSize(v.Type(), len(d.Map))
+		for _, pair := range d.Map {
+			key := reflect.New(v.Type().Key()).Elem()
+			if err := unmarshal(pair.Key, key, fieldOptions{}, depth+1); err != nil {
+				return err
+			}
+			value := reflect.New(v.Type().Elem()).Elem()
+			if err := unmarshal(pair.Value, value, fieldOptions{}, depth+1); err != nil {
+				return err
+			}
+			m.SetMapIndex(key, value)
+		}
+		v.Set(m)
+
+	case reflect.Struct:
+		if err := want(DataTypeConstr); err != nil {
+			return err
+		}
+		info, err := getStructInfo(v.Type())
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal plutus data: %w", err)
+		}
+		if d.Constructor != info.constructor {
+			return fmt.Errorf("failed to unmarshal plutus data into %v: got constr %v; want %v", v.Type(), d.Constructor, info.constructor)
+		}
+		if len(d.Fields) != len(info.fields) {
+			return fmt.Errorf("failed to unmarshal plutus data into %v: got %v fields; want %v", v.Type(), len(d.Fields), len(info.fields))
+		}
+		for i, index := range info.fields {
+			if err := unmarshal(d.Fields[i], v.Field(index), info.options[i], depth+1); err != nil {
+				return fmt.Errorf("failed to unmarshal %v.%v: %w", v.Type(), v.Type().Field(index).Name, err)
+			}
+		}
+
+	default:
+		return fmt.Errorf("failed to unmarshal plutus data: unsupported type, %v", v.Type())
+	}
+
+	return nil
+}
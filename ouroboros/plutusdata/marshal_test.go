@@ -0,0 +1,188 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plutusdata
+
+import (
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type credential struct {
+	_       struct{} `plutus:"constr=1"`
+	KeyHash string   `plutus:",hex"`
+}
+
+type order struct {
+	Owner    credential
+	Price    *big.Int
+	Quantity uint64
+	Name     string
+	Tags     []string
+	Limits   map[string]int64
+	Deadline *int64
+	Partial  bool
+	Extra    Data
+	ignored  int
+	Skipped  string `plutus:"-"`
+}
+
+func TestMarshal(t *testing.T) {
+	deadline := int64(1700000000000)
+	v := order{
+		Owner:    credential{KeyHash: "a1b2"},
+		Price:    new(big.Int).Lsh(big.NewInt(1), 70),
+		Quantity: 3,
+		Name:     "token",
+		Tags:     []string{"a", "b"},
+		Limits:   map[string]int64{"b": 2, "a": 1},
+		Deadline: &deadline,
+		Partial:  true,
+		Extra:    Constr(4),
+		ignored:  9,
+		Skipped:  "skip",
+	}
+
+	d, err := Marshal(v)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	want := Constr(0,
+		Constr(1, Bytes([]byte{0xa1, 0xb2})),
+		BigInt(v.Price),
+		Int(3),
+		Bytes([]byte("token")),
+		List(Bytes([]byte("a")), Bytes([]byte("b"))),
+		Map(
+			Pair{Key: Bytes([]byte("a")), Value: Int(1)},
+			Pair{Key: Bytes([]byte("b")), Value: Int(2)},
+		),
+		Constr(0, Int(deadline)),
+		Constr(1),
+		Constr(4),
+	)
+	if !reflect.DeepEqual(d, want) {
+		t.Fatalf("got %v; want %v", d, want)
+	}
+
+	encoded, err := d.Encode()
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	decoded, err := Decode(encoded)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	var got order
+	if err := Unmarshal(decoded, &got); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	v.ignored, v.Skipped = 0, ""
+	if !reflect.DeepEqual(got, v) {
+		t.Fatalf("got %#v; want %#v", got, v)
+	}
+
+	t.Run("nothing", func(t *testing.T) {
+		var deadline *int64
+		d, err := Marshal(deadline)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := d, Constr(1); !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+
+		deadline = new(int64)
+		if err := Unmarshal(d, &deadline); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if deadline != nil {
+			t.Fatalf("got %v; want nil", *deadline)
+		}
+	})
+
+	t.Run("interface", func(t *testing.T) {
+		var got interface{}
+		if err := UnmarshalHex("d87980", &got); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if want := Constr(0); !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+}
+
+func TestUnmarshal_Errors(t *testing.T) {
+	testCases := map[string]struct {
+		Data Data
+		Into interface{}
+		Want string
+	}{
+		"constructor": {
+			Data: Constr(0, Bytes(nil)),
+			Into: &credential{},
+			Want: "got constr 0; want 1",
+		},
+		"fields": {
+			Data: Constr(1),
+			Into: &credential{},
+			Want: "got 0 fields; want 1",
+		},
+		"type": {
+			Data: Bytes(nil),
+			Into: new(int),
+			Want: "got bytes; want int",
+		},
+		"overflow": {
+			Data: Int(256),
+			Into: new(uint8),
+			Want: "overflows uint8",
+		},
+		"negative uint": {
+			Data: Int(-1),
+			Into: new(uint64),
+			Want: "overflows uint64",
+		},
+		"bool": {
+			Data: Constr(2),
+			Into: new(bool),
+			Want: "want bool",
+		},
+		"nested": {
+			Data: Constr(0, Constr(1, Int(1))),
+			Into: &struct{ Owner credential }{},
+			Want: "Owner",
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			err := Unmarshal(tc.Data, tc.Into)
+			if err == nil {
+				t.Fatalf("got nil; want error")
+			}
+			if !strings.Contains(err.Error(), tc.Want) {
+				t.Fatalf("got %v; want %v", err, tc.Want)
+			}
+		})
+	}
+
+	if err := Unmarshal(Int(1), 1); err == nil {
+		t.Fatalf("got nil; want error")
+	}
+}
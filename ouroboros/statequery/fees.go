@@ -0,0 +1,149 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statequery
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/savaki/ogmigo/ouroboros/chainsync"
+	"github.com/savaki/ogmigo/ouroboros/chainsync/num"
+)
+
+// constants of the min utxo rules.  Sizes before babbage are in 8 byte words
+const (
+	utxoEntryOverhead       = 160 // babbage; bytes added to the size of each output
+	utxoEntrySizeWithoutVal = 27  // mary and alonzo
+	adaOnlyUtxoSize         = 27  // mary
+	adaOnlyValueSize        = 2   // alonzo
+	dataHashSize            = 10  // alonzo
+	policyIDSize            = 28
+)
+
+// MinUtxo returns the minimum coins the output must hold.  The rule is
+// selected by the parameters present; coinsPerUtxoByte from babbage,
+// coinsPerUtxoWord in alonzo and minUtxoValue in shelley and mary.  As the
+// size of the output depends on its coins, the result is the least amount
+// that satisfies the rule once the output holds it
+func (p ProtocolParameters) MinUtxo(txOut chainsync.TxOut) (num.Int, error) {
+	switch {
+	case p.CoinsPerUtxoByte != nil && !p.CoinsPerUtxoByte.IsZero():
+		return p.minUtxoBabbage(txOut)
+
+	case p.CoinsPerUtxoWord != nil:
+		size := utxoEntrySizeWithoutVal + valueSize(txOut.Value, adaOnlyValueSize)
+		if txOut.DatumHash != "" || txOut.Datum != "" {
+			size += dataHashSize
+		}
+		return p.CoinsPerUtxoWord.Mul(num.Uint64(size)), nil
+
+	case p.MinUtxoValue != nil:
+		if len(txOut.Value.AssetIDs()) == 0 {
+			return *p.MinUtxoValue, nil
+		}
+		coinsPerWord := p.MinUtxoValue.Div(num.Int64(adaOnlyUtxoSize))
+		minUtxo := coinsPerWord.Mul(num.Uint64(utxoEntrySizeWithoutVal + valueSize(txOut.Value, 0)))
+		if minUtxo.Cmp(*p.MinUtxoValue) < 0 {
+			return *p.MinUtxoValue, nil
+		}
+		return minUtxo, nil
+
+	default:
+		return num.Int{}, fmt.Errorf("failed to compute min utxo: protocol parameters missing coinsPerUtxoByte, coinsPerUtxoWord and minUtxoValue")
+	}
+}
+
+func (p ProtocolParameters) minUtxoBabbage(txOut chainsync.TxOut) (num.Int, error) {
+	// the required coins grow with the size of the encoded coins so increase
+	// them until the output pays for itself; converges within a few rounds
+	var coins num.Int
+	for {
+		txOut.Value.Coins = coins
+		data, err := chainsync.EncodeTxOut(txOut)
+		if err != nil {
+			return num.Int{}, fmt.Errorf("failed to compute min utxo: %w", err)
+		}
+		required := p.CoinsPerUtxoByte.Mul(num.Int64(int64(utxoEntryOverhead + len(data))))
+		if required.Cmp(coins) <= 0 {
+			return coins, nil
+		}
+		coins = required
+	}
+}
+
+// valueSize returns the size in words of a value as defined by mary
+func valueSize(value chainsync.Value, adaOnly uint64) uint64 {
+	ids := value.AssetIDs()
+	if len(ids) == 0 {
+		return adaOnly
+	}
+
+	var nameLengths uint64
+	for _, id := range ids {
+		nameLengths += uint64(len(id.AssetName()) / 2)
+	}
+	policies := uint64(len(value.PolicyIDs()))
+	bytes := uint64(len(ids))*12 + nameLengths + policies*policyIDSize
+	return 6 + (bytes+7)/8
+}
+
+// MinFee returns the linear fee, minFeeCoefficient * size + minFeeConstant,
+// for a tx of the given size in bytes.  Script execution fees are additional;
+// see ScriptFee
+func (p ProtocolParameters) MinFee(txSize uint64) num.Int {
+	return num.Uint64(p.MinFeeCoefficient).Mul(num.Uint64(txSize)).Add(p.MinFeeConstant)
+}
+
+// ScriptFee returns the fee for executing scripts with the given execution
+// units; the total of the units priced and rounded up as the ledger does
+func (p ProtocolParameters) ScriptFee(units ...ExecutionUnits) (num.Int, error) {
+	if p.Prices == nil {
+		return num.Int{}, fmt.Errorf("failed to compute script fee: protocol parameters missing prices")
+	}
+	memoryPrice, ok := p.Prices.Memory.Rat()
+	if !ok {
+		return num.Int{}, fmt.Errorf("failed to compute script fee: invalid memory price, %v", p.Prices.Memory)
+	}
+	stepsPrice, ok := p.Prices.Steps.Rat()
+	if !ok {
+		return num.Int{}, fmt.Errorf("failed to compute script fee: invalid steps price, %v", p.Prices.Steps)
+	}
+
+	var memory, steps big.Int
+	for _, u := range units {
+		memory.Add(&memory, new(big.Int).SetUint64(u.Memory))
+		steps.Add(&steps, new(big.Int).SetUint64(u.Steps))
+	}
+
+	fee := new(big.Rat).Mul(memoryPrice, new(big.Rat).SetInt(&memory))
+	fee.Add(fee, new(big.Rat).Mul(stepsPrice, new(big.Rat).SetInt(&steps)))
+	return ceil(fee), nil
+}
+
+// MinCollateral returns the collateral required for a tx paying the given
+// fee; collateralPercentage of the fee rounded up
+func (p ProtocolParameters) MinCollateral(fee num.Int) num.Int {
+	collateral := new(big.Rat).SetFrac(fee.Mul(num.Uint64(p.CollateralPercentage)).BigInt(), big.NewInt(100))
+	return ceil(collateral)
+}
+
+// ceil returns the least integer greater than or equal to r
+func ceil(r *big.Rat) num.Int {
+	quo, rem := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	if rem.Sign() > 0 {
+		quo.Add(quo, big.NewInt(1))
+	}
+	return num.Int(*quo)
+}
@@ -0,0 +1,126 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statequery
+
+import (
+	"testing"
+
+	"github.com/savaki/ogmigo/ouroboros/chainsync"
+	"github.com/savaki/ogmigo/ouroboros/chainsync/num"
+)
+
+const (
+	testBaseAddress       = "addr1qx2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzer3n0d3vllmyqwsx5wktcd8cc3sq835lu7drv2xwl2wywfgse35a3x"
+	testEnterpriseAddress = "addr1vx2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzers66hrl8"
+	testPolicyID          = "a0028f350aaabe0545fdcb56b039bfb08e4bb4d8c4d7c3c7d481c235"
+)
+
+func TestProtocolParameters_MinUtxo(t *testing.T) {
+	var alonzo, babbage ProtocolParameters
+	decodeFixture(t, "currentProtocolParameters", &alonzo)
+	decodeFixture(t, "currentProtocolParametersBabbage", &babbage)
+	minUtxoValue := num.Int64(1000000)
+	mary := ProtocolParameters{MinUtxoValue: &minUtxoValue}
+
+	adaOnly := chainsync.TxOut{Address: testBaseAddress, Value: chainsync.Value{Coins: num.Int64(5000000)}}
+	withDatum := chainsync.TxOut{Address: testBaseAddress, DatumHash: "923918e403bf43c34b4ef6b48eb2ee04babed17320d8d1b9ff9ad086e86f44ec"}
+	withAsset := chainsync.TxOut{
+		Address: testBaseAddress,
+		Value: chainsync.Value{
+			Assets: map[chainsync.AssetID]num.Int{chainsync.AssetID(testPolicyID): num.Int64(1)},
+		},
+	}
+
+	testCases := map[string]struct {
+		Params ProtocolParameters
+		TxOut  chainsync.TxOut
+		Want   int64
+	}{
+		"babbage ada only":            {Params: babbage, TxOut: adaOnly, Want: 969750},
+		"babbage enterprise ada only": {Params: babbage, TxOut: chainsync.TxOut{Address: testEnterpriseAddress}, Want: 849070},
+		"alonzo ada only":             {Params: alonzo, TxOut: adaOnly, Want: 999978},
+		"alonzo datum hash":           {Params: alonzo, TxOut: withDatum, Want: 1344798},
+		"alonzo asset":                {Params: alonzo, TxOut: withAsset, Want: 1310316},
+		"mary ada only":               {Params: mary, TxOut: adaOnly, Want: 1000000},
+		"mary asset":                  {Params: mary, TxOut: withAsset, Want: 1407406},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			got, err := tc.Params.MinUtxo(tc.TxOut)
+			if err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			if got, want := got.Int64(), tc.Want; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+		})
+	}
+
+	t.Run("babbage pays for itself", func(t *testing.T) {
+		minUtxo, err := babbage.MinUtxo(withAsset)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		txOut := withAsset
+		txOut.Value.Coins = minUtxo
+		data, err := chainsync.EncodeTxOut(txOut)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := minUtxo.Int64(), int64(160+len(data))*4310; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	if _, err := (ProtocolParameters{}).MinUtxo(adaOnly); err == nil {
+		t.Fatalf("got nil; want error")
+	}
+}
+
+func TestProtocolParameters_Fees(t *testing.T) {
+	var params ProtocolParameters
+	decodeFixture(t, "currentProtocolParametersBabbage", &params)
+
+	if got, want := params.MinFee(300).Int64(), int64(44*300+155381); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	redeemer := chainsync.Redeemer{Purpose: chainsync.RedeemerSpend, ExecutionUnits: chainsync.ExecutionUnits{Memory: 1000000, Steps: 500000000}}
+	fee, err := params.ScriptFee(redeemer.ExecutionUnits)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := fee.Int64(), int64(57700+36050); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	// units are totalled before rounding up
+	fee, err = params.ScriptFee(ExecutionUnits{Memory: 1, Steps: 1}, ExecutionUnits{Memory: 1, Steps: 1})
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := fee.Int64(), int64(1); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	if _, err := (ProtocolParameters{}).ScriptFee(); err == nil {
+		t.Fatalf("got nil; want error")
+	}
+
+	if got, want := params.MinCollateral(num.Int64(170001)).Int64(), int64(255002); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
@@ -1,10 +1,13 @@
 package statequery
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
-	"github.com/savaki/ogmigo/ouroboros/chainsync"
 	"time"
+
+	"github.com/savaki/ogmigo/ouroboros/chainsync"
+	"github.com/savaki/ogmigo/ouroboros/chainsync/num"
 )
 
 type EraStart struct {
@@ -15,15 +18,15 @@ type EraStart struct {
 
 func (e *EraStart) UnmarshalJSON(data []byte) (err error) {
 	var content struct {
-		Time  string `json:"time,omitempty"`
-		Slot  uint64 `json:"slot,omit"`
-		Epoch uint64 `json:"epoch,omit"`
+		Time  json.RawMessage `json:"time,omitempty"`
+		Slot  uint64          `json:"slot,omit"`
+		Epoch uint64          `json:"epoch,omit"`
 	}
 	if err := json.Unmarshal(data, &content); err != nil {
 		return fmt.Errorf("failed to unmarshal EraStart: %w", err)
 	}
 
-	t, err := time.ParseDuration(content.Time)
+	t, err := parseSeconds(content.Time)
 	if err != nil {
 		return fmt.Errorf("failed to unmarshal EraStart: %w", err)
 	}
@@ -37,6 +40,45 @@ func (e *EraStart) UnmarshalJSON(data []byte) (err error) {
 	return nil
 }
 
+// parseSeconds accepts either a duration string e.g. "20s", a number of seconds
+// or the v6 encoding e.g. {"seconds": 20} or {"milliseconds": 20000}
+func parseSeconds(data json.RawMessage) (time.Duration, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return 0, nil
+	}
+	if data[0] == '{' {
+		var v struct {
+			Seconds      *float64 `json:"seconds"`
+			Milliseconds *float64 `json:"milliseconds"`
+		}
+		if err := json.Unmarshal(data, &v); err != nil {
+			return 0, err
+		}
+		switch {
+		case v.Seconds != nil:
+			return time.Duration(*v.Seconds * float64(time.Second)), nil
+		case v.Milliseconds != nil:
+			return time.Duration(*v.Milliseconds * float64(time.Millisecond)), nil
+		default:
+			return 0, fmt.Errorf("unable to parse duration, %v", string(data))
+		}
+	}
+	if data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return 0, err
+		}
+		return time.ParseDuration(s)
+	}
+
+	var seconds float64
+	if err := json.Unmarshal(data, &seconds); err != nil {
+		return 0, err
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
 type Utxo struct {
 	TxIn  chainsync.TxIn
 	TxOut chainsync.TxOut
@@ -79,3 +121,177 @@ func (u *Utxo) UnmarshalJSON(data []byte) (err error) {
 
 	return nil
 }
+
+// Ratio holds a rational number encoded as numerator/denominator e.g. "721/10000000"
+type Ratio = chainsync.Ratio
+
+// DelegationsAndRewards describes the delegation and reward balance of a stake key
+type DelegationsAndRewards struct {
+	Delegate string  `json:"delegate,omitempty"` // Delegate pool id; blank if not delegated
+	Rewards  num.Int `json:"rewards,omitempty"`  // Rewards in lovelace
+}
+
+// EraSummary describes the bounds and slotting parameters of an era
+type EraSummary struct {
+	Start      EraStart      `json:"start"`
+	End        *EraStart     `json:"end,omitempty"` // End is nil for the current era
+	Parameters EraParameters `json:"parameters"`
+}
+
+type EraParameters struct {
+	EpochLength uint64        `json:"epochLength"`
+	SlotLength  time.Duration `json:"slotLength"`
+	SafeZone    uint64        `json:"safeZone"`
+}
+
+func (e *EraParameters) UnmarshalJSON(data []byte) error {
+	var content struct {
+		EpochLength uint64          `json:"epochLength"`
+		SlotLength  json.RawMessage `json:"slotLength"`
+		SafeZone    uint64          `json:"safeZone"`
+	}
+	if err := json.Unmarshal(data, &content); err != nil {
+		return fmt.Errorf("failed to unmarshal EraParameters: %w", err)
+	}
+
+	slotLength, err := parseSeconds(content.SlotLength)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal EraParameters: slotLength: %w", err)
+	}
+
+	*e = EraParameters{
+		EpochLength: content.EpochLength,
+		SlotLength:  slotLength,
+		SafeZone:    content.SafeZone,
+	}
+
+	return nil
+}
+
+// GenesisConfig holds the shelley genesis configuration
+type GenesisConfig struct {
+	SystemStart            time.Time          `json:"systemStart"`
+	NetworkMagic           uint32             `json:"networkMagic"`
+	Network                string             `json:"network"`
+	ActiveSlotsCoefficient Ratio              `json:"activeSlotsCoefficient"`
+	SecurityParameter      uint64             `json:"securityParameter"`
+	EpochLength            uint64             `json:"epochLength"`
+	SlotsPerKesPeriod      uint64             `json:"slotsPerKesPeriod"`
+	MaxKesEvolutions       uint64             `json:"maxKesEvolutions"`
+	SlotLength             uint64             `json:"slotLength"` // SlotLength in seconds
+	UpdateQuorum           uint64             `json:"updateQuorum"`
+	MaxLovelaceSupply      num.Int            `json:"maxLovelaceSupply"`
+	ProtocolParameters     ProtocolParameters `json:"protocolParameters"`
+}
+
+// NonMyopicMemberRewards maps each requested amount or credential to the
+// rewards, in lovelace, expected from each pool
+type NonMyopicMemberRewards map[string]map[string]num.Int
+
+// PoolDistribution describes the stake delegated to a pool
+type PoolDistribution struct {
+	Stake Ratio  `json:"stake"` // Stake as a fraction of total stake
+	VRF   string `json:"vrf"`
+}
+
+// PoolMetadata references the off chain metadata of a pool
+type PoolMetadata = chainsync.PoolMetadata
+
+// PoolParameters holds the registered parameters of a stake pool
+type PoolParameters = chainsync.PoolParameters
+
+// PoolRanking holds the desirability of a pool
+type PoolRanking struct {
+	Efficiency float64 `json:"efficiency"`
+}
+
+// Relay describes how to reach a stake pool
+type Relay = chainsync.Relay
+
+// CostModel maps the name of each plutus builtin cost parameter to its value
+type CostModel map[string]int64
+
+// ExecutionUnits measure the resources consumed by plutus scripts; the same
+// type as the execution units of a chainsync.Redeemer
+type ExecutionUnits = chainsync.ExecutionUnits
+
+// Prices of execution units in lovelace per unit
+type Prices struct {
+	Memory Ratio `json:"memory"`
+	Steps  Ratio `json:"steps"`
+}
+
+// ProtocolParameters holds the protocol parameters from shelley onwards.
+// Parameters introduced or retired in later eras are left as their zero value
+// when absent
+type ProtocolParameters struct {
+	// shelley
+	MinFeeCoefficient         uint64                     `json:"minFeeCoefficient"`
+	MinFeeConstant            num.Int                    `json:"minFeeConstant"`
+	MaxBlockBodySize          uint64                     `json:"maxBlockBodySize"`
+	MaxBlockHeaderSize        uint64                     `json:"maxBlockHeaderSize"`
+	MaxTxSize                 uint64                     `json:"maxTxSize"`
+	StakeKeyDeposit           num.Int                    `json:"stakeKeyDeposit"`
+	PoolDeposit               num.Int                    `json:"poolDeposit"`
+	PoolRetirementEpochBound  uint64                     `json:"poolRetirementEpochBound"`
+	DesiredNumberOfPools      uint64                     `json:"desiredNumberOfPools"`
+	PoolInfluence             Ratio                      `json:"poolInfluence"`
+	MonetaryExpansion         Ratio                      `json:"monetaryExpansion"`
+	TreasuryExpansion         Ratio                      `json:"treasuryExpansion"`
+	DecentralizationParameter Ratio                      `json:"decentralizationParameter,omitempty"` // removed in babbage
+	ExtraEntropy              json.RawMessage            `json:"extraEntropy,omitempty"`              // removed in babbage
+	ProtocolVersion           *chainsync.ProtocolVersion `json:"protocolVersion,omitempty"`
+	MinUtxoValue              *num.Int                   `json:"minUtxoValue,omitempty"` // replaced by coinsPerUtxoWord in alonzo
+	MinPoolCost               num.Int                    `json:"minPoolCost"`
+
+	// alonzo
+	CoinsPerUtxoWord                *num.Int             `json:"coinsPerUtxoWord,omitempty"` // replaced by coinsPerUtxoByte in babbage
+	MaxValueSize                    uint64               `json:"maxValueSize,omitempty"`
+	CollateralPercentage            uint64               `json:"collateralPercentage,omitempty"`
+	MaxCollateralInputs             uint64               `json:"maxCollateralInputs,omitempty"`
+	CostModels                      map[string]CostModel `json:"costModels,omitempty"`
+	Prices                          *Prices              `json:"prices,omitempty"`
+	MaxExecutionUnitsPerTransaction *ExecutionUnits      `json:"maxExecutionUnitsPerTransaction,omitempty"`
+	MaxExecutionUnitsPerBlock       *ExecutionUnits      `json:"maxExecutionUnitsPerBlock,omitempty"`
+
+	// babbage
+	CoinsPerUtxoByte *num.Int `json:"coinsPerUtxoByte,omitempty"`
+
+	// ogmios v6
+	MinUtxoDepositConstant *num.Int           `json:"minUtxoDepositConstant,omitempty"`
+	PlutusCostModels       map[string][]int64 `json:"plutusCostModels,omitempty"` // ordered by parameter; CostModels holds the named v5 form
+}
+
+// RewardsProvenance describes how rewards were computed for the current epoch
+type RewardsProvenance struct {
+	EpochLength               uint64                           `json:"epochLength"`
+	PoolMints                 map[string]uint64                `json:"poolMints"`
+	MaxLovelaceSupply         num.Int                          `json:"maxLovelaceSupply"`
+	DecentralizationParameter Ratio                            `json:"decentralizationParameter"`
+	TotalMintedBlocks         int64                            `json:"totalMintedBlocks"`
+	TotalExpectedBlocks       int64                            `json:"totalExpectedBlocks"`
+	Incentives                num.Int                          `json:"incentives"`
+	RewardsGap                num.Int                          `json:"rewardsGap"`
+	AvailableRewards          num.Int                          `json:"availableRewards"`
+	TotalRewards              num.Int                          `json:"totalRewards"`
+	TreasuryTax               num.Int                          `json:"treasuryTax"`
+	ActiveStake               num.Int                          `json:"activeStake"`
+	Pools                     map[string]RewardsProvenancePool `json:"pools,omitempty"`
+	DesiredNumberOfPools      uint64                           `json:"desiredNumberOfPools,omitempty"`
+	PoolInfluence             Ratio                            `json:"poolInfluence,omitempty"`
+}
+
+// RewardsProvenancePool describes the reward inputs of a single pool
+type RewardsProvenancePool struct {
+	Stake                  num.Int                         `json:"stake"`
+	OwnerStake             num.Int                         `json:"ownerStake"`
+	ApproximatePerformance float64                         `json:"approximatePerformance"`
+	PoolParameters         RewardsProvenancePoolParameters `json:"poolParameters"`
+}
+
+// RewardsProvenancePoolParameters holds the subset of pool parameters that influence rewards
+type RewardsProvenancePoolParameters struct {
+	Cost   num.Int `json:"cost"`
+	Margin Ratio   `json:"margin"`
+	Pledge num.Int `json:"pledge"`
+}
@@ -1,11 +1,17 @@
 package statequery
 
 import (
+	"bytes"
 	"encoding/json"
-	"github.com/savaki/ogmigo/ouroboros/chainsync"
-	"github.com/savaki/ogmigo/ouroboros/chainsync/num"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
+
+	"github.com/savaki/ogmigo/ouroboros/chainsync"
+	"github.com/savaki/ogmigo/ouroboros/chainsync/num"
 )
 
 func TestUtxo_MarshalJSON(t *testing.T) {
@@ -38,3 +44,289 @@ func TestUtxo_MarshalJSON(t *testing.T) {
 		t.Fatalf("got %#v; want %#v", got, want)
 	}
 }
+
+// decodeFixture decodes the recorded query result, disallowing unknown fields
+func decodeFixture(t *testing.T, query string, v interface{}) {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", query+".json"))
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		t.Fatalf("got %v; want nil: failed to decode fixture, %v", err, query)
+	}
+}
+
+func TestDelegationsAndRewards(t *testing.T) {
+	var got map[string]DelegationsAndRewards
+	decodeFixture(t, "delegationsAndRewards", &got)
+
+	v := got["7c16240714ea0e12b41a914f2945784ac494bb19573f0ca61a08afa8"]
+	if got, want := v.Delegate, "pool1m5947rydk4n0ywe6ctlav0ztt632lcwjef7fsy93sflz7ctcx6z"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := v.Rewards.Int64(), int64(7022424); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestEraSummary(t *testing.T) {
+	var got []EraSummary
+	decodeFixture(t, "eraSummaries", &got)
+
+	if got, want := len(got), 3; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := got[0].Parameters.SlotLength, 20*time.Second; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := got[1].Start.Time, 89856000*time.Second; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := got[1].End.Epoch, uint64(236); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got[2].End != nil {
+		t.Fatalf("got %v; want nil", got[2].End)
+	}
+}
+
+func TestEraStart_UnmarshalJSON(t *testing.T) {
+	for _, data := range []string{`{"time":"30s","slot":1,"epoch":2}`, `{"time":30,"slot":1,"epoch":2}`} {
+		var got EraStart
+		if err := json.Unmarshal([]byte(data), &got); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		want := EraStart{Time: 30 * time.Second, Slot: 1, Epoch: 2}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %#v; want %#v", got, want)
+		}
+	}
+}
+
+func TestGenesisConfig(t *testing.T) {
+	var got GenesisConfig
+	decodeFixture(t, "genesisConfig", &got)
+
+	if got, want := got.SystemStart, time.Date(2017, 9, 23, 21, 44, 51, 0, time.UTC); !got.Equal(want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := got.NetworkMagic, uint32(764824073); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := got.ActiveSlotsCoefficient.Float64(), 0.05; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestProtocolParameters(t *testing.T) {
+	t.Run("alonzo", func(t *testing.T) {
+		var got ProtocolParameters
+		decodeFixture(t, "currentProtocolParameters", &got)
+
+		if got, want := got.MinFeeConstant.Int64(), int64(155381); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := got.CoinsPerUtxoWord.Int64(), int64(34482); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got.CoinsPerUtxoByte != nil {
+			t.Fatalf("got %v; want nil", got.CoinsPerUtxoByte)
+		}
+		if got, want := got.Prices.Steps, Ratio("721/10000000"); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := got.MaxExecutionUnitsPerTransaction.Steps, uint64(10000000000); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := got.CostModels["plutus:v1"]["addInteger-cpu-arguments-intercept"], int64(197209); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := got.ProtocolVersion.Major, uint32(6); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("babbage", func(t *testing.T) {
+		var got ProtocolParameters
+		decodeFixture(t, "currentProtocolParametersBabbage", &got)
+
+		if got, want := got.CoinsPerUtxoByte.Int64(), int64(4310); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got.CoinsPerUtxoWord != nil {
+			t.Fatalf("got %v; want nil", got.CoinsPerUtxoWord)
+		}
+		if got, want := len(got.CostModels), 2; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got.DecentralizationParameter != "" {
+			t.Fatalf("got %v; want blank", got.DecentralizationParameter)
+		}
+	})
+
+	t.Run("shelley", func(t *testing.T) {
+		var got GenesisConfig
+		decodeFixture(t, "genesisConfig", &got)
+
+		if got, want := got.ProtocolParameters.MinUtxoValue.Int64(), int64(1000000); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := got.ProtocolParameters.DecentralizationParameter.Float64(), 1.0; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+}
+
+func TestNonMyopicMemberRewards(t *testing.T) {
+	var got NonMyopicMemberRewards
+	decodeFixture(t, "nonMyopicMemberRewards", &got)
+
+	v := got["1000000000"]["pool1m5947rydk4n0ywe6ctlav0ztt632lcwjef7fsy93sflz7ctcx6z"]
+	if got, want := v.Int64(), int64(1235342); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestPoolParameters(t *testing.T) {
+	var got map[string]PoolParameters
+	decodeFixture(t, "poolParameters", &got)
+
+	v := got["pool1m5947rydk4n0ywe6ctlav0ztt632lcwjef7fsy93sflz7ctcx6z"]
+	if got, want := v.Margin.Float64(), 0.01; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	want := []Relay{
+		{IPv4: "192.168.0.1", Port: 3001},
+		{Hostname: "relay.example.com", Port: 3001},
+	}
+	if got := v.Relays; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v; want %#v", got, want)
+	}
+	if v.Metadata == nil {
+		t.Fatalf("got nil; want not nil")
+	}
+
+	if v := got["pool1qqqqqdk4zhsjuxxd8jyvwncf5eucfskz0xjjj64fdmlgj735lr9"]; v.Metadata != nil {
+		t.Fatalf("got %v; want nil", v.Metadata)
+	}
+}
+
+func TestPoolsRanking(t *testing.T) {
+	var got map[string]PoolRanking
+	decodeFixture(t, "poolsRanking", &got)
+
+	if got, want := got["pool1m5947rydk4n0ywe6ctlav0ztt632lcwjef7fsy93sflz7ctcx6z"].Efficiency, 0.9987; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestRewardsProvenance(t *testing.T) {
+	var got RewardsProvenance
+	decodeFixture(t, "rewardsProvenance", &got)
+
+	if got, want := got.TotalExpectedBlocks, int64(21600); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := got.ActiveStake.String(), "23130219813447524"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestStakeDistribution(t *testing.T) {
+	var got map[string]PoolDistribution
+	decodeFixture(t, "stakeDistribution", &got)
+
+	stake, ok := got["pool1m5947rydk4n0ywe6ctlav0ztt632lcwjef7fsy93sflz7ctcx6z"].Stake.Rat()
+	if !ok {
+		t.Fatalf("got false; want true")
+	}
+	if want := big.NewRat(25318926327, 22839212003445813); stake.Cmp(want) != 0 {
+		t.Fatalf("got %v; want %v", stake, want)
+	}
+}
+
+func TestProtocolParametersV6(t *testing.T) {
+	var v ProtocolParametersV6
+	decodeFixture(t, "v6/protocolParameters", &v)
+	got := ProtocolParameters(v)
+
+	if got, want := got.MinFeeConstant.Int64(), int64(155381); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := got.MaxTxSize, uint64(16384); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := got.PoolDeposit.Int64(), int64(500000000); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := got.CoinsPerUtxoByte.Int64(), int64(4310); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := got.Prices.Steps, Ratio("721/10000000"); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := got.MaxExecutionUnitsPerTransaction.Steps, uint64(10000000000); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := got.PlutusCostModels["plutus:v2"][4], int64(1000); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got.CostModels != nil {
+		t.Fatalf("got %v; want nil", got.CostModels)
+	}
+	if got, want := got.MinUtxoDepositConstant.Int64(), int64(0); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got.MinUtxoValue != nil {
+		t.Fatalf("got %v; want nil", got.MinUtxoValue)
+	}
+	if got, want := got.ProtocolVersion.Major, uint32(8); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestEraSummaryV6(t *testing.T) {
+	var got []EraSummary
+	decodeFixture(t, "v6/eraSummaries", &got)
+
+	if got, want := got[0].Parameters.SlotLength, 20*time.Second; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := got[1].Start.Time, 89856000*time.Second; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestUtxoV6(t *testing.T) {
+	data := `{"transaction":{"id":"abc"},"index":1,"address":"addr","value":{"ada":{"lovelace":5}},"datumHash":"def"}`
+	var v UtxoV6
+	if err := json.Unmarshal([]byte(data), &v); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	want := Utxo{
+		TxIn: chainsync.TxIn{TxHash: "abc", Index: 1},
+		TxOut: chainsync.TxOut{
+			Address:   "addr",
+			DatumHash: "def",
+			Value:     chainsync.Value{Coins: num.Int64(5)},
+		},
+	}
+	if got := Utxo(v); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v; want %#v", got, want)
+	}
+
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	var got UtxoV6
+	if err := json.Unmarshal(encoded, &got); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if !reflect.DeepEqual(Utxo(got), want) {
+		t.Fatalf("got %#v; want %#v", got, want)
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statequery
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/savaki/ogmigo/ouroboros/chainsync"
+	"github.com/savaki/ogmigo/ouroboros/chainsync/num"
+)
+
+// UtxoV6 decodes the ogmios v6 utxo encoding,
+// {"transaction": {"id": ...}, "index": ..., "address": ..., "value": ...}
+type UtxoV6 Utxo
+
+func (u UtxoV6) MarshalJSON() ([]byte, error) {
+	txIn, err := json.Marshal(chainsync.TxInV6(u.TxIn))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal UtxoV6: %w", err)
+	}
+	txOut, err := json.Marshal(chainsync.TxOutV6(u.TxOut))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal UtxoV6: %w", err)
+	}
+
+	// both halves encode as json objects; merge them into one
+	return append(append(txIn[:len(txIn)-1], ','), txOut[1:]...), nil
+}
+
+func (u *UtxoV6) UnmarshalJSON(data []byte) error {
+	var txIn chainsync.TxInV6
+	if err := json.Unmarshal(data, &txIn); err != nil {
+		return fmt.Errorf("failed to unmarshal UtxoV6: %w", err)
+	}
+	var txOut chainsync.TxOutV6
+	if err := json.Unmarshal(data, &txOut); err != nil {
+		return fmt.Errorf("failed to unmarshal UtxoV6: %w", err)
+	}
+
+	*u = UtxoV6{
+		TxIn:  chainsync.TxIn(txIn),
+		TxOut: chainsync.TxOut(txOut),
+	}
+	return nil
+}
+
+type bytesV6 struct {
+	Bytes uint64 `json:"bytes"`
+}
+
+type executionUnitsV6 struct {
+	Memory uint64 `json:"memory"`
+	CPU    uint64 `json:"cpu"`
+}
+
+func (e *executionUnitsV6) units() *ExecutionUnits {
+	if e == nil {
+		return nil
+	}
+	return &ExecutionUnits{Memory: e.Memory, Steps: e.CPU}
+}
+
+// ProtocolParametersV6 decodes the ogmios v6 protocol parameters encoding.
+// Plutus cost models are returned as arrays by v6 and are keyed by index
+type ProtocolParametersV6 ProtocolParameters
+
+func (p *ProtocolParametersV6) UnmarshalJSON(data []byte) error {
+	var content struct {
+		MinFeeCoefficient             uint64                       `json:"minFeeCoefficient"`
+		MinFeeConstant                chainsync.LovelaceV6         `json:"minFeeConstant"`
+		MaxBlockBodySize              bytesV6                      `json:"maxBlockBodySize"`
+		MaxBlockHeaderSize            bytesV6                      `json:"maxBlockHeaderSize"`
+		MaxTransactionSize            bytesV6                      `json:"maxTransactionSize"`
+		StakeCredentialDeposit        chainsync.LovelaceV6         `json:"stakeCredentialDeposit"`
+		StakePoolDeposit              chainsync.LovelaceV6         `json:"stakePoolDeposit"`
+		StakePoolRetirementEpochBound uint64                       `json:"stakePoolRetirementEpochBound"`
+		DesiredNumberOfStakePools     uint64                       `json:"desiredNumberOfStakePools"`
+		StakePoolPressure             Ratio                        `json:"stakePoolPressure"`
+		MonetaryExpansion             Ratio                        `json:"monetaryExpansion"`
+		TreasuryExpansion             Ratio                        `json:"treasuryExpansion"`
+		FederatedBlockProduction      Ratio                        `json:"federatedBlockProductionRatio"`
+		ExtraEntropy                  json.RawMessage              `json:"extraEntropy"`
+		Version                       *chainsync.ProtocolVersion   `json:"version"`
+		MinUtxoDepositConstant        *chainsync.LovelaceV6        `json:"minUtxoDepositConstant"`
+		MinUtxoDepositCoefficient     *uint64                      `json:"minUtxoDepositCoefficient"`
+		MinStakePoolCost              chainsync.LovelaceV6         `json:"minStakePoolCost"`
+		MaxValueSize                  bytesV6                      `json:"maxValueSize"`
+		CollateralPercentage          uint64                       `json:"collateralPercentage"`
+		MaxCollateralInputs           uint64                       `json:"maxCollateralInputs"`
+		PlutusCostModels              map[string][]int64           `json:"plutusCostModels"`
+		ScriptExecutionPrices         *struct{ Memory, CPU Ratio } `json:"scriptExecutionPrices"`
+		MaxExecutionUnitsPerTx        *executionUnitsV6            `json:"maxExecutionUnitsPerTransaction"`
+		MaxExecutionUnitsPerBlock     *executionUnitsV6            `json:"maxExecutionUnitsPerBlock"`
+	}
+	if err := json.Unmarshal(data, &content); err != nil {
+		return fmt.Errorf("failed to unmarshal ProtocolParametersV6: %w", err)
+	}
+
+	params := ProtocolParameters{
+		MinFeeCoefficient:               content.MinFeeCoefficient,
+		MinFeeConstant:                  num.Int(content.MinFeeConstant),
+		MaxBlockBodySize:                content.MaxBlockBodySize.Bytes,
+		MaxBlockHeaderSize:              content.MaxBlockHeaderSize.Bytes,
+		MaxTxSize:                       content.MaxTransactionSize.Bytes,
+		StakeKeyDeposit:                 num.Int(content.StakeCredentialDeposit),
+		PoolDeposit:                     num.Int(content.StakePoolDeposit),
+		PoolRetirementEpochBound:        content.StakePoolRetirementEpochBound,
+		DesiredNumberOfPools:            content.DesiredNumberOfStakePools,
+		PoolInfluence:                   content.StakePoolPressure,
+		MonetaryExpansion:               content.MonetaryExpansion,
+		TreasuryExpansion:               content.TreasuryExpansion,
+		DecentralizationParameter:       content.FederatedBlockProduction,
+		ExtraEntropy:                    content.ExtraEntropy,
+		ProtocolVersion:                 content.Version,
+		MinPoolCost:                     num.Int(content.MinStakePoolCost),
+		MaxValueSize:                    content.MaxValueSize.Bytes,
+		CollateralPercentage:            content.CollateralPercentage,
+		MaxCollateralInputs:             content.MaxCollateralInputs,
+		MaxExecutionUnitsPerTransaction: content.MaxExecutionUnitsPerTx.units(),
+		MaxExecutionUnitsPerBlock:       content.MaxExecutionUnitsPerBlock.units(),
+	}
+	if v := content.MinUtxoDepositConstant; v != nil {
+		minUtxoDepositConstant := num.Int(*v)
+		params.MinUtxoDepositConstant = &minUtxoDepositConstant
+	}
+	if v := content.MinUtxoDepositCoefficient; v != nil {
+		coinsPerUtxoByte := num.Uint64(*v)
+		params.CoinsPerUtxoByte = &coinsPerUtxoByte
+	}
+	if prices := content.ScriptExecutionPrices; prices != nil {
+		params.Prices = &Prices{Memory: prices.Memory, Steps: prices.CPU}
+	}
+	params.PlutusCostModels = content.PlutusCostModels
+
+	*p = ProtocolParametersV6(params)
+	return nil
+}
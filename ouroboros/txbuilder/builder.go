@@ -0,0 +1,338 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package txbuilder builds, balances and signs transactions.  Inputs are
+// selected from the utxos provided to cover the outputs and fee, change is
+// returned to the change address and the tx is signed with ed25519 keys.
+// Plutus scripts are not supported; assets may be minted with native scripts
+//
+//	tx, err := txbuilder.New(params).
+//		SelectFrom(utxos...).
+//		AddOutputs(chainsync.TxOut{Address: to, Value: chainsync.Value{Coins: num.Int64(5000000)}}).
+//		ChangeAddress(from).
+//		Sign(key).
+//		Build()
+//	if err != nil {
+//		return err
+//	}
+//	err = client.SubmitTx(ctx, []byte(tx.Hex()))
+package txbuilder
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/savaki/ogmigo/ouroboros/chainsync"
+	"github.com/savaki/ogmigo/ouroboros/chainsync/num"
+	"github.com/savaki/ogmigo/ouroboros/coinselection"
+	"github.com/savaki/ogmigo/ouroboros/statequery"
+)
+
+// maxIterations bounds the rounds of input selection and fee estimation
+const maxIterations = 32
+
+// ErrInsufficientFunds is returned when the utxos available cannot cover the
+// outputs, fee and change
+var ErrInsufficientFunds = coinselection.ErrInsufficientFunds
+
+// Tx holds a signed tx
+type Tx struct {
+	ID     string
+	CBOR   []byte
+	Fee    num.Int
+	Inputs []statequery.Utxo // inputs spent; both those added and those selected
+}
+
+// Hex returns the hex encoded cbor of the tx as accepted by Client.SubmitTx
+func (t Tx) Hex() string {
+	return hex.EncodeToString(t.CBOR)
+}
+
+// Builder accumulates the contents of a tx.  Methods return the builder to
+// allow chaining; errors are deferred until Build
+type Builder struct {
+	params     statequery.ProtocolParameters
+	inputs     []statequery.Utxo
+	utxos      []statequery.Utxo
+	outputs    []chainsync.TxOut
+	collateral []statequery.Utxo
+	change     string
+	metadata   map[uint64]interface{}
+	validity   chainsync.ValidityInterval
+	mint       chainsync.Value
+	scripts    []chainsync.NativeScript
+	keys       []ed25519.PrivateKey
+	algorithm  coinselection.Algorithm
+	maxInputs  int
+	err        error
+}
+
+// New returns a builder computing fees and min utxo from the parameters
+func New(params statequery.ProtocolParameters) *Builder {
+	return &Builder{params: params, algorithm: coinselection.LargestFirst}
+}
+
+// AddInputs spends the utxos regardless of whether they are needed
+func (b *Builder) AddInputs(utxos ...statequery.Utxo) *Builder {
+	b.inputs = append(b.inputs, utxos...)
+	return b
+}
+
+// SelectFrom adds utxos that may be spent to cover the outputs and fee
+func (b *Builder) SelectFrom(utxos ...statequery.Utxo) *Builder {
+	b.utxos = append(b.utxos, utxos...)
+	return b
+}
+
+// CoinSelection sets the algorithm selecting from the utxos provided to
+// SelectFrom; coinselection.LargestFirst by default
+func (b *Builder) CoinSelection(algorithm coinselection.Algorithm) *Builder {
+	b.algorithm = algorithm
+	return b
+}
+
+// MaxInputs limits the number of inputs, both added and selected
+func (b *Builder) MaxInputs(n int) *Builder {
+	b.maxInputs = n
+	return b
+}
+
+// AddOutputs adds outputs to the tx.  An output without coins is given the
+// min utxo; an output with fewer coins than the min utxo is an error
+func (b *Builder) AddOutputs(txOuts ...chainsync.TxOut) *Builder {
+	b.outputs = append(b.outputs, txOuts...)
+	return b
+}
+
+// AddCollateral adds collateral inputs
+func (b *Builder) AddCollateral(utxos ...statequery.Utxo) *Builder {
+	b.collateral = append(b.collateral, utxos...)
+	return b
+}
+
+// ChangeAddress sets the address that receives the change
+func (b *Builder) ChangeAddress(addr string) *Builder {
+	b.change = addr
+	return b
+}
+
+// Metadata sets the metadata under label.  Values may be integers, strings,
+// byte strings, []interface{} and maps thereof e.g. as decoded from json
+func (b *Builder) Metadata(label uint64, value interface{}) *Builder {
+	v, err := metadatum(value)
+	if err != nil {
+		b.fail(fmt.Errorf("invalid metadata, %v: %w", label, err))
+		return b
+	}
+	if b.metadata == nil {
+		b.metadata = map[uint64]interface{}{}
+	}
+	b.metadata[label] = v
+	return b
+}
+
+// ValidityInterval sets the slots between which the tx is valid
+func (b *Builder) ValidityInterval(v chainsync.ValidityInterval) *Builder {
+	b.validity = v
+	return b
+}
+
+// Mint mints, or burns if negative, the assets keyed by hex encoded asset
+// name under the policy of the native script.  The keys required by the
+// script must be passed to Sign
+func (b *Builder) Mint(script chainsync.NativeScript, assets map[string]num.Int) *Builder {
+	policyID, err := PolicyID(script)
+	if err != nil {
+		b.fail(fmt.Errorf("invalid mint: %w", err))
+		return b
+	}
+
+	mint := chainsync.Value{Assets: map[chainsync.AssetID]num.Int{}}
+	for name, quantity := range assets {
+		if _, err := hex.DecodeString(name); err != nil {
+			b.fail(fmt.Errorf("invalid mint: asset name must be hex, %v", name))
+			return b
+		}
+		id := policyID
+		if name != "" {
+			id += "." + name
+		}
+		mint.Assets[chainsync.AssetID(id)] = quantity
+	}
+	b.mint = b.mint.Add(mint)
+	b.scripts = append(b.scripts, script)
+	return b
+}
+
+// Sign adds the keys that sign the tx
+func (b *Builder) Sign(keys ...ed25519.PrivateKey) *Builder {
+	b.keys = append(b.keys, keys...)
+	return b
+}
+
+func (b *Builder) fail(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// Build selects inputs, balances the tx and signs it.  The fee is the linear
+// fee of the signed tx; change below the min utxo that cannot be topped up
+// from the utxos available is added to the fee
+func (b *Builder) Build() (Tx, error) {
+	if b.err != nil {
+		return Tx{}, fmt.Errorf("failed to build tx: %w", b.err)
+	}
+	if b.change == "" {
+		return Tx{}, fmt.Errorf("failed to build tx: change address required")
+	}
+
+	outputs, err := b.fundOutputs()
+	if err != nil {
+		return Tx{}, fmt.Errorf("failed to build tx: %w", err)
+	}
+	produced := chainsync.Value{}
+	for _, txOut := range outputs {
+		produced = produced.Add(txOut.Value)
+	}
+
+	inputs := append([]statequery.Utxo{}, b.inputs...)
+	available := without(b.utxos, inputs)
+
+	var fee num.Int
+	for i := 0; i < maxIterations; i++ {
+		consumed := sum(inputs).Add(b.mint)
+		change := consumed.Sub(produced).Sub(chainsync.Value{Coins: fee})
+
+		deficit, err := b.deficit(change)
+		if err != nil {
+			return Tx{}, fmt.Errorf("failed to build tx: %w", err)
+		}
+		txOuts, txFee := outputs, fee
+		if !deficit.IsZero() {
+			selection, err := b.selectInputs(available, deficit, len(inputs))
+			if err == nil {
+				inputs, available = append(inputs, selection.Inputs...), without(available, selection.Inputs)
+				continue
+			}
+			if !errors.Is(err, ErrInsufficientFunds) || change.HasNegative() || len(change.AssetIDs()) > 0 {
+				return Tx{}, fmt.Errorf("failed to build tx: %w", err)
+			}
+			txFee = fee.Add(change.Coins) // change too small to return
+		} else if !change.IsZero() {
+			txOuts = append(txOuts[:len(txOuts):len(txOuts)], chainsync.TxOut{Address: b.change, Value: change})
+		}
+
+		tx, err := b.encodeTx(inputs, txOuts, txFee)
+		if err != nil {
+			return Tx{}, fmt.Errorf("failed to build tx: %w", err)
+		}
+		required := b.params.MinFee(uint64(len(tx.CBOR)))
+		if required.Cmp(txFee) <= 0 {
+			if max := b.params.MaxTxSize; max > 0 && uint64(len(tx.CBOR)) > max {
+				return Tx{}, fmt.Errorf("failed to build tx: size, %v, exceeds max tx size, %v", len(tx.CBOR), max)
+			}
+			return tx, nil
+		}
+		fee = required
+	}
+
+	return Tx{}, fmt.Errorf("failed to build tx: fee did not converge after %v iterations", maxIterations)
+}
+
+// fundOutputs returns the outputs with the min utxo assigned to outputs
+// without coins
+func (b *Builder) fundOutputs() ([]chainsync.TxOut, error) {
+	var outputs []chainsync.TxOut
+	for i, txOut := range b.outputs {
+		minUtxo, err := b.params.MinUtxo(txOut)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case txOut.Value.Coins.IsZero():
+			txOut.Value.Coins = minUtxo
+		case txOut.Value.Coins.Cmp(minUtxo) < 0:
+			return nil, fmt.Errorf("output %v holds %v coins; want at least %v", i, txOut.Value.Coins, minUtxo)
+		}
+		outputs = append(outputs, txOut)
+	}
+	return outputs, nil
+}
+
+// deficit returns the value that must be added to change to balance the tx;
+// any negative quantities and, unless change is empty, the coins required to
+// reach the min utxo
+func (b *Builder) deficit(change chainsync.Value) (chainsync.Value, error) {
+	deficit := chainsync.Value{}
+	if change.Coins.Sign() < 0 {
+		deficit.Coins = change.Coins.Neg()
+	}
+	for _, id := range change.AssetIDs() {
+		if quantity := change.Assets[id]; quantity.Sign() < 0 {
+			deficit = deficit.Add(chainsync.Value{Assets: map[chainsync.AssetID]num.Int{id: quantity.Neg()}})
+		}
+	}
+	if !deficit.IsZero() || change.IsZero() {
+		return deficit, nil
+	}
+
+	minUtxo, err := b.params.MinUtxo(chainsync.TxOut{Address: b.change, Value: change})
+	if err != nil {
+		return chainsync.Value{}, err
+	}
+	if change.Coins.Cmp(minUtxo) < 0 {
+		deficit.Coins = minUtxo.Sub(change.Coins)
+	}
+	return deficit, nil
+}
+
+// selectInputs selects utxos covering deficit without exceeding the max
+// inputs given the n inputs already spent
+func (b *Builder) selectInputs(utxos []statequery.Utxo, deficit chainsync.Value, n int) (coinselection.Selection, error) {
+	var opts []coinselection.Option
+	if b.maxInputs > 0 {
+		if n >= b.maxInputs {
+			return coinselection.Selection{}, fmt.Errorf("%w: %v inputs spent; max %v", coinselection.ErrMaxInputsExceeded, n, b.maxInputs)
+		}
+		opts = append(opts, coinselection.WithMaxInputs(b.maxInputs-n))
+	}
+	return b.algorithm(utxos, deficit, opts...)
+}
+
+func sum(utxos []statequery.Utxo) chainsync.Value {
+	total := chainsync.Value{}
+	for _, utxo := range utxos {
+		total = total.Add(utxo.TxOut.Value)
+	}
+	return total
+}
+
+// without returns the utxos not in spent
+func without(utxos, spent []statequery.Utxo) []statequery.Utxo {
+	seen := map[chainsync.TxIn]bool{}
+	for _, utxo := range spent {
+		seen[utxo.TxIn] = true
+	}
+	var remaining []statequery.Utxo
+	for _, utxo := range utxos {
+		if !seen[utxo.TxIn] {
+			seen[utxo.TxIn] = true
+			remaining = append(remaining, utxo)
+		}
+	}
+	return remaining
+}
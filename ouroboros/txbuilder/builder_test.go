@@ -0,0 +1,325 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package txbuilder
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/savaki/ogmigo/ouroboros/address"
+	"github.com/savaki/ogmigo/ouroboros/chainsync"
+	"github.com/savaki/ogmigo/ouroboros/chainsync/num"
+	"github.com/savaki/ogmigo/ouroboros/coinselection"
+	"github.com/savaki/ogmigo/ouroboros/statequery"
+)
+
+const recipient = "addr_test1qz2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzer3n0d3vllmyqwsx5wktcd8cc3sq835lu7drv2xwl2wywfgs68faae"
+
+func testParams(t *testing.T) statequery.ProtocolParameters {
+	t.Helper()
+	data, err := ioutil.ReadFile("testdata/currentProtocolParametersBabbage.json")
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	var params statequery.ProtocolParameters
+	if err := json.Unmarshal(data, &params); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	return params
+}
+
+func testKey(seed byte) ed25519.PrivateKey {
+	return ed25519.NewKeyFromSeed([]byte(strings.Repeat(string([]byte{seed}), ed25519.SeedSize)))
+}
+
+func testAddress(t *testing.T, key ed25519.PrivateKey) string {
+	t.Helper()
+	hash, err := hex.DecodeString(KeyHash(key.Public().(ed25519.PublicKey)))
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	return address.NewEnterpriseAddress(address.Testnet, address.KeyCredential(hash)).String()
+}
+
+func testUtxo(addr string, index int, coins int64, assets map[chainsync.AssetID]num.Int) statequery.Utxo {
+	return statequery.Utxo{
+		TxIn: chainsync.TxIn{
+			TxHash: strings.Repeat("ab", 32),
+			Index:  index,
+		},
+		TxOut: chainsync.TxOut{
+			Address: addr,
+			Value:   chainsync.Value{Coins: num.Int64(coins), Assets: assets},
+		},
+	}
+}
+
+// assertBalanced verifies the tx decodes, spends what it produces and pays at
+// least the min fee
+func assertBalanced(t *testing.T, params statequery.ProtocolParameters, tx Tx, mint chainsync.Value) chainsync.Tx {
+	t.Helper()
+
+	decoded, err := chainsync.DecodeTxHex(tx.Hex())
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := decoded.ID, tx.ID; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := len(decoded.Body.Inputs), len(tx.Inputs); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	produced := chainsync.Value{Coins: decoded.Body.Fee}
+	for _, txOut := range decoded.Body.Outputs {
+		produced = produced.Add(txOut.Value)
+	}
+	if consumed := sum(tx.Inputs).Add(mint); !consumed.Equal(produced) {
+		t.Fatalf("got %v; want %v", consumed, produced)
+	}
+
+	minFee := params.MinFee(uint64(len(tx.CBOR)))
+	if decoded.Body.Fee.Cmp(minFee) < 0 {
+		t.Fatalf("got fee %v; want at least %v", decoded.Body.Fee, minFee)
+	}
+	return decoded
+}
+
+func TestBuilder_Build(t *testing.T) {
+	params := testParams(t)
+	key := testKey(1)
+	from := testAddress(t, key)
+
+	tx, err := New(params).
+		SelectFrom(
+			testUtxo(from, 0, 3000000, nil),
+			testUtxo(from, 1, 5000000, nil),
+			testUtxo(from, 2, 1500000, nil),
+		).
+		AddOutputs(chainsync.TxOut{Address: recipient, Value: chainsync.Value{Coins: num.Int64(3500000)}}).
+		ChangeAddress(from).
+		Metadata(674, map[string]interface{}{"msg": []interface{}{"hello"}}).
+		ValidityInterval(chainsync.ValidityInterval{InvalidBefore: 100, InvalidHereafter: 2000}).
+		Sign(key).
+		Build()
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	decoded := assertBalanced(t, params, tx, chainsync.Value{})
+	if got, want := len(tx.Inputs), 1; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := tx.Inputs[0].TxIn.Index, 1; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := len(decoded.Body.Outputs), 2; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := decoded.Body.Outputs[1].Address, from; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := decoded.Body.ValidityInterval, (chainsync.ValidityInterval{InvalidBefore: 100, InvalidHereafter: 2000}); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	// [body, {0: [[vkey, signature]]}, true, metadata]
+	var items []cbor.RawMessage
+	if err := cbor.Unmarshal(tx.CBOR, &items); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	var witnesses struct {
+		VKeys []struct {
+			_         struct{} `cbor:",toarray"`
+			VKey      []byte
+			Signature []byte
+		} `cbor:"0,keyasint"`
+	}
+	if err := cbor.Unmarshal(items[1], &witnesses); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := len(witnesses.VKeys), 1; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	id, _ := hex.DecodeString(tx.ID)
+	if !ed25519.Verify(witnesses.VKeys[0].VKey, id, witnesses.VKeys[0].Signature) {
+		t.Fatalf("got invalid signature; want valid")
+	}
+
+	var metadata map[uint64]map[string][]string
+	if err := cbor.Unmarshal(items[3], &metadata); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := metadata[674]["msg"][0], "hello"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestBuilder_Mint(t *testing.T) {
+	params := testParams(t)
+	key := testKey(2)
+	from := testAddress(t, key)
+	script := chainsync.NativeScript{
+		Type:    chainsync.NativeScriptSignature,
+		KeyHash: KeyHash(key.Public().(ed25519.PublicKey)),
+	}
+	policyID, err := PolicyID(script)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	assetID := chainsync.AssetID(policyID + ".544f4b454e")
+
+	tx, err := New(params).
+		SelectFrom(testUtxo(from, 0, 10000000, nil)).
+		Mint(script, map[string]num.Int{"544f4b454e": num.Int64(100)}).
+		AddOutputs(chainsync.TxOut{
+			Address: recipient,
+			Value:   chainsync.Value{Assets: map[chainsync.AssetID]num.Int{assetID: num.Int64(10)}},
+		}).
+		ChangeAddress(from).
+		Sign(key).
+		Build()
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	mint := chainsync.Value{Assets: map[chainsync.AssetID]num.Int{assetID: num.Int64(100)}}
+	decoded := assertBalanced(t, params, tx, mint)
+	if decoded.Body.Mint == nil || !decoded.Body.Mint.Equal(mint) {
+		t.Fatalf("got %v; want %v", decoded.Body.Mint, mint)
+	}
+
+	outputs := decoded.Body.Outputs
+	if got, want := len(outputs), 2; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	minUtxo, err := params.MinUtxo(outputs[0])
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := outputs[0].Value.Coins, minUtxo; got.Cmp(want) != 0 {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := outputs[1].Value.Assets[assetID].Int(), 90; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestBuilder_SmallChange(t *testing.T) {
+	params := testParams(t)
+	key := testKey(3)
+	from := testAddress(t, key)
+
+	// change below the min utxo is added to the fee
+	tx, err := New(params).
+		AddInputs(testUtxo(from, 0, 2200000, nil)).
+		AddOutputs(chainsync.TxOut{Address: recipient, Value: chainsync.Value{Coins: num.Int64(2000000)}}).
+		ChangeAddress(from).
+		Sign(key).
+		Build()
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	decoded := assertBalanced(t, params, tx, chainsync.Value{})
+	if got, want := len(decoded.Body.Outputs), 1; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := decoded.Body.Fee.Int64(), int64(200000); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestBuilder_Errors(t *testing.T) {
+	params := testParams(t)
+	key := testKey(4)
+	from := testAddress(t, key)
+	payment := chainsync.TxOut{Address: recipient, Value: chainsync.Value{Coins: num.Int64(2000000)}}
+
+	_, err := New(params).
+		SelectFrom(testUtxo(from, 0, 1500000, nil)).
+		AddOutputs(payment).
+		ChangeAddress(from).
+		Sign(key).
+		Build()
+	if !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatalf("got %v; want ErrInsufficientFunds", err)
+	}
+
+	testCases := map[string]*Builder{
+		"no change address": New(params).SelectFrom(testUtxo(from, 0, 5000000, nil)).AddOutputs(payment),
+		"below min utxo": New(params).
+			SelectFrom(testUtxo(from, 0, 5000000, nil)).
+			AddOutputs(chainsync.TxOut{Address: recipient, Value: chainsync.Value{Coins: num.Int64(1000)}}).
+			ChangeAddress(from),
+		"metadata": New(params).
+			SelectFrom(testUtxo(from, 0, 5000000, nil)).
+			AddOutputs(payment).
+			Metadata(1, strings.Repeat("x", 65)).
+			ChangeAddress(from),
+		"mint": New(params).
+			SelectFrom(testUtxo(from, 0, 5000000, nil)).
+			Mint(chainsync.NativeScript{Type: "unknown"}, map[string]num.Int{"": num.Int64(1)}).
+			ChangeAddress(from),
+	}
+	for label, builder := range testCases {
+		t.Run(label, func(t *testing.T) {
+			if _, err := builder.Sign(key).Build(); err == nil {
+				t.Fatalf("got nil; want error")
+			}
+		})
+	}
+}
+
+func TestBuilder_CoinSelection(t *testing.T) {
+	params := testParams(t)
+	key := testKey(5)
+	from := testAddress(t, key)
+
+	var utxos []statequery.Utxo
+	for i := 0; i < 10; i++ {
+		utxos = append(utxos, testUtxo(from, i, 2000000, nil))
+	}
+	payment := chainsync.TxOut{Address: recipient, Value: chainsync.Value{Coins: num.Int64(5000000)}}
+
+	tx, err := New(params).
+		SelectFrom(utxos...).
+		CoinSelection(coinselection.RandomImprove).
+		AddOutputs(payment).
+		ChangeAddress(from).
+		Sign(key).
+		Build()
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	assertBalanced(t, params, tx, chainsync.Value{})
+
+	_, err = New(params).
+		SelectFrom(utxos...).
+		MaxInputs(2).
+		AddOutputs(payment).
+		ChangeAddress(from).
+		Sign(key).
+		Build()
+	if !errors.Is(err, coinselection.ErrMaxInputsExceeded) {
+		t.Fatalf("got %v; want ErrMaxInputsExceeded", err)
+	}
+}
@@ -0,0 +1,247 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package txbuilder
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/savaki/ogmigo/ouroboros/chainsync"
+	"github.com/savaki/ogmigo/ouroboros/chainsync/num"
+	"github.com/savaki/ogmigo/ouroboros/statequery"
+	"golang.org/x/crypto/blake2b"
+)
+
+// tx body map keys as defined by the cardano ledger cddl
+const (
+	txBodyInputs            = 0
+	txBodyOutputs           = 1
+	txBodyFee               = 2
+	txBodyTimeToLive        = 3
+	txBodyAuxiliaryDataHash = 7
+	txBodyValidityStart     = 8
+	txBodyMint              = 9
+	txBodyCollaterals       = 13
+)
+
+// witness set map keys
+const (
+	witnessVKeys         = 0
+	witnessNativeScripts = 1
+)
+
+// maxMetadataLength is the max length, in bytes, of metadata strings and
+// byte strings
+const maxMetadataLength = 64
+
+var encMode, _ = cbor.CoreDetEncOptions().EncMode() // only fails on invalid options
+
+// encodeTx returns the signed tx spending inputs with the given fee
+func (b *Builder) encodeTx(inputs []statequery.Utxo, outputs []chainsync.TxOut, fee num.Int) (Tx, error) {
+	feeCoins, ok := fee.ToUint64()
+	if !ok {
+		return Tx{}, fmt.Errorf("invalid fee, %v", fee)
+	}
+
+	body := map[uint64]interface{}{
+		txBodyFee: feeCoins,
+	}
+	var err error
+	if body[txBodyInputs], err = encodeTxIns(inputs); err != nil {
+		return Tx{}, err
+	}
+	var txOuts []cbor.RawMessage
+	for i, txOut := range outputs {
+		data, err := chainsync.EncodeTxOut(txOut)
+		if err != nil {
+			return Tx{}, fmt.Errorf("invalid output, %v: %w", i, err)
+		}
+		txOuts = append(txOuts, data)
+	}
+	body[txBodyOutputs] = txOuts
+	if v := b.validity.InvalidHereafter; v > 0 {
+		body[txBodyTimeToLive] = v
+	}
+	if v := b.validity.InvalidBefore; v > 0 {
+		body[txBodyValidityStart] = v
+	}
+	if len(b.mint.AssetIDs()) > 0 {
+		data, err := chainsync.EncodeMint(b.mint)
+		if err != nil {
+			return Tx{}, err
+		}
+		body[txBodyMint] = cbor.RawMessage(data)
+	}
+	if len(b.collateral) > 0 {
+		if body[txBodyCollaterals], err = encodeTxIns(b.collateral); err != nil {
+			return Tx{}, err
+		}
+	}
+
+	var aux []byte
+	if len(b.metadata) > 0 {
+		if aux, err = encMode.Marshal(b.metadata); err != nil {
+			return Tx{}, fmt.Errorf("invalid metadata: %w", err)
+		}
+		hash := blake2b.Sum256(aux)
+		body[txBodyAuxiliaryDataHash] = hash[:]
+	}
+
+	encodedBody, err := encMode.Marshal(body)
+	if err != nil {
+		return Tx{}, err
+	}
+	id := blake2b.Sum256(encodedBody)
+
+	witnesses := map[uint64]interface{}{}
+	var vkeys []interface{}
+	seen := map[string]bool{}
+	for _, key := range b.keys {
+		public := key.Public().(ed25519.PublicKey)
+		if seen[string(public)] {
+			continue
+		}
+		seen[string(public)] = true
+		vkeys = append(vkeys, []interface{}{[]byte(public), ed25519.Sign(key, id[:])})
+	}
+	if len(vkeys) > 0 {
+		witnesses[witnessVKeys] = vkeys
+	}
+	if len(b.scripts) > 0 {
+		var scripts []cbor.RawMessage
+		for _, script := range b.scripts {
+			data, err := chainsync.EncodeNativeScript(script)
+			if err != nil {
+				return Tx{}, err
+			}
+			scripts = append(scripts, data)
+		}
+		witnesses[witnessNativeScripts] = scripts
+	}
+
+	// [body, witnesses, is valid, auxiliary data]
+	var auxData interface{}
+	if aux != nil {
+		auxData = cbor.RawMessage(aux)
+	}
+	data, err := encMode.Marshal([]interface{}{cbor.RawMessage(encodedBody), witnesses, true, auxData})
+	if err != nil {
+		return Tx{}, err
+	}
+
+	return Tx{
+		ID:     hex.EncodeToString(id[:]),
+		CBOR:   data,
+		Fee:    fee,
+		Inputs: inputs,
+	}, nil
+}
+
+// encodeTxIns encodes utxos as [[tx hash, index], ...] sorted as the ledger
+// orders inputs; by tx hash then index
+func encodeTxIns(utxos []statequery.Utxo) ([]interface{}, error) {
+	type txIn struct {
+		hash  []byte
+		index int
+	}
+	var txIns []txIn
+	for _, utxo := range utxos {
+		hash, err := hex.DecodeString(utxo.TxIn.TxHash)
+		if err != nil {
+			return nil, fmt.Errorf("invalid input, %v: %w", utxo.TxIn, err)
+		}
+		txIns = append(txIns, txIn{hash: hash, index: utxo.TxIn.Index})
+	}
+	sort.Slice(txIns, func(i, j int) bool {
+		if c := bytes.Compare(txIns[i].hash, txIns[j].hash); c != 0 {
+			return c < 0
+		}
+		return txIns[i].index < txIns[j].index
+	})
+
+	items := make([]interface{}, 0, len(txIns))
+	for _, in := range txIns {
+		items = append(items, []interface{}{in.hash, in.index})
+	}
+	return items, nil
+}
+
+// metadatum converts v to a value encodable as tx metadata; integers,
+// strings and byte strings of at most 64 bytes, lists and maps thereof.
+// Numbers decoded from json, float64, are accepted if they are integers
+func metadatum(v interface{}) (interface{}, error) {
+	switch value := v.(type) {
+	case string:
+		if len(value) > maxMetadataLength {
+			return nil, fmt.Errorf("string exceeds %v bytes, %v", maxMetadataLength, value)
+		}
+		return value, nil
+	case []byte:
+		if len(value) > maxMetadataLength {
+			return nil, fmt.Errorf("bytes exceed %v bytes, %x", maxMetadataLength, value)
+		}
+		return value, nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return value, nil
+	case float64:
+		if value != math.Trunc(value) || math.Abs(value) > 1<<53 {
+			return nil, fmt.Errorf("number is not an integer, %v", value)
+		}
+		return int64(value), nil
+	case num.Int:
+		return value.BigInt(), nil
+	case *big.Int:
+		return value, nil
+	case []interface{}:
+		items := make([]interface{}, 0, len(value))
+		for _, item := range value {
+			converted, err := metadatum(item)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, converted)
+		}
+		return items, nil
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Map {
+		return nil, fmt.Errorf("unsupported type, %T", v)
+	}
+	m := make(map[interface{}]interface{}, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		key, err := metadatum(iter.Key().Interface())
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := key.(*big.Int); ok {
+			return nil, fmt.Errorf("unsupported map key type, %T", iter.Key().Interface())
+		}
+		value, err := metadatum(iter.Value().Interface())
+		if err != nil {
+			return nil, err
+		}
+		m[key] = value
+	}
+	return m, nil
+}
@@ -0,0 +1,69 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package txbuilder
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/savaki/ogmigo/ouroboros/chainsync/num"
+)
+
+func TestMetadatum(t *testing.T) {
+	var v interface{}
+	if err := json.Unmarshal([]byte(`{"name": "ogmigo", "tags": ["a", "b"], "version": 6}`), &v); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	got, err := metadatum(v)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	want := map[interface{}]interface{}{
+		"name":    "ogmigo",
+		"tags":    []interface{}{"a", "b"},
+		"version": int64(6),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v; want %#v", got, want)
+	}
+
+	n, err := metadatum(num.Int64(42))
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := n.(*big.Int).Int64(), int64(42); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	invalid := map[string]interface{}{
+		"long string":   strings.Repeat("x", 65),
+		"long bytes":    make([]byte, 65),
+		"fraction":      1.5,
+		"bool":          true,
+		"map value":     map[string]interface{}{"ok": 1, "invalid": struct{}{}},
+		"nested string": []interface{}{[]interface{}{strings.Repeat("x", 65)}},
+	}
+	for label, v := range invalid {
+		t.Run(label, func(t *testing.T) {
+			if _, err := metadatum(v); err == nil {
+				t.Fatalf("got nil; want error")
+			}
+		})
+	}
+}
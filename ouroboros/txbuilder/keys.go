@@ -0,0 +1,76 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package txbuilder
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/savaki/ogmigo/ouroboros/chainsync"
+	"golang.org/x/crypto/blake2b"
+)
+
+// ParseSigningKey decodes a signing key as written by cardano-cli; either the
+// text envelope, {"type": ..., "cborHex": "5820..."}, or the cborHex alone.
+// Only normal ed25519 keys are supported; extended keys are rejected
+func ParseSigningKey(data []byte) (ed25519.PrivateKey, error) {
+	s := string(bytes.TrimSpace(data))
+	if len(s) > 0 && s[0] == '{' {
+		var envelope struct{ CborHex string }
+		if err := json.Unmarshal(data, &envelope); err != nil {
+			return nil, fmt.Errorf("failed to parse signing key: %w", err)
+		}
+		s = envelope.CborHex
+	}
+
+	raw, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key: invalid hex: %w", err)
+	}
+	var seed []byte
+	if err := cbor.Unmarshal(raw, &seed); err != nil {
+		return nil, fmt.Errorf("failed to parse signing key: %w", err)
+	}
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("failed to parse signing key: got %v bytes; want %v", len(seed), ed25519.SeedSize)
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// KeyHash returns the hex encoded blake2b-224 hash of a verification key as
+// used by addresses and native scripts
+func KeyHash(key ed25519.PublicKey) string {
+	return hex.EncodeToString(hash224(key))
+}
+
+// PolicyID returns the policy id of assets minted by the native script
+func PolicyID(script chainsync.NativeScript) (string, error) {
+	data, err := chainsync.EncodeNativeScript(script)
+	if err != nil {
+		return "", err
+	}
+	// script hashes are prefixed by the script language, 0 for native
+	return hex.EncodeToString(hash224(append([]byte{0}, data...))), nil
+}
+
+func hash224(data []byte) []byte {
+	h, _ := blake2b.New(28, nil) // only fails on invalid size or key
+	h.Write(data)
+	return h.Sum(nil)
+}
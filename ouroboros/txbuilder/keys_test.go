@@ -0,0 +1,79 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package txbuilder
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/savaki/ogmigo/ouroboros/chainsync"
+)
+
+func TestParseSigningKey(t *testing.T) {
+	want := testKey(7)
+	cborHex := "5820" + hex.EncodeToString(want.Seed())
+
+	testCases := map[string]string{
+		"envelope": `{"type": "PaymentSigningKeyShelley_ed25519", "description": "Payment Signing Key", "cborHex": "` + cborHex + `"}`,
+		"cborHex":  cborHex + "\n",
+	}
+	for label, data := range testCases {
+		t.Run(label, func(t *testing.T) {
+			got, err := ParseSigningKey([]byte(data))
+			if err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Fatalf("got %x; want %x", got, want)
+			}
+		})
+	}
+
+	extended := "5840" + strings.Repeat("01", 64)
+	for _, data := range []string{extended, "zz", `{"cborHex": 1}`} {
+		if _, err := ParseSigningKey([]byte(data)); err == nil {
+			t.Fatalf("got nil; want error")
+		}
+	}
+}
+
+func TestPolicyID(t *testing.T) {
+	script := chainsync.NativeScript{
+		Type: chainsync.NativeScriptAll,
+		Scripts: []chainsync.NativeScript{
+			{Type: chainsync.NativeScriptSignature, KeyHash: "9493315cd92eb5d8c4304e67b7e16ae36d61d34502694657811a2c8e"},
+			{Type: chainsync.NativeScriptExpiresAt, Slot: 1000},
+		},
+	}
+
+	policyID, err := PolicyID(script)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := len(policyID), 56; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	script.Scripts[1].Slot = 1001
+	other, err := PolicyID(script)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if policyID == other {
+		t.Fatalf("got same policy id; want different")
+	}
+}
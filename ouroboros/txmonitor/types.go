@@ -0,0 +1,73 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package txmonitor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/savaki/ogmigo/ouroboros/chainsync"
+)
+
+var null = []byte("null")
+
+// AwaitAcquired is returned once a mempool snapshot has been acquired
+type AwaitAcquired struct {
+	Slot uint64 `json:"slot,omitempty"`
+}
+
+// SizeAndCapacity describes the acquired mempool snapshot
+type SizeAndCapacity struct {
+	Capacity    uint64 `json:"capacity"`    // Capacity of the mempool in bytes
+	CurrentSize uint64 `json:"currentSize"` // CurrentSize of the mempool in bytes
+	NumberOfTxs uint64 `json:"numberOfTxs"` // NumberOfTxs in the mempool
+}
+
+// NextTx holds the result of a NextTx request; either a tx id or, when all
+// fields were requested, the full transaction.  Both are empty once the
+// snapshot has been exhausted
+type NextTx struct {
+	ID string
+	Tx *chainsync.Tx
+}
+
+// Done returns true if there are no more transactions in the snapshot
+func (n NextTx) Done() bool {
+	return n.ID == "" && n.Tx == nil
+}
+
+func (n *NextTx) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	switch {
+	case len(data) == 0 || bytes.Equal(data, null):
+		*n = NextTx{}
+
+	case data[0] == '"':
+		var id string
+		if err := json.Unmarshal(data, &id); err != nil {
+			return fmt.Errorf("failed to unmarshal NextTx: %w", err)
+		}
+		*n = NextTx{ID: id}
+
+	default:
+		var tx chainsync.Tx
+		if err := json.Unmarshal(data, &tx); err != nil {
+			return fmt.Errorf("failed to unmarshal NextTx: %w", err)
+		}
+		*n = NextTx{ID: tx.ID, Tx: &tx}
+	}
+	return nil
+}
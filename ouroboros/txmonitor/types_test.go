@@ -0,0 +1,115 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package txmonitor
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNextTx_UnmarshalJSON(t *testing.T) {
+	t.Run("null", func(t *testing.T) {
+		var got struct{ Result NextTx }
+		if err := json.Unmarshal([]byte(`{"result":null}`), &got); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if !got.Result.Done() {
+			t.Fatalf("got false; want true")
+		}
+	})
+
+	t.Run("id", func(t *testing.T) {
+		var got NextTx
+		if err := json.Unmarshal([]byte(`"abc"`), &got); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := got.ID, "abc"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got.Tx != nil {
+			t.Fatalf("got %v; want nil", got.Tx)
+		}
+	})
+
+	t.Run("tx", func(t *testing.T) {
+		var got NextTx
+		data := []byte(`{"id":"abc","body":{"fee":123,"inputs":[{"txId":"def","index":1}]}}`)
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := got.ID, "abc"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got.Tx == nil {
+			t.Fatalf("got nil; want not nil")
+		}
+		if got, want := got.Tx.Body.Fee.Int64(), int64(123); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+}
+
+func TestNextTxV6_UnmarshalJSON(t *testing.T) {
+	t.Run("null", func(t *testing.T) {
+		var got NextTxV6
+		if err := json.Unmarshal([]byte(`{"transaction":null}`), &got); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if !NextTx(got).Done() {
+			t.Fatalf("got false; want true")
+		}
+	})
+
+	t.Run("id", func(t *testing.T) {
+		var got NextTxV6
+		if err := json.Unmarshal([]byte(`{"transaction":{"id":"abc"}}`), &got); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := got.ID, "abc"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got.Tx != nil {
+			t.Fatalf("got %v; want nil", got.Tx)
+		}
+	})
+
+	t.Run("tx", func(t *testing.T) {
+		var got NextTxV6
+		data := []byte(`{"transaction":{"id":"abc","fee":{"ada":{"lovelace":123}},"inputs":[{"transaction":{"id":"def"},"index":1}]}}`)
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got.Tx == nil {
+			t.Fatalf("got nil; want not nil")
+		}
+		if got, want := got.Tx.Body.Fee.Int64(), int64(123); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := got.Tx.Body.Inputs[0].TxHash, "def"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+}
+
+func TestSizeAndCapacityV6_UnmarshalJSON(t *testing.T) {
+	var got SizeAndCapacityV6
+	data := []byte(`{"maxCapacity":{"bytes":100},"currentSize":{"bytes":10},"transactions":{"count":2}}`)
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if want := (SizeAndCapacityV6{Capacity: 100, CurrentSize: 10, NumberOfTxs: 2}); got != want {
+		t.Fatalf("got %#v; want %#v", got, want)
+	}
+}
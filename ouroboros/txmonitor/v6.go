@@ -0,0 +1,84 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package txmonitor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/savaki/ogmigo/ouroboros/chainsync"
+)
+
+// NextTxV6 decodes the ogmios v6 nextTransaction result,
+// {"transaction": null | {"id": ...} | tx}
+type NextTxV6 NextTx
+
+func (n *NextTxV6) UnmarshalJSON(data []byte) error {
+	var content struct {
+		Transaction json.RawMessage `json:"transaction"`
+	}
+	if err := json.Unmarshal(data, &content); err != nil {
+		return fmt.Errorf("failed to unmarshal NextTxV6: %w", err)
+	}
+
+	raw := bytes.TrimSpace(content.Transaction)
+	if len(raw) == 0 || bytes.Equal(raw, null) {
+		*n = NextTxV6{}
+		return nil
+	}
+
+	var peek map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &peek); err != nil {
+		return fmt.Errorf("failed to unmarshal NextTxV6: %w", err)
+	}
+	if len(peek) == 1 {
+		var id struct{ ID string }
+		if err := json.Unmarshal(raw, &id); err != nil {
+			return fmt.Errorf("failed to unmarshal NextTxV6: %w", err)
+		}
+		*n = NextTxV6{ID: id.ID}
+		return nil
+	}
+
+	var tx chainsync.TxV6
+	if err := json.Unmarshal(raw, &tx); err != nil {
+		return fmt.Errorf("failed to unmarshal NextTxV6: %w", err)
+	}
+	v := chainsync.Tx(tx)
+	*n = NextTxV6{ID: v.ID, Tx: &v}
+	return nil
+}
+
+// SizeAndCapacityV6 decodes the ogmios v6 sizeOfMempool result
+type SizeAndCapacityV6 SizeAndCapacity
+
+func (s *SizeAndCapacityV6) UnmarshalJSON(data []byte) error {
+	var content struct {
+		MaxCapacity  struct{ Bytes uint64 } `json:"maxCapacity"`
+		CurrentSize  struct{ Bytes uint64 } `json:"currentSize"`
+		Transactions struct{ Count uint64 } `json:"transactions"`
+	}
+	if err := json.Unmarshal(data, &content); err != nil {
+		return fmt.Errorf("failed to unmarshal SizeAndCapacityV6: %w", err)
+	}
+
+	*s = SizeAndCapacityV6{
+		Capacity:    content.MaxCapacity.Bytes,
+		CurrentSize: content.CurrentSize.Bytes,
+		NumberOfTxs: content.Transactions.Count,
+	}
+	return nil
+}
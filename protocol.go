@@ -0,0 +1,196 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Protocol identifies the ogmios wire protocol spoken by the Client
+type Protocol int
+
+const (
+	// ProtocolV5 speaks the jsonwsp envelope used by ogmios v5 and earlier
+	ProtocolV5 Protocol = iota
+	// ProtocolV6 speaks the JSON-RPC 2.0 protocol introduced by ogmios v6
+	ProtocolV6
+	// ProtocolAuto selects the protocol from the version reported by the
+	// ogmios health endpoint, falling back to ProtocolV5
+	ProtocolAuto
+)
+
+func (p Protocol) String() string {
+	switch p {
+	case ProtocolV5:
+		return "v5"
+	case ProtocolV6:
+		return "v6"
+	case ProtocolAuto:
+		return "auto"
+	default:
+		return "Protocol(" + strconv.Itoa(int(p)) + ")"
+	}
+}
+
+// errNotSupportedV6 is returned by queries that have no ogmios v6 equivalent
+func errNotSupportedV6(method string) error {
+	return fmt.Errorf("%v is not supported by ogmios v6", method)
+}
+
+// detectTimeout bounds the request to the ogmios health endpoint
+const detectTimeout = 10 * time.Second
+
+// protocolState caches the protocol once resolved; shared by clients derived
+// from the same Client e.g. StateQuerySession
+type protocolState struct {
+	mutex     sync.Mutex
+	resolved  bool
+	protocol  Protocol
+	detecting chan struct{} // closed once detection completes
+}
+
+// Protocol returns the wire protocol in use, detecting it from the server if
+// the Client was created with ProtocolAuto.  Detection happens once; if the
+// server cannot be reached, the call falls back to ProtocolV5 and the next
+// call detects again
+func (c *Client) Protocol(ctx context.Context) (Protocol, error) {
+	state := c.protocol
+	state.mutex.Lock()
+	if state.resolved {
+		defer state.mutex.Unlock()
+		return state.protocol, nil
+	}
+	if c.options.protocol != ProtocolAuto {
+		defer state.mutex.Unlock()
+		state.protocol, state.resolved = c.options.protocol, true
+		return state.protocol, nil
+	}
+	detecting := state.detecting
+	if detecting == nil {
+		detecting = make(chan struct{})
+		state.detecting = detecting
+		go c.detect(state, detecting)
+	}
+	state.mutex.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	case <-detecting:
+	}
+
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	if !state.resolved {
+		return ProtocolV5, nil
+	}
+	return state.protocol, nil
+}
+
+// detect resolves the protocol from the health endpoint.  detection uses its
+// own context so one caller giving up does not fail detection for all.  A
+// failed detection is not cached so the next call to Protocol retries
+func (c *Client) detect(state *protocolState, done chan struct{}) {
+	ctx, cancel := context.WithTimeout(context.Background(), detectTimeout)
+	defer cancel()
+
+	protocol, err := detectProtocol(ctx, c.options.endpoint)
+
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	if err != nil {
+		c.logger.Info("ogmigo unable to detect protocol; defaulting to v5", KV("err", err.Error()))
+	} else {
+		state.protocol, state.resolved = protocol, true
+	}
+	state.detecting = nil
+	close(done)
+}
+
+func (c *Client) v6(ctx context.Context) (bool, error) {
+	protocol, err := c.Protocol(ctx)
+	if err != nil {
+		return false, err
+	}
+	return protocol == ProtocolV6, nil
+}
+
+// detectProtocol reads the version of ogmios from the health endpoint
+func detectProtocol(ctx context.Context, endpoint string) (Protocol, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse endpoint, %v: %w", endpoint, err)
+	}
+	switch u.Scheme {
+	case "wss":
+		u.Scheme = "https"
+	default:
+		u.Scheme = "http"
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/health"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create health request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query ogmios health: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("failed to query ogmios health: status %v", resp.StatusCode)
+	}
+
+	var health struct{ Version string }
+	if err := json.NewDecoder(resp.Body).Decode(&health); err != nil {
+		return 0, fmt.Errorf("failed to decode ogmios health: %w", err)
+	}
+
+	// versions are reported as e.g. v6.0.0 or v5.6.0 (commit)
+	version := strings.TrimPrefix(health.Version, "v")
+	if i := strings.IndexAny(version, ". "); i >= 0 {
+		version = version[:i]
+	}
+	major, err := strconv.Atoi(version)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse ogmios version, %v", health.Version)
+	}
+	if major >= 6 {
+		return ProtocolV6, nil
+	}
+	return ProtocolV5, nil
+}
+
+// makeRPC returns a JSON-RPC 2.0 request as used by ogmios v6
+func makeRPC(method string, params Map) Map {
+	payload := Map{
+		"jsonrpc": "2.0",
+		"method":  method,
+	}
+	if params != nil {
+		payload["params"] = params
+	}
+	return payload
+}
@@ -0,0 +1,676 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/savaki/ogmigo/ouroboros/chainsync"
+)
+
+// errNoResponse instructs fakeOgmiosV6 to not respond to the request
+var errNoResponse = errors.New("no response")
+
+// rpcError allows fakeOgmiosV6 handlers to respond with a JSON-RPC error
+type rpcError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+func (e rpcError) Error() string { return e.Message }
+
+// fakeOgmiosV6 serves the ogmios v6 health endpoint and JSON-RPC protocol
+func fakeOgmiosV6(t *testing.T, fn func(method string, params json.RawMessage) (interface{}, error)) (endpoint string, closer func()) {
+	var upgrader = websocket.Upgrader{} // use default options
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	go func() {
+		_ = http.Serve(listener, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.URL.Path == "/health" {
+				_, _ = w.Write([]byte(`{"version":"v6.0.0 (abcdef)"}`))
+				return
+			}
+
+			c, err := upgrader.Upgrade(w, req, nil)
+			if err != nil {
+				return
+			}
+			defer c.Close()
+
+			for {
+				var request struct {
+					JSONRPC string          `json:"jsonrpc"`
+					Method  string          `json:"method"`
+					Params  json.RawMessage `json:"params"`
+					ID      json.RawMessage `json:"id"`
+				}
+				if err := c.ReadJSON(&request); err != nil {
+					return
+				}
+
+				response := Map{
+					"jsonrpc": request.JSONRPC,
+					"method":  request.Method,
+				}
+				if request.ID != nil {
+					response["id"] = request.ID
+				}
+
+				result, err := fn(request.Method, request.Params)
+				var re rpcError
+				switch {
+				case errors.Is(err, errNoResponse):
+					continue
+				case errors.As(err, &re):
+					response["error"] = re
+				case err != nil:
+					response["error"] = rpcError{Code: -32601, Message: err.Error()}
+				default:
+					response["result"] = result
+				}
+				if err := c.WriteJSON(response); err != nil {
+					return
+				}
+			}
+		}))
+	}()
+
+	return "ws://" + listener.Addr().String(), func() { listener.Close() }
+}
+
+func TestDetectProtocol(t *testing.T) {
+	tests := map[string]struct {
+		Version string
+		Want    Protocol
+		Err     bool
+	}{
+		"v6":      {Version: "v6.0.0 (abcdef)", Want: ProtocolV6},
+		"v5":      {Version: "v5.6.0 (abcdef)", Want: ProtocolV5},
+		"invalid": {Version: "nightly", Err: true},
+	}
+
+	for label, tc := range tests {
+		t.Run(label, func(t *testing.T) {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			defer listener.Close()
+
+			go func() {
+				_ = http.Serve(listener, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					if req.URL.Path != "/health" {
+						http.NotFound(w, req)
+						return
+					}
+					_, _ = fmt.Fprintf(w, `{"version":%q}`, tc.Version)
+				}))
+			}()
+
+			got, err := detectProtocol(context.Background(), "ws://"+listener.Addr().String())
+			if tc.Err {
+				if err == nil {
+					t.Fatalf("got nil; want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			if got != tc.Want {
+				t.Fatalf("got %v; want %v", got, tc.Want)
+			}
+		})
+	}
+}
+
+// protocol returns the protocol of the client, failing the test on error
+func protocol(t *testing.T, client *Client) Protocol {
+	t.Helper()
+	got, err := client.Protocol(context.Background())
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	return got
+}
+
+func TestClient_Protocol(t *testing.T) {
+	endpoint, closer := fakeOgmiosV6(t, func(string, json.RawMessage) (interface{}, error) {
+		return nil, errNoResponse
+	})
+	defer closer()
+
+	client := New(WithEndpoint(endpoint), WithLogger(NopLogger))
+	if got, want := protocol(t, client), ProtocolV5; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	client = New(WithEndpoint(endpoint), WithLogger(NopLogger), WithProtocol(ProtocolAuto))
+	if got, want := protocol(t, client), ProtocolV6; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	// unreachable servers fall back to v5
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	listener.Close()
+	client = New(WithEndpoint("ws://"+listener.Addr().String()), WithLogger(NopLogger), WithProtocol(ProtocolAuto))
+	if got, want := protocol(t, client), ProtocolV5; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestClient_ProtocolDetect(t *testing.T) {
+	var (
+		requests  int64
+		available int64
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt64(&requests, 1)
+		if atomic.LoadInt64(&available) == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"version":"v6.0.0"}`)
+	}))
+	defer server.Close()
+
+	client := New(WithEndpoint("ws"+strings.TrimPrefix(server.URL, "http")), WithLogger(NopLogger), WithProtocol(ProtocolAuto))
+
+	// concurrent callers share a single detection
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got, err := client.Protocol(context.Background()); err != nil || got != ProtocolV5 {
+				t.Errorf("got %v, %v; want %v, nil", got, err, ProtocolV5)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got, want := atomic.LoadInt64(&requests), int64(1); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	// failed detection is retried by the next call and cached once it succeeds
+	atomic.StoreInt64(&available, 1)
+	for i := 0; i < 3; i++ {
+		if got, want := protocol(t, client), ProtocolV6; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	}
+	if got, want := atomic.LoadInt64(&requests), int64(2); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestClient_ProtocolCanceled(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := New(WithEndpoint("ws"+strings.TrimPrefix(server.URL, "http")), WithLogger(NopLogger), WithProtocol(ProtocolAuto))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := client.Protocol(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v; want %v", err, context.Canceled)
+	}
+}
+
+func readFixture(t *testing.T, path ...string) json.RawMessage {
+	data, err := ioutil.ReadFile(filepath.Join(path...))
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	return data
+}
+
+func TestClient_QueriesV6(t *testing.T) {
+	var (
+		mutex  sync.Mutex
+		params = map[string]json.RawMessage{}
+	)
+	endpoint, closer := fakeOgmiosV6(t, func(method string, p json.RawMessage) (interface{}, error) {
+		mutex.Lock()
+		params[method] = p
+		mutex.Unlock()
+
+		switch method {
+		case "queryLedgerState/tip":
+			return json.RawMessage(`{"slot":123,"id":"abc"}`), nil
+		case "queryLedgerState/epoch":
+			return 42, nil
+		case "queryLedgerState/protocolParameters":
+			return readFixture(t, "ouroboros/statequery/testdata/v6/protocolParameters.json"), nil
+		case "queryLedgerState/eraSummaries":
+			return readFixture(t, "ouroboros/statequery/testdata/v6/eraSummaries.json"), nil
+		case "queryLedgerState/eraStart":
+			return json.RawMessage(`{"time":{"seconds":30},"slot":1,"epoch":2}`), nil
+		case "queryLedgerState/utxo":
+			return json.RawMessage(`[{"transaction":{"id":"abc"},"index":1,"address":"addr","value":{"ada":{"lovelace":5}}}]`), nil
+		case "queryLedgerState/stakePools":
+			return json.RawMessage(`{"pool2":{"id":"pool2"},"pool1":{"id":"pool1"}}`), nil
+		case "queryLedgerState/liveStakeDistribution":
+			return json.RawMessage(`{"pool1":{"stake":"1/2","vrf":"def"}}`), nil
+		case "queryNetwork/blockHeight":
+			return 6916152, nil
+		case "queryNetwork/tip":
+			return json.RawMessage(`{"slot":456,"id":"def"}`), nil
+		case "queryNetwork/startTime":
+			return "2017-09-23T21:44:51Z", nil
+		default:
+			return nil, fmt.Errorf("unknown method, %v", method)
+		}
+	})
+	defer closer()
+
+	ctx := context.Background()
+	client := New(WithEndpoint(endpoint), WithLogger(NopLogger), WithProtocol(ProtocolV6))
+	defer client.Close()
+
+	t.Run("chainTip", func(t *testing.T) {
+		point, err := client.ChainTip(ctx)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if ps, _ := point.PointStruct(); ps.Slot != 123 || ps.Hash != "abc" {
+			t.Fatalf("got %v; want slot 123, hash abc", ps)
+		}
+	})
+
+	t.Run("currentEpoch", func(t *testing.T) {
+		epoch, err := client.CurrentEpoch(ctx)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := epoch, uint64(42); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("protocolParameters", func(t *testing.T) {
+		got, err := client.ProtocolParameters(ctx)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := got.MaxCollateralInputs, uint64(3); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+
+		raw, err := client.CurrentProtocolParameters(ctx)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		var v5 struct{ CoinsPerUtxoByte int64 }
+		if err := json.Unmarshal(raw, &v5); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := v5.CoinsPerUtxoByte, int64(4310); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("eraSummaries", func(t *testing.T) {
+		got, err := client.EraSummaries(ctx)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := len(got), 2; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("eraStart", func(t *testing.T) {
+		got, err := client.EraStart(ctx)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := got.Epoch, uint64(2); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("utxos", func(t *testing.T) {
+		utxos, err := client.UtxosByAddress(ctx, "addr")
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := utxos[0].TxOut.Value.Coins.Int64(), int64(5); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+
+		if _, err := client.UtxosByTxIn(ctx, chainsync.TxIn{TxHash: "abc", Index: 1}); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		if got, want := string(params["queryLedgerState/utxo"]), `{"outputReferences":[{"transaction":{"id":"abc"},"index":1}]}`; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("poolIds", func(t *testing.T) {
+		got, err := client.PoolIDs(ctx)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if want := []string{"pool1", "pool2"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("stakeDistribution", func(t *testing.T) {
+		got, err := client.StakeDistribution(ctx)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := got["pool1"].Stake.Float64(), 0.5; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("network", func(t *testing.T) {
+		height, err := client.BlockHeight(ctx)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := height, uint64(6916152); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+
+		point, err := client.NetworkTip(ctx)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if ps, _ := point.PointStruct(); ps.Slot != 456 {
+			t.Fatalf("got %v; want 456", ps.Slot)
+		}
+
+		start, err := client.SystemStart(ctx)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := start.Year(), 2017; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("not supported", func(t *testing.T) {
+		if _, err := client.GenesisConfig(ctx); err == nil {
+			t.Fatalf("got nil; want error")
+		}
+	})
+}
+
+func TestClient_SubmitTxV6(t *testing.T) {
+	endpoint, closer := fakeOgmiosV6(t, func(method string, params json.RawMessage) (interface{}, error) {
+		var content struct {
+			Transaction struct{ CBOR string }
+		}
+		if err := json.Unmarshal(params, &content); err != nil {
+			return nil, err
+		}
+
+		switch {
+		case method == "submitTransaction" && content.Transaction.CBOR == "84a3008001800200a0f5f6":
+			return nil, rpcError{Code: 3122, Message: "Insufficient fee", Data: Map{"minimumRequiredFee": Map{"ada": Map{"lovelace": 100}}, "providedFee": Map{"ada": Map{"lovelace": 50}}}}
+		case method == "evaluateTransaction" && content.Transaction.CBOR == "84a3008001800200a0f5f7":
+			return nil, rpcError{Code: 3000, Message: "Incompatible era", Data: Map{"incompatibleEra": "mary"}}
+		case method == "evaluateTransaction":
+			return json.RawMessage(`[{"validator":{"purpose":"spend","index":0},"budget":{"memory":10,"cpu":20}}]`), nil
+		default:
+			return nil, fmt.Errorf("unexpected request, %v", method)
+		}
+	})
+	defer closer()
+
+	ctx := context.Background()
+	client := New(WithEndpoint(endpoint), WithLogger(NopLogger), WithProtocol(ProtocolV6))
+	defer client.Close()
+
+	err := client.SubmitTx(ctx, []byte(`{"cborHex":"84a3008001800200a0f5f6"}`))
+	var e FeeTooSmallError
+	if !errors.As(err, &e) {
+		t.Fatalf("got %v; want FeeTooSmallError", err)
+	}
+	if got, want := e.RequiredFee.Int64(), int64(100); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := e.ActualFee.Int64(), int64(50); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	_, err = client.EvaluateTx(ctx, []byte("84a3008001800200a0f5f7"))
+	var evaluateTxError EvaluateTxError
+	if !errors.As(err, &evaluateTxError) {
+		t.Fatalf("got %v; want EvaluateTxError", err)
+	}
+	if got, want := evaluateTxError.IncompatibleEra, "mary"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	units, err := client.EvaluateTx(ctx, []byte("84a3008001800200a0f5f6"))
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := units["spend:0"].Steps, uint64(20); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestClient_AcquireV6(t *testing.T) {
+	endpoint, closer := fakeOgmiosV6(t, func(method string, params json.RawMessage) (interface{}, error) {
+		switch method {
+		case "acquireLedgerState":
+			var content struct{ Point chainsync.PointV6 }
+			if err := json.Unmarshal(params, &content); err != nil {
+				return nil, err
+			}
+			if ps, ok := chainsync.Point(content.Point).PointStruct(); ok && ps.Slot == 1 {
+				return nil, rpcError{Code: 2000, Message: "Failed to acquire requested point.", Data: "Target point is too old."}
+			}
+			if ps, ok := chainsync.Point(content.Point).PointStruct(); ok && ps.Slot == 3 {
+				return nil, rpcError{Code: 2000, Message: "Failed to acquire requested point.", Data: Map{"reason": "Target point doesn't exist on chain."}}
+			}
+			if ps, ok := chainsync.Point(content.Point).PointStruct(); ok && ps.Slot == 4 {
+				return nil, rpcError{Code: 2000, Message: "Failed to acquire requested point.", Data: Map{"tip": 123}}
+			}
+			return Map{"acquired": "ledgerState", "point": content.Point}, nil
+		case "queryLedgerState/epoch":
+			return 42, nil
+		case "releaseLedgerState":
+			return Map{"released": "ledgerState"}, nil
+		default:
+			return nil, fmt.Errorf("unexpected request, %v", method)
+		}
+	})
+	defer closer()
+
+	ctx := context.Background()
+	client := New(WithEndpoint(endpoint), WithLogger(NopLogger), WithProtocol(ProtocolV6))
+	defer client.Close()
+
+	_, err := client.Acquire(ctx, chainsync.PointStruct{Hash: "abc", Slot: 1}.Point())
+	var acquireError AcquireError
+	if !errors.As(err, &acquireError) {
+		t.Fatalf("got %v; want AcquireError", err)
+	}
+	if got, want := acquireError.Failure, AcquireFailurePointTooOld; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	_, err = client.Acquire(ctx, chainsync.PointStruct{Hash: "abc", Slot: 3}.Point())
+	if !errors.As(err, &acquireError) {
+		t.Fatalf("got %v; want AcquireError", err)
+	}
+	if got, want := acquireError.Failure, AcquireFailurePointNotOnChain; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	// failures without a reason are reported raw rather than guessed
+	_, err = client.Acquire(ctx, chainsync.PointStruct{Hash: "abc", Slot: 4}.Point())
+	if !errors.As(err, &acquireError) {
+		t.Fatalf("got %v; want AcquireError", err)
+	}
+	if got, want := acquireError.Failure, `{"tip":123}`; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	session, err := client.Acquire(ctx, chainsync.PointStruct{Hash: "abc", Slot: 2}.Point())
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	epoch, err := session.CurrentEpoch(ctx)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := epoch, uint64(42); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if err := session.Release(ctx); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+}
+
+func TestClient_MempoolMonitorV6(t *testing.T) {
+	endpoint, closer := fakeOgmiosV6(t, func(method string, params json.RawMessage) (interface{}, error) {
+		switch method {
+		case "acquireMempool":
+			return Map{"acquired": "mempool", "slot": 123}, nil
+		case "nextTransaction":
+			return Map{"transaction": nil}, nil
+		case "hasTransaction":
+			return true, nil
+		case "sizeOfMempool":
+			return json.RawMessage(`{"maxCapacity":{"bytes":100},"currentSize":{"bytes":10},"transactions":{"count":2}}`), nil
+		case "releaseMempool":
+			return Map{"released": "mempool"}, nil
+		default:
+			return nil, fmt.Errorf("unexpected request, %v", method)
+		}
+	})
+	defer closer()
+
+	ctx := context.Background()
+	client := New(WithEndpoint(endpoint), WithLogger(NopLogger), WithProtocol(ProtocolV6))
+	monitor, err := client.MempoolMonitor(ctx)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	defer monitor.Close()
+
+	slot, err := monitor.AwaitAcquire(ctx)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := slot, uint64(123); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if _, ok, err := monitor.NextTx(ctx); err != nil || ok {
+		t.Fatalf("got %v, %v; want false, nil", ok, err)
+	}
+	if ok, err := monitor.HasTx(ctx, "abc"); err != nil || !ok {
+		t.Fatalf("got %v, %v; want true, nil", ok, err)
+	}
+	size, err := monitor.SizeAndCapacity(ctx)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := size.NumberOfTxs, uint64(2); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if err := monitor.Release(ctx); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+}
+
+func TestClient_ChainSyncV6(t *testing.T) {
+	var (
+		mutex  sync.Mutex
+		blocks = []string{
+			`{"direction":"forward","block":{"era":"babbage","id":"b100","height":1,"slot":100},"tip":{"slot":300,"id":"b300","height":3}}`,
+			`{"direction":"forward","block":{"era":"conway","id":"b200","height":2,"slot":200},"tip":{"slot":300,"id":"b300","height":3}}`,
+			`{"direction":"backward","point":{"slot":100,"id":"b100"},"tip":{"slot":300,"id":"b300","height":3}}`,
+		}
+	)
+	endpoint, closer := fakeOgmiosV6(t, func(method string, params json.RawMessage) (interface{}, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		switch method {
+		case "findIntersection":
+			return json.RawMessage(`{"intersection":"origin","tip":{"slot":300,"id":"b300","height":3}}`), nil
+		case "nextBlock":
+			if len(blocks) == 0 {
+				return nil, errNoResponse
+			}
+			var block string
+			block, blocks = blocks[0], blocks[1:]
+			return json.RawMessage(block), nil
+		default:
+			return nil, fmt.Errorf("unexpected request, %v", method)
+		}
+	})
+	defer closer()
+
+	var (
+		ctx     = context.Background()
+		client  = New(WithEndpoint(endpoint), WithLogger(NopLogger), WithProtocol(ProtocolV6))
+		handler = &recordingHandler{}
+	)
+
+	cs, err := client.ChainSyncWithHandler(ctx, handler)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	want := []string{"found:origin", "forward:100", "forward:200", "backward:100"}
+	waitFor(t, func() bool { return len(handler.Events()) == len(want) })
+	if err := cs.Close(); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	if got := handler.Events(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
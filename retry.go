@@ -0,0 +1,151 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// defaultRetryPolicy reconnects every 10s for as long as errors are temporary
+var defaultRetryPolicy = ExponentialBackoff{
+	InitialDelay: 10 * time.Second,
+	Multiplier:   1,
+}
+
+// RetryPolicy determines if and when ChainSync reconnects to ogmios
+type RetryPolicy interface {
+	// Retry returns the delay before reconnecting or false to stop.  attempt counts
+	// consecutive failed connections starting from 1 and elapsed is the time since
+	// the first of those failures
+	Retry(attempt int, elapsed time.Duration, err error) (time.Duration, bool)
+}
+
+// RetryPolicyFunc allows a func to be used as a RetryPolicy
+type RetryPolicyFunc func(attempt int, elapsed time.Duration, err error) (time.Duration, bool)
+
+// Retry implements RetryPolicy
+func (fn RetryPolicyFunc) Retry(attempt int, elapsed time.Duration, err error) (time.Duration, bool) {
+	return fn(attempt, elapsed, err)
+}
+
+// ExponentialBackoff provides a RetryPolicy whose delay grows by Multiplier with
+// each attempt
+type ExponentialBackoff struct {
+	InitialDelay time.Duration        // InitialDelay before the first attempt; defaults to 1s
+	MaxDelay     time.Duration        // MaxDelay caps the delay; 0 for no cap
+	Multiplier   float64              // Multiplier applied per attempt; defaults to 2
+	Jitter       float64              // Jitter randomizes the delay by +/- the fraction provided e.g. 0.2
+	MaxAttempts  int                  // MaxAttempts before giving up; 0 for unlimited
+	MaxElapsed   time.Duration        // MaxElapsed time before giving up; 0 for unlimited
+	Retryable    func(err error) bool // Retryable classifies errors; defaults to IsTemporaryError
+}
+
+// Retry implements RetryPolicy
+func (e ExponentialBackoff) Retry(attempt int, elapsed time.Duration, err error) (time.Duration, bool) {
+	retryable := e.Retryable
+	if retryable == nil {
+		retryable = IsTemporaryError
+	}
+	if !retryable(err) {
+		return 0, false
+	}
+	if e.MaxAttempts > 0 && attempt > e.MaxAttempts {
+		return 0, false
+	}
+	if e.MaxElapsed > 0 && elapsed > e.MaxElapsed {
+		return 0, false
+	}
+
+	var (
+		delay      = e.InitialDelay
+		multiplier = e.Multiplier
+	)
+	if delay <= 0 {
+		delay = time.Second
+	}
+	if multiplier <= 0 {
+		multiplier = 2
+	}
+
+	d := float64(delay)
+	for i := 1; i < attempt; i++ {
+		d *= multiplier
+		if e.MaxDelay > 0 && d > float64(e.MaxDelay) {
+			break
+		}
+	}
+	if e.Jitter > 0 {
+		d += d * e.Jitter * (2*rand.Float64() - 1)
+	}
+	if e.MaxDelay > 0 && d > float64(e.MaxDelay) {
+		d = float64(e.MaxDelay)
+	}
+
+	return time.Duration(d), true
+}
+
+// ReconnectEvent describes a single attempt by ChainSync to reconnect
+type ReconnectEvent struct {
+	Attempt int           // Attempt counts consecutive failed connections starting from 1
+	Delay   time.Duration // Delay before reconnecting
+	Elapsed time.Duration // Elapsed time since the first failed connection
+	Err     error         // Err that caused the reconnect
+}
+
+// IsTemporaryError returns true if the error is recoverable
+func IsTemporaryError(err error) bool {
+	var wce *websocket.CloseError
+	if ok := errors.As(err, &wce); ok {
+		switch wce.Code {
+		case websocket.CloseAbnormalClosure,
+			websocket.CloseGoingAway,
+			websocket.CloseServiceRestart,
+			websocket.CloseTryAgainLater:
+			return true
+		}
+	}
+
+	if errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	var noe *net.OpError
+	if ok := errors.As(err, &noe); ok {
+		var sce *os.SyscallError
+		if ok := errors.As(noe.Err, &sce); ok && sce.Syscall == "connect" {
+			return true
+		}
+		return noe.Temporary()
+	}
+
+	// handle the generic temporary error
+	var temp interface{ Temporary() bool }
+	if ok := errors.As(err, &temp); ok {
+		return temp.Temporary()
+	}
+
+	return false
+}
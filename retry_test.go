@@ -0,0 +1,246 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestExponentialBackoff_Retry(t *testing.T) {
+	temporary := &websocket.CloseError{Code: websocket.CloseAbnormalClosure}
+
+	t.Run("delays", func(t *testing.T) {
+		policy := ExponentialBackoff{
+			InitialDelay: time.Second,
+			MaxDelay:     5 * time.Second,
+		}
+		want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+		for i, w := range want {
+			delay, ok := policy.Retry(i+1, 0, temporary)
+			if !ok {
+				t.Fatalf("got false; want true")
+			}
+			if got := delay; got != w {
+				t.Fatalf("got %v; want %v", got, w)
+			}
+		}
+	})
+
+	t.Run("jitter", func(t *testing.T) {
+		policy := ExponentialBackoff{
+			InitialDelay: time.Second,
+			Jitter:       0.5,
+		}
+		for i := 0; i < 100; i++ {
+			delay, _ := policy.Retry(1, 0, temporary)
+			if delay < 500*time.Millisecond || delay > 1500*time.Millisecond {
+				t.Fatalf("got %v; want between 500ms and 1.5s", delay)
+			}
+		}
+	})
+
+	t.Run("max attempts", func(t *testing.T) {
+		policy := ExponentialBackoff{MaxAttempts: 3}
+		if _, ok := policy.Retry(3, 0, temporary); !ok {
+			t.Fatalf("got false; want true")
+		}
+		if _, ok := policy.Retry(4, 0, temporary); ok {
+			t.Fatalf("got true; want false")
+		}
+	})
+
+	t.Run("max elapsed", func(t *testing.T) {
+		policy := ExponentialBackoff{MaxElapsed: time.Minute}
+		if _, ok := policy.Retry(1, time.Second, temporary); !ok {
+			t.Fatalf("got false; want true")
+		}
+		if _, ok := policy.Retry(1, 2*time.Minute, temporary); ok {
+			t.Fatalf("got true; want false")
+		}
+	})
+
+	t.Run("retryable", func(t *testing.T) {
+		policy := ExponentialBackoff{}
+		if _, ok := policy.Retry(1, 0, io.EOF); ok {
+			t.Fatalf("got true; want false")
+		}
+
+		policy.Retryable = func(err error) bool { return errors.Is(err, io.EOF) }
+		if _, ok := policy.Retry(1, 0, io.EOF); !ok {
+			t.Fatalf("got false; want true")
+		}
+	})
+}
+
+func TestIsTemporaryError(t *testing.T) {
+	tests := map[string]struct {
+		Err  error
+		Want bool
+	}{
+		"abnormal closure": {
+			Err:  &websocket.CloseError{Code: websocket.CloseAbnormalClosure},
+			Want: true,
+		},
+		"going away": {
+			Err:  fmt.Errorf("wrapped: %w", &websocket.CloseError{Code: websocket.CloseGoingAway}),
+			Want: true,
+		},
+		"normal closure": {
+			Err:  &websocket.CloseError{Code: websocket.CloseNormalClosure},
+			Want: false,
+		},
+		"connection reset": {
+			Err:  &net.OpError{Op: "read", Err: syscall.ECONNRESET},
+			Want: true,
+		},
+		"unexpected eof": {
+			Err:  io.ErrUnexpectedEOF,
+			Want: true,
+		},
+		"other": {
+			Err:  io.EOF,
+			Want: false,
+		},
+	}
+
+	for label, tc := range tests {
+		t.Run(label, func(t *testing.T) {
+			if got, want := IsTemporaryError(tc.Err), tc.Want; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestWithRetryPolicy(t *testing.T) {
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	endpoint := "ws://" + listener.Addr().String()
+	listener.Close() // ensure connections are refused
+
+	var (
+		mutex  sync.Mutex
+		events []ReconnectEvent
+		client = New(WithEndpoint(endpoint), WithLogger(NopLogger))
+		policy = ExponentialBackoff{
+			InitialDelay: time.Millisecond,
+			MaxAttempts:  2,
+		}
+	)
+
+	cs, err := client.ChainSync(context.Background(), func(context.Context, []byte) error { return nil },
+		WithRetryPolicy(policy),
+		WithReconnectFunc(func(event ReconnectEvent) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			events = append(events, event)
+		}),
+	)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	select {
+	case <-cs.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout waiting for chainsync to give up")
+	}
+
+	if err := cs.Close(); !errors.Is(err, syscall.ECONNREFUSED) {
+		t.Fatalf("got %v; want %v", err, syscall.ECONNREFUSED)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if got, want := len(events), 2; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	for i, event := range events {
+		if got, want := event.Attempt, i+1; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if event.Err == nil {
+			t.Fatalf("got nil; want not nil")
+		}
+	}
+}
+
+func TestWithRetryPolicy_ResetOnRead(t *testing.T) {
+	var upgrader = websocket.Upgrader{} // use default options
+
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	defer listener.Close()
+
+	// each connection delivers a block below the min slot then drops
+	var dials int64
+	go func() {
+		_ = http.Serve(listener, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			atomic.AddInt64(&dials, 1)
+			c, err := upgrader.Upgrade(w, req, nil)
+			if err != nil {
+				return
+			}
+			defer c.UnderlyingConn().Close()
+
+			var request json.RawMessage
+			if err := c.ReadJSON(&request); err != nil {
+				return
+			}
+			_ = c.WriteMessage(websocket.TextMessage, []byte(rollForward(100, 1, 1)))
+		}))
+	}()
+
+	var (
+		client = New(WithEndpoint("ws://"+listener.Addr().String()), WithLogger(NopLogger))
+		policy = ExponentialBackoff{
+			InitialDelay: time.Millisecond,
+			MaxAttempts:  1,
+		}
+	)
+	cs, err := client.ChainSync(context.Background(), func(context.Context, []byte) error { return nil },
+		WithMinSlot(1000),
+		WithRetryPolicy(policy),
+	)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	defer cs.Close()
+
+	// without a reset, the second failure would exceed MaxAttempts
+	waitFor(t, func() bool { return atomic.LoadInt64(&dials) >= 5 })
+	select {
+	case <-cs.Done():
+		t.Fatalf("got done; want chainsync to keep reconnecting")
+	default:
+	}
+}
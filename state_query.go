@@ -18,12 +18,21 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/savaki/ogmigo/ouroboros/chainsync"
 	"github.com/savaki/ogmigo/ouroboros/statequery"
 )
 
 func (c *Client) ChainTip(ctx context.Context) (chainsync.Point, error) {
+	v6, err := c.v6(ctx)
+	if err != nil {
+		return chainsync.Point{}, err
+	}
+	if v6 {
+		return c.chainTipV6(ctx)
+	}
+
 	var (
 		payload = makePayload("Query", Map{"query": "ledgerTip"})
 		content struct{ Result chainsync.Point }
@@ -37,6 +46,16 @@ func (c *Client) ChainTip(ctx context.Context) (chainsync.Point, error) {
 }
 
 func (c *Client) CurrentEpoch(ctx context.Context) (uint64, error) {
+	v6, err := c.v6(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if v6 {
+		var epoch uint64
+		err := c.queryV6(ctx, "queryLedgerState/epoch", nil, &epoch)
+		return epoch, err
+	}
+
 	var (
 		payload = makePayload("Query", Map{"query": "currentEpoch"})
 		content struct{ Result uint64 }
@@ -50,6 +69,18 @@ func (c *Client) CurrentEpoch(ctx context.Context) (uint64, error) {
 }
 
 func (c *Client) CurrentProtocolParameters(ctx context.Context) (json.RawMessage, error) {
+	v6, err := c.v6(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if v6 {
+		params, err := c.protocolParametersV6(ctx) // re-encoded as v5 for consistency
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(params)
+	}
+
 	var (
 		payload = makePayload("Query", Map{"query": "currentProtocolParameters"})
 		content struct{ Result json.RawMessage }
@@ -62,7 +93,100 @@ func (c *Client) CurrentProtocolParameters(ctx context.Context) (json.RawMessage
 	return content.Result, nil
 }
 
+// ProtocolParameters returns the current protocol parameters; equivalent to
+// CurrentProtocolParameters, but decoded
+func (c *Client) ProtocolParameters(ctx context.Context) (statequery.ProtocolParameters, error) {
+	v6, err := c.v6(ctx)
+	if err != nil {
+		return statequery.ProtocolParameters{}, err
+	}
+	if v6 {
+		params, err := c.protocolParametersV6(ctx)
+		if err != nil {
+			return statequery.ProtocolParameters{}, fmt.Errorf("failed to query protocol parameters: %w", err)
+		}
+		return params, nil
+	}
+
+	var (
+		payload = makePayload("Query", Map{"query": "currentProtocolParameters"})
+		content struct{ Result statequery.ProtocolParameters }
+	)
+
+	if err := c.query(ctx, payload, &content); err != nil {
+		return statequery.ProtocolParameters{}, fmt.Errorf("failed to query protocol parameters: %w", err)
+	}
+
+	return content.Result, nil
+}
+
+func (c *Client) DelegationsAndRewards(ctx context.Context, stakeKeyHashes ...string) (map[string]statequery.DelegationsAndRewards, error) {
+	v6, err := c.v6(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if v6 {
+		return nil, errNotSupportedV6("DelegationsAndRewards")
+	}
+
+	var (
+		payload = makePayload("Query", Map{"query": Map{"delegationsAndRewards": stakeKeyHashes}})
+		content struct {
+			Result map[string]statequery.DelegationsAndRewards
+		}
+	)
+
+	if err := c.query(ctx, payload, &content); err != nil {
+		return nil, fmt.Errorf("failed to query delegations and rewards: %w", err)
+	}
+
+	return content.Result, nil
+}
+
+func (c *Client) BlockHeight(ctx context.Context) (uint64, error) {
+	v6, err := c.v6(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if v6 {
+		height, err := c.blockHeightV6(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("failed to query block height: %w", err)
+		}
+		return height, nil
+	}
+
+	var (
+		payload = makePayload("Query", Map{"query": "blockHeight"})
+		content struct{ Result json.RawMessage }
+	)
+
+	if err := c.query(ctx, payload, &content); err != nil {
+		return 0, fmt.Errorf("failed to query block height: %w", err)
+	}
+
+	var height uint64
+	if string(content.Result) == `"origin"` {
+		return height, nil
+	}
+	if err := json.Unmarshal(content.Result, &height); err != nil {
+		return 0, fmt.Errorf("failed to decode block height: %w", err)
+	}
+
+	return height, nil
+}
+
 func (c *Client) EraStart(ctx context.Context) (statequery.EraStart, error) {
+	v6, err := c.v6(ctx)
+	if err != nil {
+		return statequery.EraStart{}, err
+	}
+	if v6 {
+		var eraStart statequery.EraStart
+		err := c.queryV6(ctx, "queryLedgerState/eraStart", nil, &eraStart)
+		return eraStart, err
+	}
+
 	var (
 		payload = makePayload("Query", Map{"query": "eraStart"})
 		content struct{ Result statequery.EraStart }
@@ -75,7 +199,297 @@ func (c *Client) EraStart(ctx context.Context) (statequery.EraStart, error) {
 	return content.Result, nil
 }
 
+func (c *Client) EraSummaries(ctx context.Context) ([]statequery.EraSummary, error) {
+	v6, err := c.v6(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if v6 {
+		var summaries []statequery.EraSummary
+		if err := c.queryV6(ctx, "queryLedgerState/eraSummaries", nil, &summaries); err != nil {
+			return nil, fmt.Errorf("failed to query era summaries: %w", err)
+		}
+		return summaries, nil
+	}
+
+	var (
+		payload = makePayload("Query", Map{"query": "eraSummaries"})
+		content struct{ Result []statequery.EraSummary }
+	)
+
+	if err := c.query(ctx, payload, &content); err != nil {
+		return nil, fmt.Errorf("failed to query era summaries: %w", err)
+	}
+
+	return content.Result, nil
+}
+
+func (c *Client) GenesisConfig(ctx context.Context) (statequery.GenesisConfig, error) {
+	v6, err := c.v6(ctx)
+	if err != nil {
+		return statequery.GenesisConfig{}, err
+	}
+	if v6 {
+		return statequery.GenesisConfig{}, errNotSupportedV6("GenesisConfig")
+	}
+
+	var (
+		payload = makePayload("Query", Map{"query": "genesisConfig"})
+		content struct{ Result statequery.GenesisConfig }
+	)
+
+	if err := c.query(ctx, payload, &content); err != nil {
+		return statequery.GenesisConfig{}, fmt.Errorf("failed to query genesis config: %w", err)
+	}
+
+	return content.Result, nil
+}
+
+// NetworkTip returns the tip of the chain known to the node via the chainTip
+// query.  Unlike ChainTip, which returns the tip of the ledger, the network tip
+// may run ahead of the ledger
+func (c *Client) NetworkTip(ctx context.Context) (chainsync.Point, error) {
+	v6, err := c.v6(ctx)
+	if err != nil {
+		return chainsync.Point{}, err
+	}
+	if v6 {
+		point, err := c.networkTipV6(ctx)
+		if err != nil {
+			return chainsync.Point{}, fmt.Errorf("failed to query chain tip: %w", err)
+		}
+		return point, nil
+	}
+
+	var (
+		payload = makePayload("Query", Map{"query": "chainTip"})
+		content struct{ Result chainsync.Point }
+	)
+
+	if err := c.query(ctx, payload, &content); err != nil {
+		return chainsync.Point{}, fmt.Errorf("failed to query chain tip: %w", err)
+	}
+
+	return content.Result, nil
+}
+
+// NonMyopicMemberRewards returns the rewards a delegator can expect from each
+// pool for each of the provided stake amounts, in lovelace
+func (c *Client) NonMyopicMemberRewards(ctx context.Context, lovelace ...uint64) (statequery.NonMyopicMemberRewards, error) {
+	return c.nonMyopicMemberRewards(ctx, lovelace)
+}
+
+// NonMyopicMemberRewardsByCredential returns the rewards each of the provided
+// stake credentials can expect from each pool
+func (c *Client) NonMyopicMemberRewardsByCredential(ctx context.Context, credentials ...string) (statequery.NonMyopicMemberRewards, error) {
+	return c.nonMyopicMemberRewards(ctx, credentials)
+}
+
+func (c *Client) nonMyopicMemberRewards(ctx context.Context, inputs interface{}) (statequery.NonMyopicMemberRewards, error) {
+	v6, err := c.v6(ctx)
+	if err != nil {
+		return statequery.NonMyopicMemberRewards{}, err
+	}
+	if v6 {
+		return nil, errNotSupportedV6("NonMyopicMemberRewards")
+	}
+
+	var (
+		payload = makePayload("Query", Map{"query": Map{"nonMyopicMemberRewards": inputs}})
+		content struct {
+			Result statequery.NonMyopicMemberRewards
+		}
+	)
+
+	if err := c.query(ctx, payload, &content); err != nil {
+		return nil, fmt.Errorf("failed to query non myopic member rewards: %w", err)
+	}
+
+	return content.Result, nil
+}
+
+func (c *Client) PoolIDs(ctx context.Context) ([]string, error) {
+	v6, err := c.v6(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if v6 {
+		ids, err := c.poolIDsV6(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query pool ids: %w", err)
+		}
+		return ids, nil
+	}
+
+	var (
+		payload = makePayload("Query", Map{"query": "poolIds"})
+		content struct{ Result []string }
+	)
+
+	if err := c.query(ctx, payload, &content); err != nil {
+		return nil, fmt.Errorf("failed to query pool ids: %w", err)
+	}
+
+	return content.Result, nil
+}
+
+func (c *Client) PoolParameters(ctx context.Context, poolIDs ...string) (map[string]statequery.PoolParameters, error) {
+	v6, err := c.v6(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if v6 {
+		return nil, errNotSupportedV6("PoolParameters")
+	}
+
+	var (
+		payload = makePayload("Query", Map{"query": Map{"poolParameters": poolIDs}})
+		content struct {
+			Result map[string]statequery.PoolParameters
+		}
+	)
+
+	if err := c.query(ctx, payload, &content); err != nil {
+		return nil, fmt.Errorf("failed to query pool parameters: %w", err)
+	}
+
+	return content.Result, nil
+}
+
+func (c *Client) PoolsRanking(ctx context.Context) (map[string]statequery.PoolRanking, error) {
+	v6, err := c.v6(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if v6 {
+		return nil, errNotSupportedV6("PoolsRanking")
+	}
+
+	var (
+		payload = makePayload("Query", Map{"query": "poolsRanking"})
+		content struct {
+			Result map[string]statequery.PoolRanking
+		}
+	)
+
+	if err := c.query(ctx, payload, &content); err != nil {
+		return nil, fmt.Errorf("failed to query pools ranking: %w", err)
+	}
+
+	return content.Result, nil
+}
+
+// ProposedProtocolParameters returns the protocol parameter updates proposed
+// by each genesis delegate.  Only the proposed parameters will be set
+func (c *Client) ProposedProtocolParameters(ctx context.Context) (map[string]statequery.ProtocolParameters, error) {
+	v6, err := c.v6(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if v6 {
+		return nil, errNotSupportedV6("ProposedProtocolParameters")
+	}
+
+	var (
+		payload = makePayload("Query", Map{"query": "proposedProtocolParameters"})
+		content struct {
+			Result map[string]statequery.ProtocolParameters
+		}
+	)
+
+	if err := c.query(ctx, payload, &content); err != nil {
+		return nil, fmt.Errorf("failed to query proposed protocol parameters: %w", err)
+	}
+
+	return content.Result, nil
+}
+
+func (c *Client) RewardsProvenance(ctx context.Context) (statequery.RewardsProvenance, error) {
+	v6, err := c.v6(ctx)
+	if err != nil {
+		return statequery.RewardsProvenance{}, err
+	}
+	if v6 {
+		return statequery.RewardsProvenance{}, errNotSupportedV6("RewardsProvenance")
+	}
+
+	var (
+		payload = makePayload("Query", Map{"query": "rewardsProvenance"})
+		content struct{ Result statequery.RewardsProvenance }
+	)
+
+	if err := c.query(ctx, payload, &content); err != nil {
+		return statequery.RewardsProvenance{}, fmt.Errorf("failed to query rewards provenance: %w", err)
+	}
+
+	return content.Result, nil
+}
+
+func (c *Client) StakeDistribution(ctx context.Context) (map[string]statequery.PoolDistribution, error) {
+	v6, err := c.v6(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if v6 {
+		var distribution map[string]statequery.PoolDistribution
+		if err := c.queryV6(ctx, "queryLedgerState/liveStakeDistribution", nil, &distribution); err != nil {
+			return nil, fmt.Errorf("failed to query stake distribution: %w", err)
+		}
+		return distribution, nil
+	}
+
+	var (
+		payload = makePayload("Query", Map{"query": "stakeDistribution"})
+		content struct {
+			Result map[string]statequery.PoolDistribution
+		}
+	)
+
+	if err := c.query(ctx, payload, &content); err != nil {
+		return nil, fmt.Errorf("failed to query stake distribution: %w", err)
+	}
+
+	return content.Result, nil
+}
+
+func (c *Client) SystemStart(ctx context.Context) (time.Time, error) {
+	v6, err := c.v6(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if v6 {
+		t, err := c.systemStartV6(ctx)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to query system start: %w", err)
+		}
+		return t, nil
+	}
+
+	var (
+		payload = makePayload("Query", Map{"query": "systemStart"})
+		content struct{ Result time.Time }
+	)
+
+	if err := c.query(ctx, payload, &content); err != nil {
+		return time.Time{}, fmt.Errorf("failed to query system start: %w", err)
+	}
+
+	return content.Result, nil
+}
+
 func (c *Client) UtxosByAddress(ctx context.Context, addresses ...string) ([]statequery.Utxo, error) {
+	v6, err := c.v6(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if v6 {
+		utxos, err := c.utxosV6(ctx, Map{"addresses": addresses})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query utxos by address: %w", err)
+		}
+		return utxos, nil
+	}
+
 	var (
 		payload = makePayload("Query", Map{"query": Map{"utxo": addresses}})
 		content struct{ Result []statequery.Utxo }
@@ -89,6 +503,22 @@ func (c *Client) UtxosByAddress(ctx context.Context, addresses ...string) ([]sta
 }
 
 func (c *Client) UtxosByTxIn(ctx context.Context, txIns ...chainsync.TxIn) ([]statequery.Utxo, error) {
+	v6, err := c.v6(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if v6 {
+		refs := make([]chainsync.TxInV6, 0, len(txIns))
+		for _, txIn := range txIns {
+			refs = append(refs, chainsync.TxInV6(txIn))
+		}
+		utxos, err := c.utxosV6(ctx, Map{"outputReferences": refs})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query utxos by tx in: %w", err)
+		}
+		return utxos, nil
+	}
+
 	var (
 		payload = makePayload("Query", Map{"query": Map{"utxo": txIns}})
 		content struct{ Result []statequery.Utxo }
@@ -0,0 +1,310 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/savaki/ogmigo/ouroboros/chainsync"
+	"github.com/savaki/ogmigo/ouroboros/statequery"
+)
+
+const (
+	// AcquireFailurePointTooOld indicates the point is further back than the
+	// node is able to serve state for
+	AcquireFailurePointTooOld = "pointTooOld"
+	// AcquireFailurePointNotOnChain indicates the point is not on the node's chain
+	AcquireFailurePointNotOnChain = "pointNotOnChain"
+)
+
+// AcquireError is returned when ogmios refuses to acquire the requested point
+type AcquireError struct {
+	Point   chainsync.Point
+	Failure string
+}
+
+func (e AcquireError) Error() string {
+	return fmt.Sprintf("failed to acquire point, %v: %v", e.Point, e.Failure)
+}
+
+// StateQuerySession runs local state queries against the ledger state at a
+// fixed point.  The state query methods of Client are available and observe
+// the same snapshot.  Each session holds its own connection to ogmios; callers
+// must Release the session when done
+type StateQuerySession struct {
+	client *Client // queries the pinned connection
+	conn   *conn
+	point  chainsync.Point
+}
+
+// Acquire opens a state query session pinned to the provided point.  Use
+// ChainTip to acquire the current tip.  Returns an AcquireError if ogmios is
+// unable to acquire the point
+func (c *Client) Acquire(ctx context.Context, point chainsync.Point) (*StateQuerySession, error) {
+	conn, err := c.pool.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	session := &StateQuerySession{
+		client: &Client{
+			logger:   c.logger,
+			options:  c.options,
+			pool:     pinnedPool(c.options.endpoint, conn),
+			protocol: c.protocol,
+		},
+		conn:  conn,
+		point: point,
+	}
+	if err := session.acquire(ctx, point); err != nil {
+		conn.close(errClientClosed)
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// Acquire moves the session to a new point.  On failure, the session should
+// be released as the ledger state it refers to is undefined
+func (s *StateQuerySession) Acquire(ctx context.Context, point chainsync.Point) error {
+	if err := s.acquire(ctx, point); err != nil {
+		return err
+	}
+	s.point = point
+	return nil
+}
+
+func (s *StateQuerySession) acquire(ctx context.Context, point chainsync.Point) error {
+	v6, err := s.client.v6(ctx)
+	if err != nil {
+		return err
+	}
+	if v6 {
+		return s.acquireV6(ctx, point)
+	}
+
+	var (
+		payload = makePayload("Acquire", Map{"point": point})
+		content struct {
+			Result struct {
+				AcquireSuccess *struct{ Point chainsync.Point }
+				AcquireFailure *struct{ Failure string }
+			}
+		}
+	)
+
+	if err := s.conn.query(ctx, payload, &content); err != nil {
+		return fmt.Errorf("failed to acquire point, %v: %w", point, err)
+	}
+	if failure := content.Result.AcquireFailure; failure != nil {
+		return AcquireError{Point: point, Failure: failure.Failure}
+	}
+	if content.Result.AcquireSuccess == nil {
+		return fmt.Errorf("failed to acquire point, %v: unexpected response", point)
+	}
+
+	return nil
+}
+
+// acquire failures as reported by ogmios v6; error code 2000 with the reason
+// held by the error data
+const (
+	acquireFailureV6               = "2000"
+	acquireReasonPointTooOldV6     = "Target point is too old."
+	acquireReasonPointNotOnChainV6 = "Target point doesn't exist on chain."
+)
+
+func (s *StateQuerySession) acquireV6(ctx context.Context, point chainsync.Point) error {
+	payload := makeRPC("acquireLedgerState", Map{"point": chainsync.PointV6(point)})
+	if err := s.conn.query(ctx, payload, nil); err != nil {
+		var e Error
+		if errors.As(err, &e) && e.Fault.Code == acquireFailureV6 {
+			return AcquireError{Point: point, Failure: acquireFailureReasonV6(e.Fault.Data)}
+		}
+		return fmt.Errorf("failed to acquire point, %v: %w", point, err)
+	}
+	return nil
+}
+
+// acquireFailureReasonV6 maps the data of a v6 acquire failure, either the
+// reason or an object holding the reason, to the v5 failure.  Unknown reasons
+// are returned as is and data without a reason is returned raw
+func acquireFailureReasonV6(data json.RawMessage) string {
+	var reason string
+	if err := json.Unmarshal(data, &reason); err != nil {
+		var content struct{ Reason string }
+		_ = json.Unmarshal(data, &content)
+		reason = content.Reason
+	}
+
+	switch reason {
+	case acquireReasonPointTooOldV6:
+		return AcquireFailurePointTooOld
+	case acquireReasonPointNotOnChainV6:
+		return AcquireFailurePointNotOnChain
+	case "":
+		return string(data)
+	default:
+		return reason
+	}
+}
+
+// Point returns the point the session is pinned to
+func (s *StateQuerySession) Point() chainsync.Point {
+	return s.point
+}
+
+// Release releases the acquired point and closes the connection held by the
+// session
+func (s *StateQuerySession) Release(ctx context.Context) error {
+	defer s.conn.close(errClientClosed)
+
+	var (
+		payload = makePayload("Release", Map{})
+		content struct{ Result json.RawMessage }
+	)
+	v6, err := s.client.v6(ctx)
+	if err != nil {
+		return err
+	}
+	if v6 {
+		payload = makeRPC("releaseLedgerState", nil)
+	}
+
+	if err := s.conn.query(ctx, payload, &content); err != nil {
+		return fmt.Errorf("failed to release point, %v: %w", s.point, err)
+	}
+
+	return nil
+}
+
+// Close closes the connection held by the session without releasing; the
+// point is implicitly released by ogmios
+func (s *StateQuerySession) Close() error {
+	s.conn.close(errClientClosed)
+	return nil
+}
+
+// ChainTip invokes Client.ChainTip against the acquired point
+func (s *StateQuerySession) ChainTip(ctx context.Context) (chainsync.Point, error) {
+	return s.client.ChainTip(ctx)
+}
+
+// CurrentEpoch invokes Client.CurrentEpoch against the acquired point
+func (s *StateQuerySession) CurrentEpoch(ctx context.Context) (uint64, error) {
+	return s.client.CurrentEpoch(ctx)
+}
+
+// CurrentProtocolParameters invokes Client.CurrentProtocolParameters against the acquired point
+func (s *StateQuerySession) CurrentProtocolParameters(ctx context.Context) (json.RawMessage, error) {
+	return s.client.CurrentProtocolParameters(ctx)
+}
+
+// ProtocolParameters invokes Client.ProtocolParameters against the acquired point
+func (s *StateQuerySession) ProtocolParameters(ctx context.Context) (statequery.ProtocolParameters, error) {
+	return s.client.ProtocolParameters(ctx)
+}
+
+// DelegationsAndRewards invokes Client.DelegationsAndRewards against the acquired point
+func (s *StateQuerySession) DelegationsAndRewards(ctx context.Context, stakeKeyHashes ...string) (map[string]statequery.DelegationsAndRewards, error) {
+	return s.client.DelegationsAndRewards(ctx, stakeKeyHashes...)
+}
+
+// BlockHeight invokes Client.BlockHeight.  The block height is read from the network and
+// does not depend on the acquired point
+func (s *StateQuerySession) BlockHeight(ctx context.Context) (uint64, error) {
+	return s.client.BlockHeight(ctx)
+}
+
+// EraStart invokes Client.EraStart against the acquired point
+func (s *StateQuerySession) EraStart(ctx context.Context) (statequery.EraStart, error) {
+	return s.client.EraStart(ctx)
+}
+
+// EraSummaries invokes Client.EraSummaries against the acquired point
+func (s *StateQuerySession) EraSummaries(ctx context.Context) ([]statequery.EraSummary, error) {
+	return s.client.EraSummaries(ctx)
+}
+
+// GenesisConfig invokes Client.GenesisConfig against the acquired point
+func (s *StateQuerySession) GenesisConfig(ctx context.Context) (statequery.GenesisConfig, error) {
+	return s.client.GenesisConfig(ctx)
+}
+
+// NetworkTip invokes Client.NetworkTip.  The network tip is read from the network and
+// does not depend on the acquired point
+func (s *StateQuerySession) NetworkTip(ctx context.Context) (chainsync.Point, error) {
+	return s.client.NetworkTip(ctx)
+}
+
+// NonMyopicMemberRewards invokes Client.NonMyopicMemberRewards against the acquired point
+func (s *StateQuerySession) NonMyopicMemberRewards(ctx context.Context, lovelace ...uint64) (statequery.NonMyopicMemberRewards, error) {
+	return s.client.NonMyopicMemberRewards(ctx, lovelace...)
+}
+
+// NonMyopicMemberRewardsByCredential invokes Client.NonMyopicMemberRewardsByCredential against the acquired point
+func (s *StateQuerySession) NonMyopicMemberRewardsByCredential(ctx context.Context, credentials ...string) (statequery.NonMyopicMemberRewards, error) {
+	return s.client.NonMyopicMemberRewardsByCredential(ctx, credentials...)
+}
+
+// PoolIDs invokes Client.PoolIDs against the acquired point
+func (s *StateQuerySession) PoolIDs(ctx context.Context) ([]string, error) {
+	return s.client.PoolIDs(ctx)
+}
+
+// PoolParameters invokes Client.PoolParameters against the acquired point
+func (s *StateQuerySession) PoolParameters(ctx context.Context, poolIDs ...string) (map[string]statequery.PoolParameters, error) {
+	return s.client.PoolParameters(ctx, poolIDs...)
+}
+
+// PoolsRanking invokes Client.PoolsRanking against the acquired point
+func (s *StateQuerySession) PoolsRanking(ctx context.Context) (map[string]statequery.PoolRanking, error) {
+	return s.client.PoolsRanking(ctx)
+}
+
+// ProposedProtocolParameters invokes Client.ProposedProtocolParameters against the acquired point
+func (s *StateQuerySession) ProposedProtocolParameters(ctx context.Context) (map[string]statequery.ProtocolParameters, error) {
+	return s.client.ProposedProtocolParameters(ctx)
+}
+
+// RewardsProvenance invokes Client.RewardsProvenance against the acquired point
+func (s *StateQuerySession) RewardsProvenance(ctx context.Context) (statequery.RewardsProvenance, error) {
+	return s.client.RewardsProvenance(ctx)
+}
+
+// StakeDistribution invokes Client.StakeDistribution against the acquired point
+func (s *StateQuerySession) StakeDistribution(ctx context.Context) (map[string]statequery.PoolDistribution, error) {
+	return s.client.StakeDistribution(ctx)
+}
+
+// SystemStart invokes Client.SystemStart.  The system start is read from the network and
+// does not depend on the acquired point
+func (s *StateQuerySession) SystemStart(ctx context.Context) (time.Time, error) {
+	return s.client.SystemStart(ctx)
+}
+
+// UtxosByAddress invokes Client.UtxosByAddress against the acquired point
+func (s *StateQuerySession) UtxosByAddress(ctx context.Context, addresses ...string) ([]statequery.Utxo, error) {
+	return s.client.UtxosByAddress(ctx, addresses...)
+}
+
+// UtxosByTxIn invokes Client.UtxosByTxIn against the acquired point
+func (s *StateQuerySession) UtxosByTxIn(ctx context.Context, txIns ...chainsync.TxIn) ([]statequery.Utxo, error) {
+	return s.client.UtxosByTxIn(ctx, txIns...)
+}
@@ -0,0 +1,105 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/savaki/ogmigo/ouroboros/chainsync"
+)
+
+func TestClient_Acquire(t *testing.T) {
+	var (
+		mutex    sync.Mutex
+		released int
+		acquired = chainsync.PointStruct{Slot: 123, Hash: "hash"}.Point()
+	)
+
+	endpoint, closer := fakeOgmios(t, func(methodName string, args json.RawMessage) (interface{}, error) {
+		switch methodName {
+		case "Acquire":
+			var content struct{ Point chainsync.Point }
+			if err := json.Unmarshal(args, &content); err != nil {
+				return nil, err
+			}
+			if content.Point.String() != acquired.String() {
+				return Map{"AcquireFailure": Map{"failure": AcquireFailurePointNotOnChain}}, nil
+			}
+			return Map{"AcquireSuccess": Map{"point": content.Point}}, nil
+
+		case "Query":
+			return acquired, nil
+
+		case "Release":
+			mutex.Lock()
+			released++
+			mutex.Unlock()
+			return "Released", nil
+
+		default:
+			return nil, fmt.Errorf("unexpected method, %v", methodName)
+		}
+	})
+	defer closer()
+
+	ctx := context.Background()
+	client := New(WithEndpoint(endpoint), WithLogger(NopLogger))
+	defer client.Close()
+
+	t.Run("ok", func(t *testing.T) {
+		session, err := client.Acquire(ctx, acquired)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+
+		point, err := session.ChainTip(ctx)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := point.String(), session.Point().String(); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+
+		if err := session.Release(ctx); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		mutex.Lock()
+		if got, want := released, 1; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		mutex.Unlock()
+
+		// released sessions must not silently redial and query the tip
+		if _, err := session.ChainTip(ctx); err == nil {
+			t.Fatalf("got nil; want not nil")
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		_, err := client.Acquire(ctx, chainsync.PointStruct{Slot: 456, Hash: "other"}.Point())
+		var acquireError AcquireError
+		if !errors.As(err, &acquireError) {
+			t.Fatalf("got %v; want AcquireError", err)
+		}
+		if got, want := acquireError.Failure, AcquireFailurePointNotOnChain; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+}
@@ -18,7 +18,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -121,3 +123,192 @@ func TestClient_UtxosByAddress(t *testing.T) {
 	encoder.SetIndent("", "  ")
 	encoder.Encode(utxos)
 }
+
+// fixtureOgmios responds to each Query with the recorded fixture of the same name
+func fixtureOgmios(t *testing.T) (endpoint string, closer func()) {
+	return fakeOgmios(t, func(methodName string, args json.RawMessage) (interface{}, error) {
+		var content struct {
+			Query json.RawMessage `json:"query"`
+		}
+		if err := json.Unmarshal(args, &content); err != nil {
+			return nil, err
+		}
+
+		query := string(content.Query)
+		if content.Query[0] == '{' {
+			var m map[string]json.RawMessage
+			if err := json.Unmarshal(content.Query, &m); err != nil {
+				return nil, err
+			}
+			for k := range m {
+				query = k
+			}
+		} else {
+			query = query[1 : len(query)-1]
+		}
+
+		data, err := ioutil.ReadFile(filepath.Join("ouroboros/statequery/testdata", query+".json"))
+		if err != nil {
+			return nil, fmt.Errorf("unknown query, %v", query)
+		}
+		return json.RawMessage(data), nil
+	})
+}
+
+func TestClient_Queries(t *testing.T) {
+	endpoint, closer := fixtureOgmios(t)
+	defer closer()
+
+	ctx := context.Background()
+	client := New(WithEndpoint(endpoint), WithLogger(NopLogger))
+	defer client.Close()
+
+	t.Run("blockHeight", func(t *testing.T) {
+		height, err := client.BlockHeight(ctx)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := height, uint64(6916152); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("chainTip", func(t *testing.T) {
+		point, err := client.NetworkTip(ctx)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		ps, ok := point.PointStruct()
+		if !ok {
+			t.Fatalf("got false; want true")
+		}
+		if got, want := ps.BlockNo, uint64(6916152); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("delegationsAndRewards", func(t *testing.T) {
+		got, err := client.DelegationsAndRewards(ctx, "7c16240714ea0e12b41a914f2945784ac494bb19573f0ca61a08afa8")
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := len(got), 2; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("eraSummaries", func(t *testing.T) {
+		got, err := client.EraSummaries(ctx)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := len(got), 3; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("genesisConfig", func(t *testing.T) {
+		got, err := client.GenesisConfig(ctx)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := got.Network, "mainnet"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("nonMyopicMemberRewards", func(t *testing.T) {
+		got, err := client.NonMyopicMemberRewards(ctx, 1e9)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := len(got["1000000000"]), 2; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("poolIds", func(t *testing.T) {
+		got, err := client.PoolIDs(ctx)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := len(got), 2; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("poolParameters", func(t *testing.T) {
+		got, err := client.PoolParameters(ctx, "pool1m5947rydk4n0ywe6ctlav0ztt632lcwjef7fsy93sflz7ctcx6z")
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := len(got), 2; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("poolsRanking", func(t *testing.T) {
+		got, err := client.PoolsRanking(ctx)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := len(got), 2; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("proposedProtocolParameters", func(t *testing.T) {
+		got, err := client.ProposedProtocolParameters(ctx)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := len(got), 1; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		for _, params := range got {
+			if got, want := params.MaxTxSize, uint64(16384); got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+		}
+	})
+
+	t.Run("currentProtocolParameters", func(t *testing.T) {
+		got, err := client.ProtocolParameters(ctx)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := got.MaxCollateralInputs, uint64(3); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("rewardsProvenance", func(t *testing.T) {
+		got, err := client.RewardsProvenance(ctx)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := got.EpochLength, uint64(432000); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("stakeDistribution", func(t *testing.T) {
+		got, err := client.StakeDistribution(ctx)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := len(got), 1; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("systemStart", func(t *testing.T) {
+		got, err := client.SystemStart(ctx)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := got.Year(), 2017; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+}
@@ -0,0 +1,113 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/savaki/ogmigo/ouroboros/chainsync"
+	"github.com/savaki/ogmigo/ouroboros/statequery"
+)
+
+// queryV6 submits the ogmios v6 query and decodes the result into v
+func (c *Client) queryV6(ctx context.Context, method string, params Map, v interface{}) error {
+	var content struct{ Result json.RawMessage }
+	if err := c.query(ctx, makeRPC(method, params), &content); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(content.Result, v); err != nil {
+		return fmt.Errorf("failed to decode %v: %w", method, err)
+	}
+	return nil
+}
+
+func (c *Client) chainTipV6(ctx context.Context) (chainsync.Point, error) {
+	var point chainsync.PointV6
+	if err := c.queryV6(ctx, "queryLedgerState/tip", nil, &point); err != nil {
+		return chainsync.Point{}, err
+	}
+	return chainsync.Point(point), nil
+}
+
+func (c *Client) protocolParametersV6(ctx context.Context) (statequery.ProtocolParameters, error) {
+	var params statequery.ProtocolParametersV6
+	if err := c.queryV6(ctx, "queryLedgerState/protocolParameters", nil, &params); err != nil {
+		return statequery.ProtocolParameters{}, err
+	}
+	return statequery.ProtocolParameters(params), nil
+}
+
+func (c *Client) blockHeightV6(ctx context.Context) (uint64, error) {
+	var raw json.RawMessage
+	if err := c.queryV6(ctx, "queryNetwork/blockHeight", nil, &raw); err != nil {
+		return 0, err
+	}
+
+	var height uint64
+	if string(raw) == `"origin"` {
+		return height, nil
+	}
+	if err := json.Unmarshal(raw, &height); err != nil {
+		return 0, fmt.Errorf("failed to decode block height: %w", err)
+	}
+	return height, nil
+}
+
+func (c *Client) networkTipV6(ctx context.Context) (chainsync.Point, error) {
+	var point chainsync.PointV6
+	if err := c.queryV6(ctx, "queryNetwork/tip", nil, &point); err != nil {
+		return chainsync.Point{}, err
+	}
+	return chainsync.Point(point), nil
+}
+
+func (c *Client) poolIDsV6(ctx context.Context) ([]string, error) {
+	var pools map[string]json.RawMessage
+	if err := c.queryV6(ctx, "queryLedgerState/stakePools", nil, &pools); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(pools))
+	for id := range pools {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func (c *Client) systemStartV6(ctx context.Context) (time.Time, error) {
+	var t time.Time
+	if err := c.queryV6(ctx, "queryNetwork/startTime", nil, &t); err != nil {
+		return time.Time{}, err
+	}
+	return t, nil
+}
+
+func (c *Client) utxosV6(ctx context.Context, params Map) ([]statequery.Utxo, error) {
+	var items []statequery.UtxoV6
+	if err := c.queryV6(ctx, "queryLedgerState/utxo", params, &items); err != nil {
+		return nil, err
+	}
+
+	utxos := make([]statequery.Utxo, 0, len(items))
+	for _, item := range items {
+		utxos = append(utxos, statequery.Utxo(item))
+	}
+	return utxos, nil
+}
@@ -16,11 +16,14 @@ package ogmigo
 
 import (
 	"context"
+	"errors"
 	"strconv"
 
 	"github.com/savaki/ogmigo/ouroboros/chainsync"
 )
 
+var errNotRollbackStore = errors.New("ogmigo store does not implement RollbackStore")
+
 // Store allows points to be saved and retrieved to allow graceful recovery
 // after shutdown
 type Store interface {
@@ -31,6 +34,17 @@ type Store interface {
 	Load(ctx context.Context) (chainsync.Points, error)
 }
 
+// RollbackStore is a Store that can discard saved points.  ChainSync requires
+// the store to implement RollbackStore and invokes Rollback whenever ogmios
+// rolls back the chain so that Load never returns points beyond the rollback
+// point.
+type RollbackStore interface {
+	Store
+	// Rollback discards all saved points after the provided point and saves
+	// the point itself
+	Rollback(ctx context.Context, point chainsync.Point) error
+}
+
 type loggingStore struct {
 	logger Logger
 }
@@ -53,6 +67,11 @@ func (l *loggingStore) Save(_ context.Context, point chainsync.Point) error {
 	return nil
 }
 
+func (l *loggingStore) Rollback(_ context.Context, point chainsync.Point) error {
+	l.logger.Info("rollback point", KV("point", point.String()))
+	return nil
+}
+
 func (l *loggingStore) Load(_ context.Context) (chainsync.Points, error) {
 	return nil, nil
 }
@@ -60,5 +79,6 @@ func (l *loggingStore) Load(_ context.Context) (chainsync.Points, error) {
 type nopStore struct {
 }
 
-func (n nopStore) Save(context.Context, chainsync.Point) error    { return nil }
-func (n nopStore) Load(context.Context) (chainsync.Points, error) { return nil, nil }
+func (n nopStore) Save(context.Context, chainsync.Point) error     { return nil }
+func (n nopStore) Rollback(context.Context, chainsync.Point) error { return nil }
+func (n nopStore) Load(context.Context) (chainsync.Points, error)  { return nil, nil }
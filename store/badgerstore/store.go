@@ -62,6 +62,46 @@ func (s *Store) Save(_ context.Context, point chainsync.Point) error {
 	return s.db.Sync()
 }
 
+// Rollback removes all saved points after the provided point and saves the
+// point itself
+func (s *Store) Rollback(ctx context.Context, point chainsync.Point) error {
+	var slot uint64
+	if ps, ok := point.PointStruct(); ok {
+		slot = ps.Slot
+	}
+
+	tx := s.db.NewTransaction(true)
+	defer tx.Discard()
+
+	iter := tx.NewIterator(badger.DefaultIteratorOptions)
+	var keys [][]byte
+	for iter.Seek(s.prefix); iter.ValidForPrefix(s.prefix); iter.Next() {
+		var p chainsync.Point
+		unmarshal := func(val []byte) error { return json.Unmarshal(val, &p) }
+
+		if err := iter.Item().Value(unmarshal); err != nil {
+			iter.Close()
+			return fmt.Errorf("failed to rollback points: %w", err)
+		}
+
+		if ps, ok := p.PointStruct(); ok && ps.Slot > slot {
+			keys = append(keys, iter.Item().KeyCopy(nil))
+		}
+	}
+	iter.Close()
+
+	for _, key := range keys {
+		if err := tx.Delete(key); err != nil {
+			return fmt.Errorf("failed to rollback points: delete failed: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to rollback points: commit failed: %w", err)
+	}
+
+	return s.Save(ctx, point)
+}
+
 // Load saved points
 func (s *Store) Load(context.Context) (chainsync.Points, error) {
 	tx := s.db.NewTransaction(false)
@@ -65,3 +65,39 @@ func TestStore_Load(t *testing.T) {
 		t.Fatalf("got %#v; want %#v", got, want)
 	}
 }
+
+func TestStore_Rollback(t *testing.T) {
+	db, err := badger.Open(badger.DefaultOptions("").WithInMemory(true))
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	defer db.Close()
+
+	var (
+		ctx   = context.Background()
+		a     = chainsync.PointStruct{Slot: 10}
+		b     = chainsync.PointStruct{Slot: 20}
+		c     = chainsync.PointStruct{Slot: 30}
+		d     = chainsync.PointStruct{Slot: 25}
+		store = New(db, "points")
+	)
+
+	for _, p := range []chainsync.PointStruct{a, b, c} {
+		if err := store.Save(ctx, p.Point()); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+	}
+
+	if err := store.Rollback(ctx, d.Point()); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	points, err := store.Load(ctx)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	want := chainsync.Points{d.Point(), b.Point(), a.Point()}
+	if got := points; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v; want %#v", got, want)
+	}
+}
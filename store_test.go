@@ -26,3 +26,20 @@ func TestNewLoggingStore(t *testing.T) {
 		t.Fatalf("got %v; want %v", got, want)
 	}
 }
+
+func TestLoggingStore_Rollback(t *testing.T) {
+	p := chainsync.PointStruct{
+		BlockNo: 123,
+		Hash:    "hash",
+		Slot:    456,
+	}
+
+	store := NewLoggingStore(NopLogger)
+	rs, ok := store.(RollbackStore)
+	if !ok {
+		t.Fatalf("got false; want true")
+	}
+	if err := rs.Rollback(context.Background(), p.Point()); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+}
@@ -0,0 +1,257 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/savaki/ogmigo/ouroboros/chainsync"
+)
+
+// ErrTxExpired indicates the chain passed the ttl of the tx before the tx was
+// included in a block
+var ErrTxExpired = errors.New("tx expired before confirmation")
+
+// TxConfirmation describes the block that confirmed a tx
+type TxConfirmation struct {
+	TxID  string
+	Block chainsync.PointStruct // block that includes the tx
+	Depth uint64                // number of blocks built on top of Block
+}
+
+// TxRollback describes a rollback that removed the block including the tx
+type TxRollback struct {
+	TxID  string
+	Block chainsync.PointStruct // block that had included the tx
+	Point chainsync.Point       // point the chain was rolled back to
+}
+
+// AwaitTxOptions configures AwaitTx and SubmitTxAndAwait
+type AwaitTxOptions struct {
+	depth        uint64            // blocks required on top of the including block
+	points       []chainsync.Point // points to begin following the chain from
+	rollbackFunc func(TxRollback)  // invoked when the including block is rolled back
+	ttl          uint64            // slot at which the tx expires; 0 for none
+}
+
+// AwaitTxOption provides functional options for AwaitTx
+type AwaitTxOption func(opts *AwaitTxOptions)
+
+func buildAwaitTxOptions(opts ...AwaitTxOption) AwaitTxOptions {
+	var options AwaitTxOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// WithAwaitDepth waits until n blocks have been built on top of the block that
+// includes the tx; defaults to 0, returning as soon as the tx is included
+func WithAwaitDepth(n uint64) AwaitTxOption {
+	return func(opts *AwaitTxOptions) {
+		opts.depth = n
+	}
+}
+
+// WithAwaitPoints sets the points to begin following the chain from; defaults
+// to the current tip
+func WithAwaitPoints(points ...chainsync.Point) AwaitTxOption {
+	return func(opts *AwaitTxOptions) {
+		opts.points = points
+	}
+}
+
+// WithAwaitRollbackFunc is invoked whenever a rollback removes the block that
+// included the tx.  AwaitTx continues to wait for the tx to be included again
+func WithAwaitRollbackFunc(fn func(TxRollback)) AwaitTxOption {
+	return func(opts *AwaitTxOptions) {
+		opts.rollbackFunc = fn
+	}
+}
+
+// WithAwaitTTL fails with ErrTxExpired once the chain reaches slot without the
+// tx having been included; typically TxBody.TimeToLive or
+// TxBody.ValidityInterval.InvalidHereafter
+func WithAwaitTTL(slot uint64) AwaitTxOption {
+	return func(opts *AwaitTxOptions) {
+		opts.ttl = slot
+	}
+}
+
+// SubmitTxAndAwait submits the tx and then follows the chain until the tx with
+// the given id is confirmed.  If txID is blank, the id is computed from the tx.
+// Unless WithAwaitTTL is provided, the ttl is read from the tx.  See AwaitTx
+func (c *Client) SubmitTxAndAwait(ctx context.Context, data []byte, txID string, opts ...AwaitTxOption) (TxConfirmation, error) {
+	signedTx, err := readCborHex(data)
+	if err != nil {
+		return TxConfirmation{}, fmt.Errorf("failed to decode signed tx: %w", err)
+	}
+	tx, err := chainsync.DecodeTxHex(signedTx)
+	if err != nil {
+		return TxConfirmation{}, fmt.Errorf("failed to submit tx: %w", err)
+	}
+	if txID == "" {
+		txID = tx.ID
+	}
+
+	options := buildAwaitTxOptions(opts...)
+	if ttl := tx.Body.ValidityInterval.InvalidHereafter; options.ttl == 0 && ttl > 0 {
+		opts = append(opts, WithAwaitTTL(ttl))
+	}
+
+	// capture the tip prior to submitting so the including block cannot be missed
+	if len(options.points) == 0 {
+		tip, err := c.ChainTip(ctx)
+		if err != nil {
+			return TxConfirmation{}, fmt.Errorf("failed to await tx, %v: %w", txID, err)
+		}
+		opts = append(opts, WithAwaitPoints(tip))
+	}
+
+	if err := c.SubmitTx(ctx, data); err != nil {
+		return TxConfirmation{}, err
+	}
+
+	return c.AwaitTx(ctx, txID, opts...)
+}
+
+// AwaitTx follows the chain until the tx with the given id is included in a
+// block at the requested depth, the ttl passes, or the context is canceled.
+// Rollbacks that remove the including block are reported via
+// WithAwaitRollbackFunc and the wait continues
+func (c *Client) AwaitTx(ctx context.Context, txID string, opts ...AwaitTxOption) (TxConfirmation, error) {
+	options := buildAwaitTxOptions(opts...)
+	if len(options.points) == 0 {
+		tip, err := c.ChainTip(ctx)
+		if err != nil {
+			return TxConfirmation{}, fmt.Errorf("failed to await tx, %v: %w", txID, err)
+		}
+		options.points = []chainsync.Point{tip}
+	}
+
+	handler := &awaitTxHandler{
+		txID:    txID,
+		options: options,
+		done:    make(chan awaitTxResult, 1),
+	}
+	cs, err := c.ChainSyncWithHandler(ctx, handler,
+		WithPoints(options.points...),
+		WithReconnect(true),
+	)
+	if err != nil {
+		return TxConfirmation{}, fmt.Errorf("failed to await tx, %v: %w", txID, err)
+	}
+	defer cs.Close()
+
+	select {
+	case <-ctx.Done():
+		return TxConfirmation{}, ctx.Err()
+	case result := <-handler.done:
+		return result.confirmation, result.err
+	case <-cs.Done():
+		return TxConfirmation{}, fmt.Errorf("failed to await tx, %v: chain sync terminated: %v", txID, cs.Close())
+	}
+}
+
+type awaitTxResult struct {
+	confirmation TxConfirmation
+	err          error
+}
+
+// awaitTxHandler tracks the block including the tx; chain sync invokes the
+// handler from a single goroutine
+type awaitTxHandler struct {
+	txID     string
+	options  AwaitTxOptions
+	included *chainsync.PointStruct
+	finished bool
+	done     chan awaitTxResult
+}
+
+func (h *awaitTxHandler) finish(result awaitTxResult) {
+	if h.finished {
+		return
+	}
+	h.finished = true
+	h.done <- result
+}
+
+func (h *awaitTxHandler) IntersectionFound(context.Context, chainsync.Point, chainsync.Point) error {
+	return nil
+}
+
+func (h *awaitTxHandler) IntersectionNotFound(_ context.Context, tip chainsync.Point) error {
+	h.finish(awaitTxResult{err: fmt.Errorf("failed to await tx, %v: intersection not found, tip %v", h.txID, tip)})
+	return nil
+}
+
+func (h *awaitTxHandler) RollForward(_ context.Context, block chainsync.RollForwardBlock, _ chainsync.Point) error {
+	if h.finished {
+		return nil
+	}
+
+	ps := block.PointStruct()
+	if h.included == nil && containsTx(block, h.txID) {
+		h.included = &ps
+	}
+
+	switch {
+	case h.included != nil && h.included.BlockNo+h.options.depth <= ps.BlockNo:
+		h.finish(awaitTxResult{
+			confirmation: TxConfirmation{
+				TxID:  h.txID,
+				Block: *h.included,
+				Depth: ps.BlockNo - h.included.BlockNo,
+			},
+		})
+	case h.included == nil && h.options.ttl > 0 && ps.Slot >= h.options.ttl:
+		h.finish(awaitTxResult{err: fmt.Errorf("failed to await tx, %v: ttl %v, slot %v: %w", h.txID, h.options.ttl, ps.Slot, ErrTxExpired)})
+	}
+
+	return nil
+}
+
+func (h *awaitTxHandler) RollBackward(_ context.Context, point, _ chainsync.Point) error {
+	if h.finished || h.included == nil {
+		return nil
+	}
+
+	if ps, ok := point.PointStruct(); ok && ps.Slot >= h.included.Slot {
+		return nil // including block survives
+	}
+
+	rollback := TxRollback{
+		TxID:  h.txID,
+		Block: *h.included,
+		Point: point,
+	}
+	h.included = nil
+	if fn := h.options.rollbackFunc; fn != nil {
+		fn(rollback)
+	}
+	return nil
+}
+
+// containsTx returns true if the block includes the tx with the given id
+func containsTx(block chainsync.RollForwardBlock, txID string) bool {
+	for _, tx := range block.Txs() {
+		if tx.ID == txID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/savaki/ogmigo/ouroboros/chainsync"
+)
+
+// rollForwardTx returns a RollForward of a block that includes the tx
+func rollForwardTx(slot, blockNo, tip uint64, txID string) string {
+	return fmt.Sprintf(`{"type":"jsonwsp/response","version":"1.0","servicename":"ogmios","methodname":"RequestNext","result":{"RollForward":{"block":{"alonzo":{"body":[{"id":"%v"}],"header":{"slot":%v,"blockHeight":%v},"headerHash":"%v"}},"tip":{"slot":%v,"hash":"%v","blockNo":%v}}}}`,
+		txID, slot, blockNo, slot, tip*10, tip*10, tip)
+}
+
+func TestClient_AwaitTx(t *testing.T) {
+	const txID = "abc"
+
+	await := func(t *testing.T, messages []string, opts ...AwaitTxOption) (TxConfirmation, error) {
+		endpoint, closer := chainSyncServer(t, append([]string{intersectionFound}, messages...)...)
+		defer closer()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		client := New(WithEndpoint(endpoint), WithLogger(NopLogger))
+		defer client.Close()
+
+		return client.AwaitTx(ctx, txID, append(opts, WithAwaitPoints(chainsync.Origin))...)
+	}
+
+	t.Run("depth", func(t *testing.T) {
+		got, err := await(t,
+			[]string{
+				rollForward(10, 1, 4),
+				rollForwardTx(20, 2, 4, txID),
+				rollForward(30, 3, 4),
+				rollForward(40, 4, 4),
+			},
+			WithAwaitDepth(2),
+		)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := got.Block.BlockNo, uint64(2); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := got.Depth, uint64(2); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		var rollbacks []TxRollback
+		got, err := await(t,
+			[]string{
+				rollForward(10, 1, 3),
+				rollForwardTx(20, 2, 3, txID),
+				rollBackward(10, 3),
+				rollForward(21, 2, 3),
+				rollForwardTx(30, 3, 3, txID),
+				rollForward(40, 4, 4),
+			},
+			WithAwaitDepth(1),
+			WithAwaitRollbackFunc(func(rollback TxRollback) {
+				rollbacks = append(rollbacks, rollback)
+			}),
+		)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := got.Block.Slot, uint64(30); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := len(rollbacks), 1; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := rollbacks[0].Block.Slot, uint64(20); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		_, err := await(t,
+			[]string{
+				rollForward(10, 1, 3),
+				rollForward(20, 2, 3),
+				rollForward(30, 3, 3),
+			},
+			WithAwaitTTL(25),
+		)
+		if !errors.Is(err, ErrTxExpired) {
+			t.Fatalf("got %v; want ErrTxExpired", err)
+		}
+	})
+}
@@ -0,0 +1,260 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/savaki/ogmigo/ouroboros/chainsync"
+	"github.com/savaki/ogmigo/ouroboros/statequery"
+)
+
+// EvaluateTx evaluates the scripts of the transaction and returns the execution
+// units required by each redeemer, keyed by redeemer pointer e.g. spend:0.
+// additionalUtxos supplies inputs not yet known to the ledger such as the
+// outputs of transactions still in flight.  Failures are returned as an
+// EvaluateTxError
+// https://ogmios.dev/mini-protocols/local-tx-submission/#evaluating-transactions
+func (c *Client) EvaluateTx(ctx context.Context, data []byte, additionalUtxos ...statequery.Utxo) (map[string]statequery.ExecutionUnits, error) {
+	tx, err := readCborHex(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode tx: %w", err)
+	}
+
+	v6, err := c.v6(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if v6 {
+		return c.evaluateTxV6(ctx, tx, additionalUtxos)
+	}
+
+	args := Map{"evaluate": tx}
+	if len(additionalUtxos) > 0 {
+		args["additionalUtxoSet"] = additionalUtxos
+	}
+
+	var (
+		payload = makePayload("EvaluateTx", args)
+		content struct {
+			Result struct {
+				EvaluationResult  map[string]statequery.ExecutionUnits
+				EvaluationFailure json.RawMessage
+			}
+		}
+	)
+	if err := c.query(ctx, payload, &content); err != nil {
+		return nil, fmt.Errorf("failed to evaluate tx: %w", err)
+	}
+
+	if failure := content.Result.EvaluationFailure; len(failure) > 0 {
+		return nil, readEvaluateTx(failure)
+	}
+
+	return content.Result.EvaluationResult, nil
+}
+
+// evaluateTxV6 evaluates the tx via ogmios v6; failures are translated into
+// an EvaluateTxError
+func (c *Client) evaluateTxV6(ctx context.Context, tx string, additionalUtxos []statequery.Utxo) (map[string]statequery.ExecutionUnits, error) {
+	params := Map{"transaction": Map{"cbor": tx}}
+	if len(additionalUtxos) > 0 {
+		utxos := make([]statequery.UtxoV6, 0, len(additionalUtxos))
+		for _, utxo := range additionalUtxos {
+			utxos = append(utxos, statequery.UtxoV6(utxo))
+		}
+		params["additionalUtxo"] = utxos
+	}
+
+	var content struct {
+		Result []struct {
+			Validator struct {
+				Purpose string
+				Index   int
+			}
+			Budget struct {
+				Memory uint64
+				CPU    uint64
+			}
+		}
+	}
+	if err := c.query(ctx, makeRPC("evaluateTransaction", params), &content); err != nil {
+		if e, code, ok := rpcFailure(err); ok {
+			return nil, readEvaluateTxV6(e, code)
+		}
+		return nil, fmt.Errorf("failed to evaluate tx: %w", err)
+	}
+
+	units := map[string]statequery.ExecutionUnits{}
+	for _, item := range content.Result {
+		key := item.Validator.Purpose + ":" + strconv.Itoa(item.Validator.Index)
+		units[key] = statequery.ExecutionUnits{Memory: item.Budget.Memory, Steps: item.Budget.CPU}
+	}
+	return units, nil
+}
+
+// ScriptFailure describes why a single redeemer failed to evaluate.  Code
+// holds the ogmios failure name e.g. validatorFailed, extraRedeemers,
+// missingRequiredDatums, missingRequiredScripts,
+// unknownInputReferencedByRedeemer, nonScriptInputReferencedByRedeemer,
+// noCostModelForLanguage, illFormedExecutionBudget
+type ScriptFailure struct {
+	Code string
+	Data json.RawMessage
+}
+
+// ValidatorFailed returns the error and traces reported by the script when
+// Code is validatorFailed
+func (s ScriptFailure) ValidatorFailed() (msg string, traces []string, ok bool) {
+	if s.Code != "validatorFailed" {
+		return "", nil, false
+	}
+
+	var content struct {
+		Error  string
+		Traces []string
+	}
+	if err := json.Unmarshal(s.Data, &content); err != nil {
+		return "", nil, false
+	}
+	return content.Error, content.Traces, true
+}
+
+// EvaluateTxError encapsulates the reasons EvaluateTx failed
+type EvaluateTxError struct {
+	// ScriptFailures holds the failures of each redeemer keyed by redeemer
+	// pointer e.g. spend:0
+	ScriptFailures map[string][]ScriptFailure
+	// UnknownInputs lists inputs that are neither in the ledger nor in the
+	// additional utxo set
+	UnknownInputs []chainsync.TxIn
+	// AdditionalUtxoOverlap lists additional utxos already present in the ledger
+	AdditionalUtxoOverlap []chainsync.TxIn
+	// IncompatibleEra holds the era of the node when it does not support evaluation
+	IncompatibleEra string
+
+	raw map[string]json.RawMessage
+}
+
+// ErrorCodes returns the sorted list of failure codes including the codes of
+// any script failures
+func (e EvaluateTxError) ErrorCodes() []string {
+	seen := map[string]struct{}{}
+	for key := range e.raw {
+		seen[key] = struct{}{}
+	}
+	for _, failures := range e.ScriptFailures {
+		for _, failure := range failures {
+			seen[failure.Code] = struct{}{}
+		}
+	}
+
+	var keys []string
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// HasErrorCode returns true if the error contains the provided code
+func (e EvaluateTxError) HasErrorCode(errorCode string) bool {
+	for _, ec := range e.ErrorCodes() {
+		if ec == errorCode {
+			return true
+		}
+	}
+	return false
+}
+
+// Messages returns the raw failures keyed by failure type
+func (e EvaluateTxError) Messages() map[string]json.RawMessage {
+	return e.raw
+}
+
+// Error implements the error interface
+func (e EvaluateTxError) Error() string {
+	return fmt.Sprintf("EvaluateTx failed: %v", strings.Join(e.ErrorCodes(), ", "))
+}
+
+func readEvaluateTx(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var reason string
+		if err := json.Unmarshal(data, &reason); err != nil {
+			return fmt.Errorf("failed to parse EvaluateTx response: %w", err)
+		}
+		return EvaluateTxError{raw: map[string]json.RawMessage{reason: data}}
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("failed to parse EvaluateTx response: %w", err)
+	}
+
+	e := EvaluateTxError{raw: raw}
+	for key, value := range raw {
+		var err error
+		switch key {
+		case "ScriptFailures":
+			e.ScriptFailures, err = readScriptFailures(value)
+		case "UnknownInputs":
+			err = json.Unmarshal(value, &e.UnknownInputs)
+		case "AdditionalUtxoOverlap":
+			err = json.Unmarshal(value, &e.AdditionalUtxoOverlap)
+		case "IncompatibleEra":
+			err = json.Unmarshal(value, &e.IncompatibleEra)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to parse EvaluateTx response: %v: %w", key, err)
+		}
+	}
+
+	return e
+}
+
+func readScriptFailures(data []byte) (map[string][]ScriptFailure, error) {
+	var raw map[string][]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	failures := map[string][]ScriptFailure{}
+	for redeemer, items := range raw {
+		for _, item := range items {
+			if len(item) > 0 && item[0] == '"' {
+				var code string
+				if err := json.Unmarshal(item, &code); err != nil {
+					return nil, err
+				}
+				failures[redeemer] = append(failures[redeemer], ScriptFailure{Code: code})
+				continue
+			}
+
+			var m map[string]json.RawMessage
+			if err := json.Unmarshal(item, &m); err != nil {
+				return nil, err
+			}
+			for code, value := range m {
+				failures[redeemer] = append(failures[redeemer], ScriptFailure{Code: code, Data: value})
+			}
+		}
+	}
+	return failures, nil
+}
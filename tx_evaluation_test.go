@@ -0,0 +1,124 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/savaki/ogmigo/ouroboros/chainsync"
+	"github.com/savaki/ogmigo/ouroboros/chainsync/num"
+	"github.com/savaki/ogmigo/ouroboros/statequery"
+)
+
+func TestClient_EvaluateTx(t *testing.T) {
+	responses := map[string]string{
+		"ok":             `{"EvaluationResult":{"spend:0":{"memory":1700,"steps":476468}}}`,
+		"script":         `{"EvaluationFailure":{"ScriptFailures":{"spend:0":[{"validatorFailed":{"error":"An error has occurred","traces":["PT5"]}}],"mint:1":[{"extraRedeemers":["mint:1"]}]}}}`,
+		"unknown-inputs": `{"EvaluationFailure":{"UnknownInputs":[{"txId":"abc","index":1}]}}`,
+	}
+
+	endpoint, closer := fakeOgmios(t, func(methodName string, args json.RawMessage) (interface{}, error) {
+		if methodName != "EvaluateTx" {
+			return nil, fmt.Errorf("unexpected method, %v", methodName)
+		}
+
+		var content struct {
+			Evaluate          string
+			AdditionalUtxoSet []statequery.Utxo
+		}
+		if err := json.Unmarshal(args, &content); err != nil {
+			return nil, err
+		}
+		if content.Evaluate == "utxos" && len(content.AdditionalUtxoSet) == 1 {
+			return json.RawMessage(responses["ok"]), nil
+		}
+		response, ok := responses[content.Evaluate]
+		if !ok {
+			return nil, fmt.Errorf("unexpected tx, %v", content.Evaluate)
+		}
+		return json.RawMessage(response), nil
+	})
+	defer closer()
+
+	ctx := context.Background()
+	client := New(WithEndpoint(endpoint), WithLogger(NopLogger))
+	defer client.Close()
+
+	t.Run("ok", func(t *testing.T) {
+		units, err := client.EvaluateTx(ctx, []byte(`{"cborHex":"ok"}`))
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := units["spend:0"], (statequery.ExecutionUnits{Memory: 1700, Steps: 476468}); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("additional utxos", func(t *testing.T) {
+		utxo := statequery.Utxo{
+			TxIn:  chainsync.TxIn{TxHash: "abc", Index: 1},
+			TxOut: chainsync.TxOut{Address: "addr", Value: chainsync.Value{Coins: num.Int64(2000000)}},
+		}
+		if _, err := client.EvaluateTx(ctx, []byte(`{"cborHex":"utxos"}`), utxo); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+	})
+
+	t.Run("script failures", func(t *testing.T) {
+		_, err := client.EvaluateTx(ctx, []byte(`{"cborHex":"script"}`))
+
+		var e EvaluateTxError
+		if !errors.As(err, &e) {
+			t.Fatalf("got %v; want EvaluateTxError", err)
+		}
+		if !e.HasErrorCode("extraRedeemers") {
+			t.Fatalf("got %v; want extraRedeemers", e.ErrorCodes())
+		}
+
+		failures := e.ScriptFailures["spend:0"]
+		if got, want := len(failures), 1; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		msg, traces, ok := failures[0].ValidatorFailed()
+		if !ok {
+			t.Fatalf("got false; want true")
+		}
+		if got, want := msg, "An error has occurred"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := len(traces), 1; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("unknown inputs", func(t *testing.T) {
+		_, err := client.EvaluateTx(ctx, []byte(`{"cborHex":"unknown-inputs"}`))
+
+		var e EvaluateTxError
+		if !errors.As(err, &e) {
+			t.Fatalf("got %v; want EvaluateTxError", err)
+		}
+		if got, want := e.UnknownInputs, []chainsync.TxIn{{TxHash: "abc", Index: 1}}; len(got) != 1 || got[0] != want[0] {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := e.Error(), "EvaluateTx failed: UnknownInputs"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+}
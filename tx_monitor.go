@@ -0,0 +1,209 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/savaki/ogmigo/ouroboros/chainsync"
+	"github.com/savaki/ogmigo/ouroboros/txmonitor"
+)
+
+// MempoolMonitor provides access to the node's mempool via the local tx monitor
+// protocol.  Each MempoolMonitor holds its own connection to ogmios as acquired
+// snapshots are specific to a connection.
+// https://ogmios.dev/mini-protocols/local-tx-monitor/
+type MempoolMonitor struct {
+	conn *conn
+	v6   bool
+}
+
+// MempoolMonitor opens a new connection for monitoring the mempool.  Callers
+// must Close the MempoolMonitor when done
+func (c *Client) MempoolMonitor(ctx context.Context) (*MempoolMonitor, error) {
+	v6, err := c.v6(ctx)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := c.pool.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &MempoolMonitor{conn: conn, v6: v6}, nil
+}
+
+// AwaitAcquire acquires a snapshot of the mempool, blocking until a snapshot
+// different from the one currently held is available.  Returns the slot of the
+// snapshot
+func (m *MempoolMonitor) AwaitAcquire(ctx context.Context) (uint64, error) {
+	if m.v6 {
+		var content struct {
+			Result struct{ Slot uint64 }
+		}
+		if err := m.conn.query(ctx, makeRPC("acquireMempool", nil), &content); err != nil {
+			return 0, fmt.Errorf("failed to acquire mempool: %w", err)
+		}
+		return content.Result.Slot, nil
+	}
+
+	var (
+		payload = makePayload("AwaitAcquire", Map{})
+		content struct {
+			Result struct{ AwaitAcquired txmonitor.AwaitAcquired }
+		}
+	)
+
+	if err := m.conn.query(ctx, payload, &content); err != nil {
+		return 0, fmt.Errorf("failed to acquire mempool: %w", err)
+	}
+
+	return content.Result.AwaitAcquired.Slot, nil
+}
+
+// NextTxID returns the id of the next transaction in the acquired snapshot and
+// false once the snapshot has been exhausted
+func (m *MempoolMonitor) NextTxID(ctx context.Context) (string, bool, error) {
+	next, err := m.nextTx(ctx, Map{})
+	if err != nil {
+		return "", false, err
+	}
+	return next.ID, !next.Done(), nil
+}
+
+// NextTx returns the next transaction in the acquired snapshot and false once
+// the snapshot has been exhausted
+func (m *MempoolMonitor) NextTx(ctx context.Context) (chainsync.Tx, bool, error) {
+	next, err := m.nextTx(ctx, Map{"fields": "all"})
+	if err != nil {
+		return chainsync.Tx{}, false, err
+	}
+	if next.Tx == nil {
+		return chainsync.Tx{}, false, nil
+	}
+	return *next.Tx, true, nil
+}
+
+func (m *MempoolMonitor) nextTx(ctx context.Context, args Map) (txmonitor.NextTx, error) {
+	if m.v6 {
+		var content struct{ Result txmonitor.NextTxV6 }
+		if err := m.conn.query(ctx, makeRPC("nextTransaction", args), &content); err != nil {
+			return txmonitor.NextTx{}, fmt.Errorf("failed to read next mempool tx: %w", err)
+		}
+		return txmonitor.NextTx(content.Result), nil
+	}
+
+	var (
+		payload = makePayload("NextTx", args)
+		content struct{ Result txmonitor.NextTx }
+	)
+
+	if err := m.conn.query(ctx, payload, &content); err != nil {
+		return txmonitor.NextTx{}, fmt.Errorf("failed to read next mempool tx: %w", err)
+	}
+
+	return content.Result, nil
+}
+
+// EachTxID invokes the callback with the id of each remaining transaction in
+// the acquired snapshot
+func (m *MempoolMonitor) EachTxID(ctx context.Context, callback func(id string) error) error {
+	for {
+		id, ok, err := m.NextTxID(ctx)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+		if err := callback(id); err != nil {
+			return err
+		}
+	}
+}
+
+// EachTx invokes the callback with each remaining transaction in the acquired
+// snapshot
+func (m *MempoolMonitor) EachTx(ctx context.Context, callback func(tx chainsync.Tx) error) error {
+	for {
+		tx, ok, err := m.NextTx(ctx)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+		if err := callback(tx); err != nil {
+			return err
+		}
+	}
+}
+
+// HasTx returns true if the acquired snapshot contains the transaction
+func (m *MempoolMonitor) HasTx(ctx context.Context, id string) (bool, error) {
+	var (
+		payload = makePayload("HasTx", Map{"id": id})
+		content struct{ Result bool }
+	)
+	if m.v6 {
+		payload = makeRPC("hasTransaction", Map{"id": id})
+	}
+
+	if err := m.conn.query(ctx, payload, &content); err != nil {
+		return false, fmt.Errorf("failed to query mempool for tx, %v: %w", id, err)
+	}
+
+	return content.Result, nil
+}
+
+// SizeAndCapacity returns the size of the acquired snapshot
+func (m *MempoolMonitor) SizeAndCapacity(ctx context.Context) (txmonitor.SizeAndCapacity, error) {
+	if m.v6 {
+		var content struct{ Result txmonitor.SizeAndCapacityV6 }
+		if err := m.conn.query(ctx, makeRPC("sizeOfMempool", nil), &content); err != nil {
+			return txmonitor.SizeAndCapacity{}, fmt.Errorf("failed to query mempool size: %w", err)
+		}
+		return txmonitor.SizeAndCapacity(content.Result), nil
+	}
+
+	var (
+		payload = makePayload("SizeAndCapacity", Map{})
+		content struct{ Result txmonitor.SizeAndCapacity }
+	)
+
+	if err := m.conn.query(ctx, payload, &content); err != nil {
+		return txmonitor.SizeAndCapacity{}, fmt.Errorf("failed to query mempool size: %w", err)
+	}
+
+	return content.Result, nil
+}
+
+// Release releases the acquired snapshot
+func (m *MempoolMonitor) Release(ctx context.Context) error {
+	payload := makePayload("ReleaseMempool", Map{})
+	if m.v6 {
+		payload = makeRPC("releaseMempool", nil)
+	}
+	if err := m.conn.query(ctx, payload, nil); err != nil {
+		return fmt.Errorf("failed to release mempool: %w", err)
+	}
+	return nil
+}
+
+// Close the underlying connection
+func (m *MempoolMonitor) Close() error {
+	m.conn.close(errClientClosed)
+	return nil
+}
@@ -0,0 +1,127 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/savaki/ogmigo/ouroboros/chainsync"
+)
+
+func TestClient_MempoolMonitor(t *testing.T) {
+	var (
+		mempool = []string{"a", "b"}
+		next    int
+	)
+	endpoint, closer := fakeOgmios(t, func(methodName string, args json.RawMessage) (interface{}, error) {
+		switch methodName {
+		case "AwaitAcquire":
+			next = 0
+			return Map{"AwaitAcquired": Map{"slot": 123}}, nil
+		case "NextTx":
+			if next >= len(mempool) {
+				return nil, nil
+			}
+			id := mempool[next]
+			next++
+			if string(args) == `{"fields":"all"}` {
+				return Map{"id": id, "body": Map{"fee": 1}}, nil
+			}
+			return id, nil
+		case "HasTx":
+			return string(args) == `{"id":"a"}`, nil
+		case "SizeAndCapacity":
+			return Map{"capacity": 100, "currentSize": 10, "numberOfTxs": len(mempool)}, nil
+		case "ReleaseMempool":
+			return "Released", nil
+		default:
+			return nil, fmt.Errorf("unexpected method, %v", methodName)
+		}
+	})
+	defer closer()
+
+	ctx := context.Background()
+	client := New(WithEndpoint(endpoint), WithLogger(NopLogger))
+	defer client.Close()
+
+	monitor, err := client.MempoolMonitor(ctx)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	defer monitor.Close()
+
+	slot, err := monitor.AwaitAcquire(ctx)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := slot, uint64(123); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	var ids []string
+	err = monitor.EachTxID(ctx, func(id string) error {
+		ids = append(ids, id)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if !reflect.DeepEqual(ids, mempool) {
+		t.Fatalf("got %v; want %v", ids, mempool)
+	}
+
+	if _, err := monitor.AwaitAcquire(ctx); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	var txs []chainsync.Tx
+	err = monitor.EachTx(ctx, func(tx chainsync.Tx) error {
+		txs = append(txs, tx)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := len(txs), 2; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := txs[1].ID, "b"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	ok, err := monitor.HasTx(ctx, "a")
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if !ok {
+		t.Fatalf("got false; want true")
+	}
+
+	size, err := monitor.SizeAndCapacity(ctx)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := size.NumberOfTxs, uint64(2); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	if err := monitor.Release(ctx); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"strings"
 
 	"github.com/buger/jsonparser"
+	"github.com/savaki/ogmigo/ouroboros/chainsync"
 )
 
 type Response struct {
@@ -35,17 +36,33 @@ type Response struct {
 	Result      json.RawMessage
 }
 
-// SubmitTx submits the transaction via ogmios
+// SubmitTx submits the transaction via ogmios.  data may be either a
+// cardano-cli text envelope or raw cbor hex.  Malformed transactions are
+// rejected before being sent.  Rejections are returned as a SubmitTxError
+// by both ogmios v5 and v6
 // https://ogmios.dev/mini-protocols/local-tx-submission/
 func (c *Client) SubmitTx(ctx context.Context, data []byte) (err error) {
-	var content struct{ CborHex string }
-	if err := json.Unmarshal(data, &content); err != nil {
+	signedTx, err := readCborHex(data)
+	if err != nil {
 		return fmt.Errorf("failed to decode signed tx: %w", err)
 	}
+	if _, err := chainsync.DecodeTxHex(signedTx); err != nil {
+		return fmt.Errorf("failed to submit tx: %w", err)
+	}
 
-	signedTx := content.CborHex
-	if signedTx == "" {
-		signedTx = string(data)
+	v6, err := c.v6(ctx)
+	if err != nil {
+		return err
+	}
+	if v6 {
+		payload := makeRPC("submitTransaction", Map{"transaction": Map{"cbor": signedTx}})
+		if err := c.query(ctx, payload, nil); err != nil {
+			if e, code, ok := rpcFailure(err); ok {
+				return readSubmitTxV6(e, code)
+			}
+			return fmt.Errorf("failed to submit tx: %w", err)
+		}
+		return nil
 	}
 
 	var (
@@ -59,6 +76,23 @@ func (c *Client) SubmitTx(ctx context.Context, data []byte) (err error) {
 	return readSubmitTx(raw)
 }
 
+// readCborHex returns the cborHex of a cardano-cli text envelope or data as is
+// when data is raw hex
+func readCborHex(data []byte) (string, error) {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] != '{' && trimmed[0] != '"' {
+		return string(trimmed), nil
+	}
+
+	var content struct{ CborHex string }
+	if err := json.Unmarshal(data, &content); err != nil {
+		return "", err
+	}
+	if content.CborHex == "" {
+		return string(data), nil
+	}
+	return content.CborHex, nil
+}
+
 // SubmitTxError encapsulates the SubmitTx errors and allows the results to be parsed
 type SubmitTxError struct {
 	messages []json.RawMessage
@@ -0,0 +1,237 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/savaki/ogmigo/ouroboros/chainsync"
+	"github.com/savaki/ogmigo/ouroboros/chainsync/num"
+)
+
+// SubmitTxFailure holds a SubmitTx failure without a dedicated type
+type SubmitTxFailure struct {
+	Code string
+	Data json.RawMessage
+}
+
+func (f SubmitTxFailure) Error() string { return "SubmitTx failed: " + f.Code }
+
+// BadInputsError indicates inputs that are unknown or already spent
+type BadInputsError struct {
+	Inputs []chainsync.TxIn
+}
+
+func (e BadInputsError) Error() string {
+	return fmt.Sprintf("SubmitTx failed: badInputs: %v", e.Inputs)
+}
+
+// CollateralTooSmallError indicates the collateral is below the required amount
+type CollateralTooSmallError struct {
+	RequiredCollateral num.Int `json:"requiredCollateral"`
+	ActualCollateral   num.Int `json:"actualCollateral"`
+}
+
+func (e CollateralTooSmallError) Error() string {
+	return fmt.Sprintf("SubmitTx failed: collateralTooSmall: required %v, actual %v", e.RequiredCollateral, e.ActualCollateral)
+}
+
+// ExpiredUtxoError indicates the ttl of a pre-alonzo tx has passed
+type ExpiredUtxoError struct {
+	TransactionTimeToLive uint64 `json:"transactionTimeToLive"`
+	CurrentSlot           uint64 `json:"currentSlot"`
+}
+
+func (e ExpiredUtxoError) Error() string {
+	return fmt.Sprintf("SubmitTx failed: expiredUtxo: ttl %v, current slot %v", e.TransactionTimeToLive, e.CurrentSlot)
+}
+
+// FeeTooSmallError indicates the fee is below the minimum fee of the tx
+type FeeTooSmallError struct {
+	RequiredFee num.Int `json:"requiredFee"`
+	ActualFee   num.Int `json:"actualFee"`
+}
+
+func (e FeeTooSmallError) Error() string {
+	return fmt.Sprintf("SubmitTx failed: feeTooSmall: required %v, actual %v", e.RequiredFee, e.ActualFee)
+}
+
+// MissingVkWitnessesError lists the key hashes whose signatures are missing
+type MissingVkWitnessesError struct {
+	KeyHashes []string
+}
+
+func (e MissingVkWitnessesError) Error() string {
+	return fmt.Sprintf("SubmitTx failed: missingVkWitnesses: %v", e.KeyHashes)
+}
+
+// OutputTooSmallError lists outputs below the minimum utxo value
+type OutputTooSmallError struct {
+	Outputs []chainsync.TxOut
+}
+
+func (e OutputTooSmallError) Error() string {
+	return fmt.Sprintf("SubmitTx failed: outputTooSmall: %v outputs", len(e.Outputs))
+}
+
+// ValidityInterval bounds the slots in which a tx may be included; nil bounds
+// are open
+type ValidityInterval struct {
+	InvalidBefore    *uint64 `json:"invalidBefore"`
+	InvalidHereafter *uint64 `json:"invalidHereafter"`
+}
+
+// OutsideOfValidityIntervalError indicates the current slot falls outside the
+// validity interval of the tx
+type OutsideOfValidityIntervalError struct {
+	Interval    ValidityInterval `json:"interval"`
+	CurrentSlot uint64           `json:"currentSlot"`
+}
+
+func (e OutsideOfValidityIntervalError) Error() string {
+	return fmt.Sprintf("SubmitTx failed: outsideOfValidityInterval: current slot %v", e.CurrentSlot)
+}
+
+// ScriptWitnessNotValidatingError lists the hashes of scripts that failed
+type ScriptWitnessNotValidatingError struct {
+	ScriptHashes []string
+}
+
+func (e ScriptWitnessNotValidatingError) Error() string {
+	return fmt.Sprintf("SubmitTx failed: scriptWitnessNotValidating: %v", e.ScriptHashes)
+}
+
+// TxTooLargeError indicates the serialized tx exceeds the maximum tx size
+type TxTooLargeError struct {
+	MaximumSize uint64 `json:"maximumSize"`
+	ActualSize  uint64 `json:"actualSize"`
+}
+
+func (e TxTooLargeError) Error() string {
+	return fmt.Sprintf("SubmitTx failed: txTooLarge: maximum %v, actual %v", e.MaximumSize, e.ActualSize)
+}
+
+// ValueNotConservedError indicates consumed and produced values differ
+type ValueNotConservedError struct {
+	Consumed chainsync.Value `json:"consumed"`
+	Produced chainsync.Value `json:"produced"`
+}
+
+func (e ValueNotConservedError) Error() string {
+	return fmt.Sprintf("SubmitTx failed: valueNotConserved: consumed %v, produced %v", e.Consumed.Coins, e.Produced.Coins)
+}
+
+// decodeSubmitTxFailure decodes the failure identified by code into its typed
+// error; codes without a dedicated type decode to SubmitTxFailure
+func decodeSubmitTxFailure(code string, data json.RawMessage) (error, error) {
+	var (
+		v   error
+		ptr interface{}
+	)
+	switch code {
+	case "badInputs":
+		var e BadInputsError
+		v, ptr = &e, &e.Inputs
+	case "collateralTooSmall":
+		var e CollateralTooSmallError
+		v, ptr = &e, &e
+	case "expiredUtxo":
+		var e ExpiredUtxoError
+		v, ptr = &e, &e
+	case "feeTooSmall":
+		var e FeeTooSmallError
+		v, ptr = &e, &e
+	case "missingVkWitnesses":
+		var e MissingVkWitnessesError
+		v, ptr = &e, &e.KeyHashes
+	case "outputTooSmall":
+		var e OutputTooSmallError
+		v, ptr = &e, &e.Outputs
+	case "outsideOfValidityInterval":
+		var e OutsideOfValidityIntervalError
+		v, ptr = &e, &e
+	case "scriptWitnessNotValidating":
+		var e ScriptWitnessNotValidatingError
+		v, ptr = &e, &e.ScriptHashes
+	case "txTooLarge":
+		var e TxTooLargeError
+		v, ptr = &e, &e
+	case "valueNotConserved":
+		var e ValueNotConservedError
+		v, ptr = &e, &e
+	default:
+		return SubmitTxFailure{Code: code, Data: data}, nil
+	}
+
+	if err := json.Unmarshal(data, ptr); err != nil {
+		return nil, fmt.Errorf("failed to decode %v: %w", code, err)
+	}
+	return reflect.ValueOf(v).Elem().Interface().(error), nil
+}
+
+// Failures returns each failure decoded into its typed error e.g.
+// FeeTooSmallError.  Failures without a dedicated type are returned as
+// SubmitTxFailure
+func (s SubmitTxError) Failures() ([]error, error) {
+	var failures []error
+	for _, data := range s.messages {
+		if bytes.HasPrefix(data, []byte(`"`)) {
+			var code string
+			if err := json.Unmarshal(data, &code); err != nil {
+				return nil, fmt.Errorf("failed to decode string, %v", string(data))
+			}
+			failures = append(failures, SubmitTxFailure{Code: code})
+			continue
+		}
+
+		var messages map[string]json.RawMessage
+		if err := json.Unmarshal(data, &messages); err != nil {
+			return nil, fmt.Errorf("failed to decode object, %v", string(data))
+		}
+		for code, message := range messages {
+			failure, err := decodeSubmitTxFailure(code, message)
+			if err != nil {
+				return nil, err
+			}
+			failures = append(failures, failure)
+		}
+	}
+	return failures, nil
+}
+
+// As allows errors.As to extract the typed failures of a SubmitTxError e.g.
+//
+//	var feeTooSmall ogmigo.FeeTooSmallError
+//	if errors.As(err, &feeTooSmall) {
+//	  ...
+//	}
+func (s SubmitTxError) As(target interface{}) bool {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return false
+	}
+
+	failures, _ := s.Failures()
+	for _, failure := range failures {
+		if reflect.TypeOf(failure).AssignableTo(value.Elem().Type()) {
+			value.Elem().Set(reflect.ValueOf(failure))
+			return true
+		}
+	}
+	return false
+}
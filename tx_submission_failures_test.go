@@ -0,0 +1,139 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestSubmitTxError_As(t *testing.T) {
+	data := []byte(`{"result":{"SubmitFail":[
+		{"badInputs":[{"txId":"abc","index":0}]},
+		{"feeTooSmall":{"requiredFee":170000,"actualFee":168000}},
+		{"outsideOfValidityInterval":{"interval":{"invalidBefore":null,"invalidHereafter":100},"currentSlot":120}},
+		{"valueNotConserved":{"consumed":{"coins":5000000},"produced":{"coins":4000000}}},
+		{"collateralTooSmall":{"requiredCollateral":3000000,"actualCollateral":2000000}},
+		{"scriptWitnessNotValidating":["deadbeef"]},
+		{"networkMismatch":{"expectedNetwork":"mainnet","invalidEntities":[]}},
+		"invalidMetadata"
+	]}}`)
+
+	err := fmt.Errorf("wrapped: %w", readSubmitTx(data))
+
+	t.Run("badInputs", func(t *testing.T) {
+		var e BadInputsError
+		if !errors.As(err, &e) {
+			t.Fatalf("got false; want true")
+		}
+		if got, want := len(e.Inputs), 1; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := e.Inputs[0].TxHash, "abc"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("feeTooSmall", func(t *testing.T) {
+		var e FeeTooSmallError
+		if !errors.As(err, &e) {
+			t.Fatalf("got false; want true")
+		}
+		if got, want := e.RequiredFee.Int64(), int64(170000); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := e.ActualFee.Int64(), int64(168000); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("outsideOfValidityInterval", func(t *testing.T) {
+		var e OutsideOfValidityIntervalError
+		if !errors.As(err, &e) {
+			t.Fatalf("got false; want true")
+		}
+		if e.Interval.InvalidBefore != nil {
+			t.Fatalf("got %v; want nil", *e.Interval.InvalidBefore)
+		}
+		if got, want := *e.Interval.InvalidHereafter, uint64(100); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := e.CurrentSlot, uint64(120); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("valueNotConserved", func(t *testing.T) {
+		var e ValueNotConservedError
+		if !errors.As(err, &e) {
+			t.Fatalf("got false; want true")
+		}
+		if got, want := e.Consumed.Coins.Int64(), int64(5000000); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("collateralTooSmall", func(t *testing.T) {
+		var e CollateralTooSmallError
+		if !errors.As(err, &e) {
+			t.Fatalf("got false; want true")
+		}
+		if got, want := e.RequiredCollateral.Int64(), int64(3000000); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("scriptWitnessNotValidating", func(t *testing.T) {
+		var e ScriptWitnessNotValidatingError
+		if !errors.As(err, &e) {
+			t.Fatalf("got false; want true")
+		}
+		if got, want := e.ScriptHashes, []string{"deadbeef"}; len(got) != 1 || got[0] != want[0] {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("untyped", func(t *testing.T) {
+		var e SubmitTxFailure
+		if !errors.As(err, &e) {
+			t.Fatalf("got false; want true")
+		}
+		if got, want := e.Code, "networkMismatch"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("absent", func(t *testing.T) {
+		var e TxTooLargeError
+		if errors.As(err, &e) {
+			t.Fatalf("got true; want false")
+		}
+	})
+
+	t.Run("SubmitTxError", func(t *testing.T) {
+		var e SubmitTxError
+		if !errors.As(err, &e) {
+			t.Fatalf("got false; want true")
+		}
+		failures, err := e.Failures()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := len(failures), 8; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+}
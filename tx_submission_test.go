@@ -16,6 +16,7 @@ package ogmigo
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/fs"
@@ -33,7 +34,7 @@ func TestClient_SubmitTx(t *testing.T) {
 
 	ctx := context.Background()
 	client := New(WithEndpoint(endpoint), WithLogger(DefaultLogger))
-	err := client.SubmitTx(ctx, []byte("blah"))
+	err := client.SubmitTx(ctx, []byte("84a3008001800200a0f5f6")) // well formed, but invalid
 
 	var e Error
 	ok := errors.As(err, &e)
@@ -42,6 +43,24 @@ func TestClient_SubmitTx(t *testing.T) {
 	}
 }
 
+func TestClient_SubmitTxMalformed(t *testing.T) {
+	endpoint, closer := fakeOgmios(t, func(methodName string, args json.RawMessage) (interface{}, error) {
+		t.Errorf("got %v; want malformed tx rejected before submission", methodName)
+		return nil, nil
+	})
+	defer closer()
+
+	ctx := context.Background()
+	client := New(WithEndpoint(endpoint), WithLogger(NopLogger))
+	defer client.Close()
+
+	for _, data := range []string{"blah", `{"cborHex":"ff"}`, "84a0a0f5f6"} {
+		if err := client.SubmitTx(ctx, []byte(data)); err == nil {
+			t.Fatalf("got nil; want err for %v", data)
+		}
+	}
+}
+
 func TestSubmitTxResult(t *testing.T) {
 	err := filepath.Walk("ext/ogmios/server/test/vectors/TxSubmission", testSubmitTxResult(t))
 	if err != nil {
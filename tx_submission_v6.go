@@ -0,0 +1,280 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/savaki/ogmigo/ouroboros/chainsync"
+	"github.com/savaki/ogmigo/ouroboros/chainsync/num"
+)
+
+// ogmios v6 reports submission and evaluation failures as JSON-RPC errors
+// with codes in the range [3000, 4000).  See
+// https://ogmios.dev/mini-protocols/local-tx-submission/#errors
+const (
+	incompatibleEraV6           = 3000
+	unsupportedEraV6            = 3001
+	overlappingAdditionalUtxoV6 = 3002
+	scriptExecutionFailureV6    = 3010
+	invalidRedeemerPointersV6   = 3011
+	validationFailureV6         = 3012
+	unsuitableOutputRefV6       = 3013
+	missingSignatoriesV6        = 3101
+	missingScriptsV6            = 3102
+	extraneousRedeemersV6       = 3110
+	missingDatumsV6             = 3111
+	missingCostModelsV6         = 3115
+	unknownOutputReferencesV6   = 3117
+	outsideOfValidityIntervalV6 = 3118
+	transactionTooLargeV6       = 3119
+	transactionFeeTooSmallV6    = 3122
+	valueNotConservedV6         = 3123
+	insufficientlyFundedV6      = 3125
+	insufficientCollateralV6    = 3128
+)
+
+// rpcFailure returns the code of err if it holds a v6 submission or
+// evaluation failure
+func rpcFailure(err error) (Error, int, bool) {
+	var e Error
+	if !errors.As(err, &e) {
+		return Error{}, 0, false
+	}
+	code, convErr := strconv.Atoi(e.Fault.Code)
+	if convErr != nil || code < 3000 || code >= 4000 {
+		return Error{}, 0, false
+	}
+	return e, code, true
+}
+
+// readSubmitTxV6 translates a v6 submission failure into a SubmitTxError
+// holding the equivalent v5 failure so the typed failures e.g.
+// FeeTooSmallError are available regardless of protocol.  Failures without a
+// v5 equivalent are keyed by their v6 error code
+func readSubmitTxV6(e Error, code int) error {
+	var (
+		key   string
+		value interface{}
+		err   error
+	)
+	switch code {
+	case unknownOutputReferencesV6:
+		var content struct{ UnknownOutputReferences []chainsync.TxInV6 }
+		err = json.Unmarshal(e.Fault.Data, &content)
+		key, value = "badInputs", txInsFromV6(content.UnknownOutputReferences)
+
+	case missingSignatoriesV6:
+		var content struct{ MissingSignatories []string }
+		err = json.Unmarshal(e.Fault.Data, &content)
+		key, value = "missingVkWitnesses", content.MissingSignatories
+
+	case outsideOfValidityIntervalV6:
+		var content struct {
+			ValidityInterval struct {
+				InvalidBefore *uint64
+				InvalidAfter  *uint64
+			}
+			CurrentSlot uint64
+		}
+		err = json.Unmarshal(e.Fault.Data, &content)
+		key, value = "outsideOfValidityInterval", OutsideOfValidityIntervalError{
+			Interval: ValidityInterval{
+				InvalidBefore:    content.ValidityInterval.InvalidBefore,
+				InvalidHereafter: content.ValidityInterval.InvalidAfter,
+			},
+			CurrentSlot: content.CurrentSlot,
+		}
+
+	case transactionTooLargeV6:
+		var content struct {
+			MeasuredTransactionSize uint64
+			MaximumTransactionSize  uint64
+		}
+		err = json.Unmarshal(e.Fault.Data, &content)
+		key, value = "txTooLarge", TxTooLargeError{
+			MaximumSize: content.MaximumTransactionSize,
+			ActualSize:  content.MeasuredTransactionSize,
+		}
+
+	case transactionFeeTooSmallV6:
+		var content struct {
+			MinimumRequiredFee chainsync.LovelaceV6
+			ProvidedFee        chainsync.LovelaceV6
+		}
+		err = json.Unmarshal(e.Fault.Data, &content)
+		key, value = "feeTooSmall", FeeTooSmallError{
+			RequiredFee: num.Int(content.MinimumRequiredFee),
+			ActualFee:   num.Int(content.ProvidedFee),
+		}
+
+	case valueNotConservedV6:
+		var content struct {
+			ValueConsumed chainsync.ValueV6
+			ValueProduced chainsync.ValueV6
+		}
+		err = json.Unmarshal(e.Fault.Data, &content)
+		key, value = "valueNotConserved", ValueNotConservedError{
+			Consumed: chainsync.Value(content.ValueConsumed),
+			Produced: chainsync.Value(content.ValueProduced),
+		}
+
+	case insufficientlyFundedV6:
+		var content struct {
+			InsufficientlyFundedOutputs []struct{ Output chainsync.TxOutV6 }
+		}
+		err = json.Unmarshal(e.Fault.Data, &content)
+		var outputs []chainsync.TxOut
+		for _, item := range content.InsufficientlyFundedOutputs {
+			outputs = append(outputs, chainsync.TxOut(item.Output))
+		}
+		key, value = "outputTooSmall", outputs
+
+	case insufficientCollateralV6:
+		var content struct {
+			ProvidedCollateral        chainsync.LovelaceV6
+			MinimumRequiredCollateral chainsync.LovelaceV6
+		}
+		err = json.Unmarshal(e.Fault.Data, &content)
+		key, value = "collateralTooSmall", CollateralTooSmallError{
+			RequiredCollateral: num.Int(content.MinimumRequiredCollateral),
+			ActualCollateral:   num.Int(content.ProvidedCollateral),
+		}
+
+	default:
+		key, value = strconv.Itoa(code), e.Fault.Data
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse submitTransaction error, %v: %w", code, err)
+	}
+
+	message, err := json.Marshal(map[string]interface{}{key: value})
+	if err != nil {
+		return fmt.Errorf("failed to parse submitTransaction error, %v: %w", code, err)
+	}
+	return SubmitTxError{messages: []json.RawMessage{message}}
+}
+
+// scriptFailureCodesV6 maps the v6 codes of script failures to the v5 codes
+var scriptFailureCodesV6 = map[int]string{
+	invalidRedeemerPointersV6: "extraRedeemers",
+	validationFailureV6:       "validatorFailed",
+	unsuitableOutputRefV6:     "nonScriptInputReferencedByRedeemer",
+	missingScriptsV6:          "missingRequiredScripts",
+	extraneousRedeemersV6:     "extraRedeemers",
+	missingDatumsV6:           "missingRequiredDatums",
+	missingCostModelsV6:       "noCostModelForLanguage",
+	unknownOutputReferencesV6: "unknownInputReferencedByRedeemer",
+}
+
+// readEvaluateTxV6 translates a v6 evaluation failure into an
+// EvaluateTxError.  Failures without a v5 equivalent are keyed by their v6
+// error code
+func readEvaluateTxV6(e Error, code int) error {
+	var (
+		result = EvaluateTxError{raw: map[string]json.RawMessage{}}
+		err    error
+	)
+	switch code {
+	case incompatibleEraV6, unsupportedEraV6:
+		var content struct {
+			IncompatibleEra string
+			UnsupportedEra  string
+		}
+		err = json.Unmarshal(e.Fault.Data, &content)
+		result.IncompatibleEra = content.IncompatibleEra
+		if result.IncompatibleEra == "" {
+			result.IncompatibleEra = content.UnsupportedEra
+		}
+		result.raw["IncompatibleEra"] = e.Fault.Data
+
+	case overlappingAdditionalUtxoV6:
+		var content struct{ OverlappingOutputReferences []chainsync.TxInV6 }
+		err = json.Unmarshal(e.Fault.Data, &content)
+		result.AdditionalUtxoOverlap = txInsFromV6(content.OverlappingOutputReferences)
+		result.raw["AdditionalUtxoOverlap"] = e.Fault.Data
+
+	case unknownOutputReferencesV6:
+		var content struct{ UnknownOutputReferences []chainsync.TxInV6 }
+		err = json.Unmarshal(e.Fault.Data, &content)
+		result.UnknownInputs = txInsFromV6(content.UnknownOutputReferences)
+		result.raw["UnknownInputs"] = e.Fault.Data
+
+	case scriptExecutionFailureV6:
+		result.ScriptFailures, err = readScriptFailuresV6(e.Fault.Data)
+		result.raw["ScriptFailures"] = e.Fault.Data
+
+	default:
+		result.raw[strconv.Itoa(code)] = e.Fault.Data
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse evaluateTransaction error, %v: %w", code, err)
+	}
+	return result
+}
+
+// readScriptFailuresV6 decodes [{"validator": ..., "error": ...}] keyed by
+// redeemer pointer e.g. spend:0
+func readScriptFailuresV6(data json.RawMessage) (map[string][]ScriptFailure, error) {
+	var items []struct {
+		Validator struct {
+			Purpose string
+			Index   int
+		}
+		Error struct {
+			Code int
+			Data json.RawMessage
+		}
+	}
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
+
+	failures := map[string][]ScriptFailure{}
+	for _, item := range items {
+		redeemer := item.Validator.Purpose + ":" + strconv.Itoa(item.Validator.Index)
+		failure := ScriptFailure{Code: strconv.Itoa(item.Error.Code), Data: item.Error.Data}
+		if code, ok := scriptFailureCodesV6[item.Error.Code]; ok {
+			failure.Code = code
+		}
+		if item.Error.Code == validationFailureV6 {
+			var content struct {
+				ValidationError string
+				Traces          []string
+			}
+			if err := json.Unmarshal(item.Error.Data, &content); err != nil {
+				return nil, err
+			}
+			data, err := json.Marshal(map[string]interface{}{"error": content.ValidationError, "traces": content.Traces})
+			if err != nil {
+				return nil, err
+			}
+			failure.Data = data
+		}
+		failures[redeemer] = append(failures[redeemer], failure)
+	}
+	return failures, nil
+}
+
+func txInsFromV6(items []chainsync.TxInV6) []chainsync.TxIn {
+	txIns := make([]chainsync.TxIn, 0, len(items))
+	for _, item := range items {
+		txIns = append(txIns, chainsync.TxIn(item))
+	}
+	return txIns
+}
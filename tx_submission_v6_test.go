@@ -0,0 +1,223 @@
+// Copyright 2021 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogmigo
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/savaki/ogmigo/ouroboros/chainsync"
+)
+
+// readFailureV6 returns the failure held by the JSON-RPC error fixture as
+// returned by conn.query
+func readFailureV6(t *testing.T, name string) (Error, int) {
+	t.Helper()
+	err := readRPCError(readFixture(t, "testdata/v6", name))
+	e, code, ok := rpcFailure(err)
+	if !ok {
+		t.Fatalf("got %v; want v6 failure", err)
+	}
+	return e, code
+}
+
+func TestReadSubmitTxV6(t *testing.T) {
+	var (
+		txHash  = strings.Repeat("a", 64)
+		keyHash = strings.Repeat("b", 56)
+		assetID = chainsync.AssetID(strings.Repeat("c", 56) + ".746f6b656e")
+	)
+
+	tests := map[string]struct {
+		Fixture string
+		Code    string
+		Assert  func(t *testing.T, err error)
+	}{
+		"fee too small": {
+			Fixture: "submitTransactionFeeTooSmall.json",
+			Code:    "feeTooSmall",
+			Assert: func(t *testing.T, err error) {
+				var e FeeTooSmallError
+				if !errors.As(err, &e) {
+					t.Fatalf("got %v; want FeeTooSmallError", err)
+				}
+				if got, want := e.RequiredFee.Int64(), int64(172761); got != want {
+					t.Fatalf("got %v; want %v", got, want)
+				}
+				if got, want := e.ActualFee.Int64(), int64(170000); got != want {
+					t.Fatalf("got %v; want %v", got, want)
+				}
+			},
+		},
+		"unknown inputs": {
+			Fixture: "submitTransactionUnknownOutputReferences.json",
+			Code:    "badInputs",
+			Assert: func(t *testing.T, err error) {
+				var e BadInputsError
+				if !errors.As(err, &e) {
+					t.Fatalf("got %v; want BadInputsError", err)
+				}
+				if got, want := e.Inputs, []chainsync.TxIn{{TxHash: txHash, Index: 1}}; !reflect.DeepEqual(got, want) {
+					t.Fatalf("got %v; want %v", got, want)
+				}
+			},
+		},
+		"missing signatories": {
+			Fixture: "submitTransactionMissingSignatories.json",
+			Code:    "missingVkWitnesses",
+			Assert: func(t *testing.T, err error) {
+				var e MissingVkWitnessesError
+				if !errors.As(err, &e) {
+					t.Fatalf("got %v; want MissingVkWitnessesError", err)
+				}
+				if got, want := e.KeyHashes, []string{keyHash}; !reflect.DeepEqual(got, want) {
+					t.Fatalf("got %v; want %v", got, want)
+				}
+			},
+		},
+		"outside of validity interval": {
+			Fixture: "submitTransactionOutsideOfValidityInterval.json",
+			Code:    "outsideOfValidityInterval",
+			Assert: func(t *testing.T, err error) {
+				var e OutsideOfValidityIntervalError
+				if !errors.As(err, &e) {
+					t.Fatalf("got %v; want OutsideOfValidityIntervalError", err)
+				}
+				if e.Interval.InvalidBefore != nil || e.Interval.InvalidHereafter == nil || *e.Interval.InvalidHereafter != 1000 {
+					t.Fatalf("got %#v; want invalid hereafter 1000", e.Interval)
+				}
+				if got, want := e.CurrentSlot, uint64(1200); got != want {
+					t.Fatalf("got %v; want %v", got, want)
+				}
+			},
+		},
+		"value not conserved": {
+			Fixture: "submitTransactionValueNotConserved.json",
+			Code:    "valueNotConserved",
+			Assert: func(t *testing.T, err error) {
+				var e ValueNotConservedError
+				if !errors.As(err, &e) {
+					t.Fatalf("got %v; want ValueNotConservedError", err)
+				}
+				if got, want := e.Consumed.Coins.Int64(), int64(5000000); got != want {
+					t.Fatalf("got %v; want %v", got, want)
+				}
+				if got, want := e.Produced.Assets[assetID].Int64(), int64(10); got != want {
+					t.Fatalf("got %v; want %v", got, want)
+				}
+			},
+		},
+		"insufficiently funded outputs": {
+			Fixture: "submitTransactionInsufficientlyFundedOutputs.json",
+			Code:    "outputTooSmall",
+			Assert: func(t *testing.T, err error) {
+				var e OutputTooSmallError
+				if !errors.As(err, &e) {
+					t.Fatalf("got %v; want OutputTooSmallError", err)
+				}
+				if got, want := len(e.Outputs), 1; got != want {
+					t.Fatalf("got %v; want %v", got, want)
+				}
+				if got, want := e.Outputs[0].Value.Coins.Int64(), int64(1000); got != want {
+					t.Fatalf("got %v; want %v", got, want)
+				}
+			},
+		},
+		"no v5 equivalent": {
+			Fixture: "submitTransactionEraMismatch.json",
+			Code:    "3005",
+			Assert: func(t *testing.T, err error) {
+				var e SubmitTxFailure
+				if !errors.As(err, &e) {
+					t.Fatalf("got %v; want SubmitTxFailure", err)
+				}
+				if got, want := string(e.Data), `{"queryEra":"babbage","ledgerEra":"conway"}`; got != want {
+					t.Fatalf("got %v; want %v", got, want)
+				}
+			},
+		},
+	}
+
+	for label, tc := range tests {
+		t.Run(label, func(t *testing.T) {
+			err := readSubmitTxV6(readFailureV6(t, tc.Fixture))
+
+			var submitTxError SubmitTxError
+			if !errors.As(err, &submitTxError) {
+				t.Fatalf("got %v; want SubmitTxError", err)
+			}
+			if !submitTxError.HasErrorCode(tc.Code) {
+				t.Fatalf("got %v; want %v", err, tc.Code)
+			}
+			tc.Assert(t, err)
+		})
+	}
+}
+
+func TestReadEvaluateTxV6(t *testing.T) {
+	txHash := strings.Repeat("a", 64)
+
+	t.Run("script failures", func(t *testing.T) {
+		err := readEvaluateTxV6(readFailureV6(t, "evaluateTransactionScriptExecutionFailure.json"))
+		var e EvaluateTxError
+		if !errors.As(err, &e) {
+			t.Fatalf("got %v; want EvaluateTxError", err)
+		}
+		if got, want := e.ErrorCodes(), []string{"ScriptFailures", "missingRequiredDatums", "validatorFailed"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		failures := e.ScriptFailures["spend:0"]
+		if got, want := len(failures), 1; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		msg, traces, ok := failures[0].ValidatorFailed()
+		if !ok {
+			t.Fatalf("got false; want true")
+		}
+		if !strings.HasPrefix(msg, "An error has occurred") {
+			t.Fatalf("got %v; want validation error", msg)
+		}
+		if got, want := traces, []string{"deadline not reached"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := e.ScriptFailures["mint:1"][0].Code, "missingRequiredDatums"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("unknown inputs", func(t *testing.T) {
+		err := readEvaluateTxV6(readFailureV6(t, "evaluateTransactionUnknownOutputReferences.json"))
+		var e EvaluateTxError
+		if !errors.As(err, &e) {
+			t.Fatalf("got %v; want EvaluateTxError", err)
+		}
+		if got, want := e.UnknownInputs, []chainsync.TxIn{{TxHash: txHash, Index: 0}}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("incompatible era", func(t *testing.T) {
+		err := readEvaluateTxV6(readFailureV6(t, "evaluateTransactionIncompatibleEra.json"))
+		var e EvaluateTxError
+		if !errors.As(err, &e) {
+			t.Fatalf("got %v; want EvaluateTxError", err)
+		}
+		if got, want := e.IncompatibleEra, "mary"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+}
@@ -14,39 +14,136 @@
 
 package ogmigo
 
+import (
+	"fmt"
+
+	"github.com/savaki/ogmigo/ouroboros/chainsync"
+)
+
 // Map provides a simple type alias
 type Map map[string]interface{}
 
 type circular struct {
 	index int
-	data  [][]byte
+	data  []*chainSyncMessage
 }
 
 func newCircular(cap int) *circular {
 	return &circular{
-		data: make([][]byte, cap),
+		data: make([]*chainSyncMessage, cap),
 	}
 }
 
-func (c *circular) add(data []byte) {
+func (c *circular) add(data *chainSyncMessage) {
 	c.data[c.index] = data
 	c.index = (c.index + 1) % len(c.data)
 }
 
-func (c *circular) list() (data [][]byte) {
+func (c *circular) list() (data []*chainSyncMessage) {
 	for i := 0; i < len(c.data); i++ {
 		offset := (c.index + i) % len(c.data)
-		if v := c.data[offset]; len(v) > 0 {
+		if v := c.data[offset]; v != nil {
 			data = append(data, v)
 		}
 	}
 	return data
 }
 
-func (c *circular) prefix(data ...[]byte) [][]byte {
+func (c *circular) prefix(data ...*chainSyncMessage) []*chainSyncMessage {
 	return append(data, c.list()...)
 }
 
+// blockQueue buffers RollForward messages until they are buried beneath depth
+// blocks.  Unlike circular, blocks may be removed from the tail on rollback
+type blockQueue struct {
+	depth     uint64
+	blocks    []queuedBlock
+	delivered *chainsync.PointStruct // point of the last block returned by push
+	buffered  bool                   // true once a block has been queued
+}
+
+type queuedBlock struct {
+	point chainsync.PointStruct
+	msg   *chainSyncMessage
+}
+
+func newBlockQueue(depth uint64) *blockQueue {
+	return &blockQueue{
+		depth: depth,
+	}
+}
+
+// push adds the message to the queue and returns the messages, oldest first,
+// that have reached the required depth.  Messages other than RollForward are
+// returned immediately
+func (q *blockQueue) push(msg *chainSyncMessage) ([]*chainSyncMessage, error) {
+	response, err := msg.decode()
+	if err != nil {
+		return nil, err
+	}
+	if response.Result == nil || response.Result.RollForward == nil {
+		return []*chainSyncMessage{msg}, nil
+	}
+
+	rollForward := response.Result.RollForward
+	q.buffered = true
+	q.blocks = append(q.blocks, queuedBlock{
+		point: rollForward.Block.PointStruct(),
+		msg:   msg,
+	})
+
+	tip, ok := rollForward.Tip.PointStruct()
+	if !ok {
+		return nil, fmt.Errorf("unable to determine depth: tip, %v, has no block number", rollForward.Tip)
+	}
+
+	var n int
+	for n < len(q.blocks) && q.blocks[n].point.BlockNo+q.depth <= tip.BlockNo {
+		n++
+	}
+	if n == 0 {
+		return nil, nil
+	}
+
+	ready := make([]*chainSyncMessage, 0, n)
+	for _, block := range q.blocks[:n] {
+		ready = append(ready, block.msg)
+	}
+	delivered := q.blocks[n-1].point
+	q.delivered = &delivered
+	q.blocks = append(q.blocks[:0], q.blocks[n:]...)
+
+	return ready, nil
+}
+
+// rollback discards the queued blocks after the point.  returns true if the
+// rollback must be forwarded i.e. it undoes blocks previously returned by push
+// or, as with the RollBackward to the intersection, no block has been queued
+// yet
+func (q *blockQueue) rollback(point chainsync.Point) bool {
+	ps, ok := point.PointStruct()
+	if !ok {
+		q.blocks = q.blocks[:0] // rollback to origin
+		q.delivered = nil
+		return true
+	}
+
+	n := len(q.blocks)
+	for n > 0 && q.blocks[n-1].point.Slot > ps.Slot {
+		n--
+	}
+	q.blocks = q.blocks[:n]
+
+	if q.delivered == nil {
+		return !q.buffered
+	}
+	if ps.Slot >= q.delivered.Slot {
+		return false
+	}
+	q.delivered = ps
+	return true
+}
+
 func makePayload(methodName string, args Map) Map {
 	return Map{
 		"type":        "jsonwsp/request",
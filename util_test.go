@@ -17,41 +17,43 @@ package ogmigo
 import (
 	"reflect"
 	"testing"
+
+	"github.com/savaki/ogmigo/ouroboros/chainsync"
 )
 
 func Test_circular_list(t *testing.T) {
-	a := []byte("a")
-	b := []byte("b")
-	c := []byte("c")
-	d := []byte("d")
-	e := []byte("e")
+	a := &chainSyncMessage{data: []byte("a")}
+	b := &chainSyncMessage{data: []byte("b")}
+	c := &chainSyncMessage{data: []byte("c")}
+	d := &chainSyncMessage{data: []byte("d")}
+	e := &chainSyncMessage{data: []byte("e")}
 	tests := map[string]struct {
-		Inputs [][]byte
-		Want   [][]byte
+		Inputs []*chainSyncMessage
+		Want   []*chainSyncMessage
 	}{
 		"nop": {
 			Inputs: nil,
 			Want:   nil,
 		},
 		"1": {
-			Inputs: [][]byte{a},
-			Want:   [][]byte{a},
+			Inputs: []*chainSyncMessage{a},
+			Want:   []*chainSyncMessage{a},
 		},
 		"2": {
-			Inputs: [][]byte{a, b},
-			Want:   [][]byte{a, b},
+			Inputs: []*chainSyncMessage{a, b},
+			Want:   []*chainSyncMessage{a, b},
 		},
 		"3": {
-			Inputs: [][]byte{a, b, c},
-			Want:   [][]byte{a, b, c},
+			Inputs: []*chainSyncMessage{a, b, c},
+			Want:   []*chainSyncMessage{a, b, c},
 		},
 		"4": {
-			Inputs: [][]byte{a, b, c, d},
-			Want:   [][]byte{b, c, d},
+			Inputs: []*chainSyncMessage{a, b, c, d},
+			Want:   []*chainSyncMessage{b, c, d},
 		},
 		"5": {
-			Inputs: [][]byte{a, b, c, d, e},
-			Want:   [][]byte{c, d, e},
+			Inputs: []*chainSyncMessage{a, b, c, d, e},
+			Want:   []*chainSyncMessage{c, d, e},
 		},
 	}
 
@@ -69,3 +71,55 @@ func Test_circular_list(t *testing.T) {
 		})
 	}
 }
+
+func Test_blockQueue(t *testing.T) {
+	var (
+		a = &chainSyncMessage{data: []byte(rollForward(100, 1, 1))}
+		b = &chainSyncMessage{data: []byte(rollForward(200, 2, 2))}
+		c = &chainSyncMessage{data: []byte(rollForward(300, 3, 3))}
+		d = &chainSyncMessage{data: []byte(rollForward(400, 4, 4))}
+		q = newBlockQueue(2)
+	)
+
+	push := func(msg *chainSyncMessage, want ...*chainSyncMessage) {
+		got, err := q.push(msg)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if len(got) != len(want) || (len(want) > 0 && !reflect.DeepEqual(got, want)) {
+			t.Fatalf("got %v messages; want %v", len(got), len(want))
+		}
+	}
+
+	// the rollback to the intersection is forwarded
+	if got := q.rollback(chainsync.PointStruct{Slot: 50}.Point()); !got {
+		t.Fatalf("got false; want true")
+	}
+
+	push(a)
+	push(b)
+	push(c, a)
+
+	if got := q.rollback(chainsync.PointStruct{Slot: 200}.Point()); got {
+		t.Fatalf("got true; want false")
+	}
+	if got, want := len(q.blocks), 1; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	push(d, b)
+
+	// rollback to the last block returned has nothing to undo
+	if got := q.rollback(chainsync.PointStruct{Slot: 200}.Point()); got {
+		t.Fatalf("got true; want false")
+	}
+	if got := q.rollback(chainsync.PointStruct{Slot: 100}.Point()); !got {
+		t.Fatalf("got false; want true")
+	}
+	if got := q.rollback(chainsync.Origin); !got {
+		t.Fatalf("got false; want true")
+	}
+
+	found := &chainSyncMessage{data: []byte(intersectionFound)}
+	push(found, found)
+}
@@ -18,55 +18,230 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
+	"sync"
 	"sync/atomic"
+	"time"
 
+	"github.com/buger/jsonparser"
 	"github.com/gorilla/websocket"
 )
 
-var fault = []byte(`jsonwsp/fault`)
-
-func (c *Client) query(ctx context.Context, payload interface{}, v interface{}) (err error) {
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
-
-	var (
-		ch     = make(chan error, 1)
-		conn   *websocket.Conn
-		closed int64 // ensures close is only called once
-	)
-	go func() {
-		<-ctx.Done()
-		if conn != nil {
-			ch <- ctx.Err()
-			if v := atomic.AddInt64(&closed, 1); v == 1 {
-				conn.Close()
+var (
+	fault = []byte(`jsonwsp/fault`)
+
+	errClientClosed = errors.New("ogmigo client closed")
+)
+
+// query submits the payload on a pooled connection.  a connection may fail
+// before the pool notices; requests that were never written are retried once
+// on a fresh connection
+func (c *Client) query(ctx context.Context, payload Map, v interface{}) error {
+	for attempt := 0; ; attempt++ {
+		conn, err := c.pool.get(ctx)
+		if err != nil {
+			return err
+		}
+		err = conn.query(ctx, payload, v)
+		var unsent unsentError
+		if attempt == 0 && errors.As(err, &unsent) && ctx.Err() == nil {
+			continue
+		}
+		return err
+	}
+}
+
+// unsentError indicates the request was not written to ogmios and may safely
+// be submitted again
+type unsentError struct {
+	err error
+}
+
+func (e unsentError) Error() string { return "failed to submit request: " + e.err.Error() }
+func (e unsentError) Unwrap() error { return e.err }
+
+// pool holds a fixed number of long-lived websocket connections to ogmios.
+// connections are dialed lazily and redialed the next time they are needed
+// after a failure
+type pool struct {
+	endpoint string
+	logger   Logger
+
+	counter uint64 // round robin index into conns
+	pinned  bool   // pinned pools hold a single stateful connection that must not be redialed
+
+	mutex   sync.Mutex
+	conns   []*conn
+	dialing map[int]chan struct{} // closed when the dial of the conn at the index completes
+	closed  bool
+}
+
+func newPool(endpoint string, logger Logger, size int) *pool {
+	return &pool{
+		endpoint: endpoint,
+		logger:   logger,
+		conns:    make([]*conn, size),
+		dialing:  map[int]chan struct{}{},
+	}
+}
+
+// pinnedPool returns a pool that always returns the provided connection
+func pinnedPool(endpoint string, c *conn) *pool {
+	return &pool{
+		endpoint: endpoint,
+		logger:   c.logger,
+		pinned:   true,
+		conns:    []*conn{c},
+	}
+}
+
+// get returns an open connection, dialing a new connection if required.
+// dials happen outside the lock so a slow dial blocks only the queries
+// waiting on that connection
+func (p *pool) get(ctx context.Context) (*conn, error) {
+	index := int(atomic.AddUint64(&p.counter, 1) % uint64(len(p.conns)))
+
+	for {
+		p.mutex.Lock()
+		if p.closed {
+			p.mutex.Unlock()
+			return nil, errClientClosed
+		}
+		if c := p.conns[index]; c != nil && c.alive() {
+			p.mutex.Unlock()
+			return c, nil
+		}
+		if p.pinned {
+			p.mutex.Unlock()
+			return nil, fmt.Errorf("failed to submit request: %w", p.conns[index].reason())
+		}
+		if dialing, ok := p.dialing[index]; ok {
+			p.mutex.Unlock()
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-dialing:
+				continue
 			}
 		}
-	}()
+		dialing := make(chan struct{})
+		p.dialing[index] = dialing
+		p.mutex.Unlock()
+
+		c, err := p.dial(ctx)
+
+		p.mutex.Lock()
+		delete(p.dialing, index)
+		close(dialing)
+		if err != nil {
+			p.mutex.Unlock()
+			return nil, err
+		}
+		if p.closed {
+			p.mutex.Unlock()
+			c.close(errClientClosed)
+			return nil, errClientClosed
+		}
+		p.conns[index] = c
+		p.mutex.Unlock()
+		return c, nil
+	}
+}
 
-	conn, _, err = websocket.DefaultDialer.DialContext(ctx, c.options.endpoint, nil)
+// dial returns a new connection that is not shared via the pool; useful for
+// stateful protocols
+func (p *pool) dial(ctx context.Context) (*conn, error) {
+	ws, _, err := websocket.DefaultDialer.DialContext(ctx, p.endpoint, nil)
 	if err != nil {
-		return fmt.Errorf("failed to connect to ogmios, %v: %w", c.options.endpoint, err)
+		return nil, fmt.Errorf("failed to connect to ogmios, %v: %w", p.endpoint, err)
 	}
-	defer func() {
-		if v := atomic.AddInt64(&closed, 1); v == 1 {
-			conn.Close()
-		} else {
-			err = <-ch
+	return newConn(ws, p.logger), nil
+}
+
+// Close closes all open connections
+func (p *pool) Close() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.closed = true
+	for _, c := range p.conns {
+		if c != nil {
+			c.close(errClientClosed)
 		}
-	}()
+	}
+	return nil
+}
+
+// conn multiplexes concurrent requests over a single websocket connection.
+// requests are tagged with a unique mirror that ogmios echoes back as the
+// reflection of the response or, for JSON-RPC requests, a unique id
+type conn struct {
+	ws      *websocket.Conn
+	logger  Logger
+	counter uint64 // source of unique mirror ids
+	done    chan struct{}
+	writes  sync.Mutex // websocket supports only one concurrent writer
+
+	mutex   sync.Mutex
+	pending map[string]chan json.RawMessage
+	err     error // err holds the reason the connection was closed
+}
 
-	if err := conn.WriteJSON(payload); err != nil {
-		return fmt.Errorf("failed to submit request: %w", err)
+func newConn(ws *websocket.Conn, logger Logger) *conn {
+	c := &conn{
+		ws:      ws,
+		logger:  logger,
+		done:    make(chan struct{}),
+		pending: map[string]chan json.RawMessage{},
 	}
+	go c.readLoop()
+	return c
+}
 
-	var raw json.RawMessage
-	if err := conn.ReadJSON(&raw); err != nil {
-		return fmt.Errorf("failed to read json response: %w", err)
+func (c *conn) alive() bool {
+	select {
+	case <-c.done:
+		return false
+	default:
+		return true
 	}
+}
 
-	if bytes.Contains(raw, fault) {
+// reason returns the error the connection was closed with, if any
+func (c *conn) reason() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.err
+}
+
+// close fails all pending requests with the provided error; only the first
+// call has any effect
+func (c *conn) close(err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.err != nil {
+		return
+	}
+	c.err = err
+	close(c.done)
+	_ = c.ws.Close()
+}
+
+// query submits the payload and decodes the response into v
+func (c *conn) query(ctx context.Context, payload Map, v interface{}) error {
+	raw, err := c.do(ctx, payload)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := payload["jsonrpc"]; ok {
+		if err := readRPCError(raw); err != nil {
+			return err
+		}
+	} else if bytes.Contains(raw, fault) {
 		var e Error
 		if err := json.Unmarshal(raw, &e); err != nil {
 			return fmt.Errorf("failed to decode error: %w", err)
@@ -82,3 +257,137 @@ func (c *Client) query(ctx context.Context, payload interface{}, v interface{})
 
 	return nil
 }
+
+// readRPCError returns the error held by a JSON-RPC response as an Error, if any
+func readRPCError(raw json.RawMessage) error {
+	var content struct {
+		Error *struct {
+			Code    int             `json:"code"`
+			Message string          `json:"message"`
+			Data    json.RawMessage `json:"data"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(raw, &content); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	if e := content.Error; e != nil {
+		return Error{
+			Type:        "jsonrpc/error",
+			Version:     "2.0",
+			ServiceName: "ogmios",
+			Fault: Fault{
+				Code:   strconv.Itoa(e.Code),
+				String: e.Message,
+				Data:   e.Data,
+			},
+		}
+	}
+	return nil
+}
+
+// do submits the payload and waits for the matching response
+func (c *conn) do(ctx context.Context, payload Map) (json.RawMessage, error) {
+	id := strconv.FormatUint(atomic.AddUint64(&c.counter, 1), 10)
+	ch := make(chan json.RawMessage, 1)
+
+	c.mutex.Lock()
+	if err := c.err; err != nil {
+		c.mutex.Unlock()
+		return nil, unsentError{err: err}
+	}
+	c.pending[id] = ch
+	c.mutex.Unlock()
+
+	defer func() {
+		c.mutex.Lock()
+		delete(c.pending, id)
+		c.mutex.Unlock()
+	}()
+
+	// copy the payload as callers may reuse it concurrently
+	request := make(Map, len(payload)+1)
+	for k, v := range payload {
+		request[k] = v
+	}
+	if _, ok := payload["jsonrpc"]; ok {
+		request["id"] = id
+	} else {
+		request["mirror"] = Map{"id": id}
+	}
+	if err := c.write(ctx, request); err != nil {
+		c.close(err)
+		return nil, unsentError{err: err}
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case raw := <-ch:
+		return raw, nil
+	case <-c.done:
+		return nil, fmt.Errorf("failed to read json response: %w", c.reason())
+	}
+}
+
+func (c *conn) write(ctx context.Context, payload Map) error {
+	c.writes.Lock()
+	defer c.writes.Unlock()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := c.ws.SetWriteDeadline(deadline); err != nil {
+			return err
+		}
+		defer c.ws.SetWriteDeadline(time.Time{})
+	}
+	return c.ws.WriteJSON(payload)
+}
+
+func (c *conn) readLoop() {
+	for {
+		var raw json.RawMessage
+		if err := c.ws.ReadJSON(&raw); err != nil {
+			c.close(err)
+			return
+		}
+
+		id, err := jsonparser.GetString(raw, "reflection", "id")
+		if err != nil {
+			id, err = jsonparser.GetString(raw, "id") // JSON-RPC responses echo the id
+		}
+
+		var chs []chan json.RawMessage
+		c.mutex.Lock()
+		if ch, ok := c.pending[id]; ok {
+			chs = append(chs, ch)
+		} else if err != nil && isFault(raw) {
+			// faults for requests ogmios could not parse carry no reflection
+			// so there is no telling which request failed; fail them all
+			// rather than leave them waiting
+			for _, ch := range c.pending {
+				chs = append(chs, ch)
+			}
+		}
+		c.mutex.Unlock()
+
+		if len(chs) == 0 {
+			c.logger.Info("ogmigo dropped unexpected message", KV("reflection", id))
+			continue
+		}
+		for _, ch := range chs {
+			select {
+			case ch <- raw:
+			default:
+				// response already delivered
+			}
+		}
+	}
+}
+
+// isFault returns true if raw holds a jsonwsp fault or a JSON-RPC error
+func isFault(raw json.RawMessage) bool {
+	if bytes.Contains(raw, fault) {
+		return true
+	}
+	_, _, _, err := jsonparser.Get(raw, "error")
+	return err == nil
+}
@@ -2,12 +2,15 @@ package ogmigo
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
 	"net"
 	"net/http"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -65,8 +68,319 @@ func TestClient_query(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 250*time.Millisecond)
 	defer cancel()
 
-	err = client.SubmitTx(ctx, []byte(`{}`))
+	err = client.SubmitTx(ctx, []byte(`{"cborHex":"84a3008001800200a0f5f6"}`))
 	if ok := errors.Is(err, context.DeadlineExceeded); !ok {
 		t.Fatalf("expected context.Canceled; got %v", err)
 	}
 }
+
+// mirror responds to each request concurrently, echoing the mirror as the
+// reflection.  the connection is dropped after maxRequests requests
+func mirror(maxRequests int, dials *int64) http.HandlerFunc {
+	var upgrader = websocket.Upgrader{} // use default options
+	return func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt64(dials, 1)
+
+		c, err := upgrader.Upgrade(w, req, nil)
+		if err != nil {
+			log.Print("upgrade:", err)
+			return
+		}
+		defer c.Close()
+
+		var mutex sync.Mutex
+		for i := 0; i < maxRequests; i++ {
+			var request struct {
+				Args   json.RawMessage `json:"args"`
+				Mirror json.RawMessage `json:"mirror"`
+			}
+			if err := c.ReadJSON(&request); err != nil {
+				return
+			}
+
+			go func() {
+				time.Sleep(time.Duration(len(request.Mirror)%3) * time.Millisecond)
+
+				mutex.Lock()
+				defer mutex.Unlock()
+				_ = c.WriteJSON(Map{
+					"type":       "jsonwsp/response",
+					"result":     request.Args,
+					"reflection": request.Mirror,
+				})
+			}()
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestClient_queryMultiplexed(t *testing.T) {
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	defer listener.Close()
+
+	var dials int64
+	go func() {
+		_ = http.Serve(listener, mirror(1e3, &dials))
+	}()
+
+	parts := strings.Split(listener.Addr().String(), ":")
+	port := parts[len(parts)-1]
+
+	client := New(WithEndpoint(fmt.Sprintf("ws://127.0.0.1:%v", port)), WithLogger(NopLogger))
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			var content struct{ Result struct{ N int } }
+			if err := client.query(ctx, makePayload("Query", Map{"n": i}), &content); err != nil {
+				t.Errorf("got %v; want nil", err)
+				return
+			}
+			if got, want := content.Result.N, i; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if got, want := atomic.LoadInt64(&dials), int64(1); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestClient_queryRedial(t *testing.T) {
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	defer listener.Close()
+
+	var dials int64
+	go func() {
+		_ = http.Serve(listener, mirror(1, &dials))
+	}()
+
+	parts := strings.Split(listener.Addr().String(), ":")
+	port := parts[len(parts)-1]
+
+	client := New(WithEndpoint(fmt.Sprintf("ws://127.0.0.1:%v", port)), WithLogger(NopLogger))
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for i := 0; i < 3; i++ {
+		if err := client.query(ctx, makePayload("Query", Map{}), nil); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		time.Sleep(100 * time.Millisecond) // allow server to drop the connection
+	}
+
+	if got, want := atomic.LoadInt64(&dials), int64(3); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	if err := client.Close(); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if err := client.query(ctx, makePayload("Query", Map{}), nil); !errors.Is(err, errClientClosed) {
+		t.Fatalf("got %v; want %v", err, errClientClosed)
+	}
+}
+
+// fakeOgmios responds to each request with the result returned by fn, echoing
+// the mirror as the reflection.  an error from fn is returned as a fault
+func fakeOgmios(t *testing.T, fn func(methodName string, args json.RawMessage) (interface{}, error)) (endpoint string, closer func()) {
+	var upgrader = websocket.Upgrader{} // use default options
+
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	go func() {
+		_ = http.Serve(listener, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			c, err := upgrader.Upgrade(w, req, nil)
+			if err != nil {
+				return
+			}
+			defer c.Close()
+
+			for {
+				var request struct {
+					MethodName string          `json:"methodname"`
+					Args       json.RawMessage `json:"args"`
+					Mirror     json.RawMessage `json:"mirror"`
+				}
+				if err := c.ReadJSON(&request); err != nil {
+					return
+				}
+
+				response := Map{
+					"type":        "jsonwsp/response",
+					"version":     "1.0",
+					"servicename": "ogmios",
+					"methodname":  request.MethodName,
+					"reflection":  request.Mirror,
+				}
+				result, err := fn(request.MethodName, request.Args)
+				if err != nil {
+					response["type"] = "jsonwsp/fault"
+					response["fault"] = Map{"code": "client", "string": err.Error()}
+				} else {
+					response["result"] = result
+				}
+				if err := c.WriteJSON(response); err != nil {
+					return
+				}
+			}
+		}))
+	}()
+
+	return "ws://" + listener.Addr().String(), func() { listener.Close() }
+}
+
+// anonymousFault waits for n requests then responds with a single fault that
+// carries no reflection, as ogmios does for requests it cannot parse
+func anonymousFault(n int) http.HandlerFunc {
+	var upgrader = websocket.Upgrader{} // use default options
+	return func(w http.ResponseWriter, req *http.Request) {
+		c, err := upgrader.Upgrade(w, req, nil)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+
+		for i := 0; i < n; i++ {
+			var request json.RawMessage
+			if err := c.ReadJSON(&request); err != nil {
+				return
+			}
+		}
+		_ = c.WriteJSON(Map{
+			"type":        "jsonwsp/fault",
+			"version":     "1.0",
+			"servicename": "ogmios",
+			"fault":       Map{"code": "client", "string": "invalid request"},
+		})
+		_, _, _ = c.ReadMessage() // hold the connection open
+	}
+}
+
+func TestClient_queryFaultWithoutReflection(t *testing.T) {
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	defer listener.Close()
+
+	const n = 3
+	go func() {
+		_ = http.Serve(listener, anonymousFault(n))
+	}()
+
+	client := New(WithEndpoint("ws://"+listener.Addr().String()), WithLogger(NopLogger))
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			var e Error
+			if err := client.query(ctx, makePayload("Query", Map{}), nil); !errors.As(err, &e) {
+				t.Errorf("got %v; want Error", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestClient_queryReusedPayload(t *testing.T) {
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	defer listener.Close()
+
+	var dials int64
+	go func() {
+		_ = http.Serve(listener, mirror(1e3, &dials))
+	}()
+
+	client := New(WithEndpoint("ws://"+listener.Addr().String()), WithLogger(NopLogger))
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	payload := makePayload("Query", Map{})
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := client.query(ctx, payload, nil); err != nil {
+				t.Errorf("got %v; want nil", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if _, ok := payload["mirror"]; ok {
+		t.Fatalf("got mirror; want payload unchanged")
+	}
+}
+
+func TestClient_queryStaleConn(t *testing.T) {
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	defer listener.Close()
+
+	var dials int64
+	go func() {
+		_ = http.Serve(listener, mirror(100, &dials))
+	}()
+
+	endpoint := "ws://" + listener.Addr().String()
+	client := New(WithEndpoint(endpoint), WithLogger(NopLogger))
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// a conn whose socket has failed, but that has not yet been noticed by
+	// the pool
+	ws, _, err := websocket.DefaultDialer.DialContext(ctx, endpoint, nil)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	ws.Close()
+	stale := &conn{ws: ws, logger: NopLogger, done: make(chan struct{}), pending: map[string]chan json.RawMessage{}}
+	for i := range client.pool.conns {
+		client.pool.conns[i] = stale
+	}
+
+	if err := client.query(ctx, makePayload("Query", Map{}), nil); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := atomic.LoadInt64(&dials), int64(2); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}